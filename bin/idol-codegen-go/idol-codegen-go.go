@@ -17,12 +17,18 @@ package main
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"path"
 	"slices"
+	"strconv"
 	"strings"
 
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/codegen_idl"
+	"go.idol-lang.org/idol/fingerprint"
 	"go.idol-lang.org/idol/schema_idl"
 )
 
@@ -31,11 +37,108 @@ type codegen struct {
 	dependencies  []schema_idl.Schema
 	pluginOptions []schema_idl.UninterpretedOptions
 
-	schemaPath []string
-	imports    map[string]string
-	goPackage  string
-	buf        bytes.Buffer
-	output     []uint8
+	options      codegenOptions
+	schemaPath   []string
+	imports      map[string]string
+	fingerprints map[string]idol.Fingerprint
+	goPackage    string
+	buf          bytes.Buffer
+	output       []uint8
+}
+
+// generate runs code generation for a CodegenRequest, returning a
+// CodegenResponse containing the generated file.
+func generate(request codegen_idl.CodegenRequest) (*codegen_idl.CodegenResponse__Builder, error) {
+	c := codegen{
+		schema:        request.Schema(),
+		dependencies:  request.Dependencies().Collect(),
+		pluginOptions: request.PluginOptions().Collect(),
+	}
+	if err := c.emitSchema(); err != nil {
+		return nil, err
+	}
+
+	responseBuilder := &codegen_idl.CodegenResponse__Builder{}
+	outputBuilder := &codegen_idl.OutputFile__Builder{}
+	outputBuilder.Path.Set(strings.Split(c.goPackage+".go", "/"))
+	outputBuilder.Content.SetBytes(c.output)
+	responseBuilder.OutputFiles.Add(outputBuilder)
+	return responseBuilder, nil
+}
+
+// codegenOptions are the plugin options set in a CodegenRequest.
+type codegenOptions struct {
+	// package: overrides the Go package set in the schema options.
+	goPackage string
+
+	// import.<namespace>: the Go package of an imported namespace, for
+	// dependencies that don't set their Go package in the schema options.
+	importPaths map[string]string
+
+	// fingerprints: whether to emit Idol__Fingerprint() methods.
+	fingerprints bool
+
+	// deprecated_comments: whether to emit "Deprecated:" doc comments.
+	deprecatedComments bool
+}
+
+func (c *codegen) parseOptions() error {
+	c.options = codegenOptions{
+		importPaths:        make(map[string]string),
+		fingerprints:       true,
+		deprecatedComments: true,
+	}
+	for _, options := range c.pluginOptions {
+		for _, opt := range options.Options().Iter() {
+			name := opt.Name()
+			value, err := optionText(opt)
+			if err != nil {
+				return err
+			}
+			if namespace, ok := strings.CutPrefix(name, "import."); ok {
+				c.options.importPaths[namespace] = value
+				continue
+			}
+			switch name {
+			case "package":
+				c.options.goPackage = value
+			case "fingerprints":
+				c.options.fingerprints, err = parseBoolOption(name, value)
+			case "deprecated_comments":
+				c.options.deprecatedComments, err = parseBoolOption(name, value)
+			default:
+				return fmt.Errorf("Unknown plugin option %q", name)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// optionText returns the value of a text option, which is either stored as
+// text or as the source of a text literal.
+func optionText(opt schema_idl.UninterpretedOption) (string, error) {
+	value := string(opt.Value().Collect())
+	if opt.Type() == schema_idl.Type_TEXT || !strings.HasPrefix(value, `"`) {
+		return value, nil
+	}
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return "", fmt.Errorf("Invalid value for plugin option %q: %s", opt.Name(), value)
+	}
+	return unquoted, nil
+}
+
+func parseBoolOption(name, value string) (bool, error) {
+	switch value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("Invalid value for plugin option %q: %q (expected true or false)", name, value)
 }
 
 func (c *codegen) w(s string) {
@@ -55,21 +158,122 @@ func (c *codegen) wlf(format string, a ...any) {
 	c.wl(fmt.Sprintf(format, a...))
 }
 
+func (c *codegen) emitDoc(doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		if line == "" {
+			c.wl(`//`)
+		} else {
+			c.wlf(`// %s`, line)
+		}
+	}
+}
+
+// emitDocDeprecated emits a doc comment with a trailing "Deprecated:"
+// paragraph, which tools such as staticcheck report at call sites.
+func (c *codegen) emitDocDeprecated(doc string, deprecated bool) {
+	c.emitDoc(doc)
+	if !deprecated || !c.options.deprecatedComments {
+		return
+	}
+	if doc != "" {
+		c.wl(`//`)
+	}
+	c.wl(`// Deprecated: Marked as deprecated in the schema.`)
+}
+
+// emitGetter emits the getter for a scalar message field, which returns the
+// field's default value (if any) when the field is absent.
+func (c *codegen) emitGetter(
+	msgName string,
+	field schema_idl.MessageField,
+	goType, expr string,
+) {
+	fName := c.localName(field)
+	defaultValue, ok := fieldDefault(field, goType)
+	if !ok {
+		c.wlf(`func (m %s) %s() %s { return %s }`, msgName, fName, goType, expr)
+		return
+	}
+	c.wlf(`func (m %s) %s() %s {`, msgName, fName, goType)
+	c.wlf(`if !m.msg.Has(%d) { return %s }`, field.Tag(), defaultValue)
+	c.wlf(`return %s }`, expr)
+}
+
+func fieldDefault(field schema_idl.MessageField, goType string) (string, bool) {
+	value := field.Options().Default().Collect()
+	if len(value) == 0 {
+		return "", false
+	}
+	var num uint64
+	switch field.Type() {
+	case schema_idl.Type_BOOL:
+		return strconv.FormatBool(value[0] != 0), true
+	case schema_idl.Type_TEXT:
+		return strconv.Quote(string(value)), true
+	case schema_idl.Type_I8:
+		return strconv.FormatInt(int64(int8(value[0])), 10), true
+	case schema_idl.Type_I16:
+		return strconv.FormatInt(int64(int16(binary.LittleEndian.Uint16(value))), 10), true
+	case schema_idl.Type_I32:
+		return strconv.FormatInt(int64(int32(binary.LittleEndian.Uint32(value))), 10), true
+	case schema_idl.Type_I64:
+		return strconv.FormatInt(int64(binary.LittleEndian.Uint64(value)), 10), true
+	case schema_idl.Type_F32:
+		f32 := math.Float32frombits(binary.LittleEndian.Uint32(value))
+		return strconv.FormatFloat(float64(f32), 'g', -1, 32), true
+	case schema_idl.Type_F64:
+		f64 := math.Float64frombits(binary.LittleEndian.Uint64(value))
+		return strconv.FormatFloat(f64, 'g', -1, 64), true
+	case schema_idl.Type_U8:
+		num = uint64(value[0])
+	case schema_idl.Type_U16:
+		num = uint64(binary.LittleEndian.Uint16(value))
+	case schema_idl.Type_U32:
+		num = uint64(binary.LittleEndian.Uint32(value))
+	case schema_idl.Type_U64:
+		num = binary.LittleEndian.Uint64(value)
+	default:
+		return "", false
+	}
+	if field.TypeName() != "" {
+		return fmt.Sprintf("%s(%d)", goType, num), true
+	}
+	return strconv.FormatUint(num, 10), true
+}
+
 func (c *codegen) emitSchema() error {
 	if len(c.schemaPath) == 0 {
 		c.schemaPath = c.schema.SourcePath().Collect()
 	}
 
+	if err := c.parseOptions(); err != nil {
+		return err
+	}
+
 	if err := c.decideGoPackage(); err != nil {
 		return err
 	}
 
+	if c.options.fingerprints {
+		fingerprints, err := fingerprint.Decls(c.schema, c.dependencies...)
+		if err != nil {
+			return err
+		}
+		c.fingerprints = fingerprints
+	}
+
 	namespaces := make(map[string]string)
 	for _, dep := range c.dependencies {
 		if depPkg := schemaGoPackage(dep); depPkg != "" {
 			namespaces[dep.Namespace()] = depPkg
 		}
 	}
+	for namespace, goPkg := range c.options.importPaths {
+		namespaces[namespace] = goPkg
+	}
 
 	c.wl(`// Code generated by idol-codegen-go. DO NOT EDIT.`)
 	if len(c.schemaPath) > 0 {
@@ -138,7 +342,10 @@ func schemaGoPackage(schema schema_idl.Schema) string {
 }
 
 func (c *codegen) decideGoPackage() error {
-	goPackage := schemaGoPackage(c.schema)
+	goPackage := c.options.goPackage
+	if goPackage == "" {
+		goPackage = schemaGoPackage(c.schema)
+	}
 	if goPackage == "" && len(c.schemaPath) > 0 {
 		filename := c.schemaPath[len(c.schemaPath)-1]
 		if s, ok := strings.CutSuffix(filename, ".idol"); ok {
@@ -202,12 +409,43 @@ func (*codegen) hasData(type_ schema_idl.Type, arrayLen uint32) bool {
 		return true
 	}
 	switch type_ {
-	case schema_idl.Type_U64, schema_idl.Type_TEXT, schema_idl.Type_MESSAGE:
+	case schema_idl.Type_U64, schema_idl.Type_I64, schema_idl.Type_F64:
+		return true
+	case schema_idl.Type_TEXT, schema_idl.Type_MESSAGE:
 		return true
 	}
 	return false
 }
 
+// scalarType describes the generated code for a field of a signed integer
+// or floating-point type.
+type scalarType struct {
+	goType  string
+	getter  string
+	builder string
+	decoder string
+}
+
+var scalarTypes = map[schema_idl.Type]scalarType{
+	schema_idl.Type_I8:  {"int8", `int8(m.msg.GetInt32(%d))`, `idol.Int8FieldBuilder`, `Int8`},
+	schema_idl.Type_I16: {"int16", `int16(m.msg.GetInt32(%d))`, `idol.Int16FieldBuilder`, `Int16`},
+	schema_idl.Type_I32: {"int32", `m.msg.GetInt32(%d)`, `idol.Int32FieldBuilder`, `Int32`},
+	schema_idl.Type_I64: {"int64", `m.msg.GetInt64(%d)`, `idol.Int64FieldBuilder`, `Int64`},
+	schema_idl.Type_F32: {"float32", `m.msg.GetFloat32(%d)`, `idol.Float32FieldBuilder`, `Float32`},
+	schema_idl.Type_F64: {"float64", `m.msg.GetFloat64(%d)`, `idol.Float64FieldBuilder`, `Float64`},
+}
+
+// scalarFieldType returns the scalarType of a field, if it's a signed
+// integer or floating-point field. Arrays and enums of these types aren't
+// yet supported.
+func scalarFieldType(field schema_idl.MessageField) (scalarType, bool) {
+	if field.ArrayLen() > 0 || field.TypeName() != "" {
+		return scalarType{}, false
+	}
+	st, ok := scalarTypes[field.Type()]
+	return st, ok
+}
+
 func (*codegen) containsHandles(type_ schema_idl.Type) bool {
 	switch type_ {
 	case schema_idl.Type_HANDLE, schema_idl.Type_STRUCT, schema_idl.Type_MESSAGE, schema_idl.Type_UNION:
@@ -219,6 +457,7 @@ func (*codegen) containsHandles(type_ schema_idl.Type) bool {
 func (c *codegen) emitEnum(enum schema_idl.Enum) error {
 	name := c.localName(enum)
 	goType := c.enumTypeName(enum)
+	c.emitDoc(enum.Doc())
 	c.wlf(`type %s %s`, name, goType)
 	c.wl(``)
 
@@ -227,8 +466,10 @@ func (c *codegen) emitEnum(enum schema_idl.Enum) error {
 		// TODO: signed enums
 		if ii == 0 {
 			c.wl(`const (`)
+			c.emitDocDeprecated(item.Doc(), item.Options().Deprecated())
 			c.wlf(`%s_%s %s = %v`, name, item.Name(), name, item.Value())
 		} else {
+			c.emitDocDeprecated(item.Doc(), item.Options().Deprecated())
 			c.wlf(`%s_%s = %v`, name, item.Name(), item.Value())
 		}
 	}
@@ -271,6 +512,7 @@ func (c *codegen) emitMessage(msg schema_idl.Message) error {
 		optional[tag] = field.Options().Optional()
 	}
 
+	c.emitDocDeprecated(msg.Doc(), msg.Options().Deprecated())
 	c.wlf(`type %s struct { msg idol.DecodedMessage }`, name)
 	c.wl(``)
 
@@ -293,6 +535,19 @@ func (c *codegen) emitMessage(msg schema_idl.Message) error {
 	c.wlf(`return _%s__MessageType{} }`, name)
 	c.wl(``)
 
+	if c.options.fingerprints {
+		c.wlf(`func (%s) Idol__Fingerprint() idol.Fingerprint {`, name)
+		c.wf(`return idol.Fingerprint{`)
+		for ii, b := range c.fingerprints[msg.Name()] {
+			if ii > 0 {
+				c.w(`, `)
+			}
+			c.wf(`0x%02X`, b)
+		}
+		c.wl(`} }`)
+		c.wl(``)
+	}
+
 	c.wlf(`func (m _%s__Message) Self() %s { return m.self }`, name, name)
 	c.wl(``)
 
@@ -410,7 +665,11 @@ func (c *codegen) emitMessage(msg schema_idl.Message) error {
 				c.wlf(`Bool(%d)`, tag)
 			}
 		default:
-			c.wl(`TODO_FIELD_TYPE`)
+			if st, ok := scalarFieldType(field); ok {
+				c.wlf(`%s(%d)`, st.decoder, tag)
+			} else {
+				c.wl(`TODO_FIELD_TYPE`)
+			}
 		}
 	}
 	c.wl(`return d.Finish() }`)
@@ -429,12 +688,13 @@ func (c *codegen) emitMessage(msg schema_idl.Message) error {
 	for _, field := range msg.Fields().Iter() {
 		fName := c.localName(field)
 		tag := field.Tag()
+		c.emitDocDeprecated(field.Doc(), field.Options().Deprecated())
 		switch field.Type() {
 		case schema_idl.Type_TEXT:
 			if field.ArrayLen() > 0 {
 				c.wlf(`func (m %s) %s() idol.TextArray { return m.msg.GetTextArray(%d) }`, name, fName, tag)
 			} else {
-				c.wlf(`func (m %s) %s() idol.Text { return m.msg.GetText(%d) }`, name, fName, tag)
+				c.emitGetter(name, field, `idol.Text`, fmt.Sprintf(`m.msg.GetText(%d)`, tag))
 			}
 		case schema_idl.Type_MESSAGE:
 			fType := c.typeName(field.TypeName())
@@ -459,19 +719,13 @@ func (c *codegen) emitMessage(msg schema_idl.Message) error {
 						name, fName, tag,
 					)
 				} else {
-					c.wlf(
-						`func (m %s) %s() uint8 { return uint8(m.msg.GetUint32(%d)) }`,
-						name, fName, tag,
-					)
+					c.emitGetter(name, field, `uint8`, fmt.Sprintf(`uint8(m.msg.GetUint32(%d))`, tag))
 				}
 			} else {
 				if field.ArrayLen() > 0 {
 					c.wl(`func TODO_FIELD_FN() {}`)
 				} else {
-					c.wlf(
-						`func (m %s) %s() %s { return %s(m.msg.GetUint32(%d))}`,
-						name, fName, fType, fType, tag,
-					)
+					c.emitGetter(name, field, fType, fmt.Sprintf(`%s(m.msg.GetUint32(%d))`, fType, tag))
 				}
 			}
 		case schema_idl.Type_U16:
@@ -482,10 +736,7 @@ func (c *codegen) emitMessage(msg schema_idl.Message) error {
 						name, fName, tag,
 					)
 				} else {
-					c.wlf(
-						`func (m %s) %s() uint16 { return uint16(m.msg.GetUint32(%d)) }`,
-						name, fName, tag,
-					)
+					c.emitGetter(name, field, `uint16`, fmt.Sprintf(`uint16(m.msg.GetUint32(%d))`, tag))
 				}
 			} else {
 				c.wl(`func TODO_FIELD_FN() {}`)
@@ -498,10 +749,7 @@ func (c *codegen) emitMessage(msg schema_idl.Message) error {
 						name, fName, tag,
 					)
 				} else {
-					c.wlf(
-						`func (m %s) %s() uint32 { return m.msg.GetUint32(%d) }`,
-						name, fName, tag,
-					)
+					c.emitGetter(name, field, `uint32`, fmt.Sprintf(`m.msg.GetUint32(%d)`, tag))
 				}
 			} else {
 				c.wl(`func TODO_FIELD_FN() {}`)
@@ -518,20 +766,14 @@ func (c *codegen) emitMessage(msg schema_idl.Message) error {
 					c.wlf(`if m.msg.Has(%d) { return m.msg.GetUint64(%d), true }`, tag, tag)
 					c.wl(`return 0, false }`)
 				} else {
-					c.wlf(
-						`func (m %s) %s() uint64 { return m.msg.GetUint64(%d) }`,
-						name, fName, tag,
-					)
+					c.emitGetter(name, field, `uint64`, fmt.Sprintf(`m.msg.GetUint64(%d)`, tag))
 				}
 			} else {
 				if field.ArrayLen() > 0 {
 					c.wl(`func TODO_FIELD_FN() {}`)
 				} else {
 					// TODO: u64 and i64 enums
-					c.wlf(
-						`func (m %s) %s() %s { return %s(m.msg.GetUint8(%d))}`,
-						name, fName, fType, fType, tag,
-					)
+					c.emitGetter(name, field, fType, fmt.Sprintf(`%s(m.msg.GetUint8(%d))`, fType, tag))
 				}
 			}
 		case schema_idl.Type_BOOL:
@@ -541,20 +783,28 @@ func (c *codegen) emitMessage(msg schema_idl.Message) error {
 					name, fName, tag,
 				)
 			} else {
-				c.wlf(
-					`func (m %s) %s() bool { return m.msg.GetBool(%d) }`,
-					name, fName, tag,
-				)
+				c.emitGetter(name, field, `bool`, fmt.Sprintf(`m.msg.GetBool(%d)`, tag))
 			}
 		default:
-			c.wl(`func TODO_FIELD_FN() {}`)
+			st, ok := scalarFieldType(field)
+			if !ok {
+				c.wl(`func TODO_FIELD_FN() {}`)
+			} else if optional[tag] {
+				c.wlf(`func (m %s) %s() (%s, bool) {`, name, fName, st.goType)
+				c.wlf(`if m.msg.Has(%d) { return %s, true }`, tag, fmt.Sprintf(st.getter, tag))
+				c.wl(`return 0, false }`)
+			} else {
+				c.emitGetter(name, field, st.goType, fmt.Sprintf(st.getter, tag))
+			}
 		}
 		c.wl(``)
 	}
 	c.wl(``)
 
+	c.emitDocDeprecated("", msg.Options().Deprecated())
 	c.wlf(`type %s__Builder struct {`, name)
 	for _, field := range msg.Fields().Iter() {
+		c.emitDocDeprecated("", field.Options().Deprecated())
 		c.wf(`%s `, c.localName(field))
 		switch field.Type() {
 		case schema_idl.Type_TEXT:
@@ -621,7 +871,11 @@ func (c *codegen) emitMessage(msg schema_idl.Message) error {
 				c.wl(`idol.BoolFieldBuilder`)
 			}
 		default:
-			c.wl(`TODO_FIELD_TYPE`)
+			if st, ok := scalarFieldType(field); ok {
+				c.wl(st.builder)
+			} else {
+				c.wl(`TODO_FIELD_TYPE`)
+			}
 		}
 	}
 	c.wl(`}`)
@@ -16,12 +16,16 @@
 package main
 
 import (
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/codegen_idl"
 	"go.idol-lang.org/idol/compiler"
 	"go.idol-lang.org/idol/schema_idl"
 	"go.idol-lang.org/idol/syntax"
@@ -29,6 +33,11 @@ import (
 
 func main() {
 	args := os.Args[1:]
+	if len(args) == 1 {
+		if language, ok := strings.CutPrefix(args[0], "--generate="); ok {
+			os.Exit(pluginMain(language))
+		}
+	}
 	if len(args) < 1 {
 		log.Fatalf("usage: %s IDOL_SCHEMA", os.Args[0])
 	}
@@ -81,6 +90,43 @@ func main() {
 	}
 }
 
+// pluginMain implements the native plugin protocol of `idol codegen`, which
+// runs the plugin with "--generate=<lang>", writes an encoded CodegenRequest
+// to its stdin, and reads an encoded CodegenResponse from its stdout.
+func pluginMain(language string) int {
+	requestBuf, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	var responseBuilder *codegen_idl.CodegenResponse__Builder
+	request, err := idol.DecodeAs[codegen_idl.CodegenRequest](&idol.DecodeCtx{}, requestBuf)
+	if err == nil && language != "go" {
+		err = fmt.Errorf("Unsupported language %q", language)
+	}
+	if err == nil {
+		responseBuilder, err = generate(request)
+	}
+	rc := 0
+	if err != nil {
+		responseBuilder = &codegen_idl.CodegenResponse__Builder{}
+		responseBuilder.Error.Set(err.Error())
+		rc = 1
+	}
+
+	response, err := idol.Encode(&idol.EncodeCtx{}, responseBuilder)
+	if err != nil {
+		log.Printf("Encode[CodegenResponse]: %v", err)
+		return 1
+	}
+	if _, err := os.Stdout.Write(response); err != nil {
+		log.Print(err)
+		return 1
+	}
+	return rc
+}
+
 func splitPath(path string) []string {
 	var out []string
 	for {
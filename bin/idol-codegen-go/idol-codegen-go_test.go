@@ -0,0 +1,451 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/format"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+var update = flag.Bool("update", false, "rewrite the checked-in generated files")
+
+// repoRoots are the directories that paths relative to the repository root
+// are resolved against. Bazel runs tests from the repository root, and
+// `go test` from the package directory.
+var repoRoots = []string{".", "../.."}
+
+func repoPath(t *testing.T, path string) string {
+	t.Helper()
+	for _, root := range repoRoots {
+		fullPath := filepath.Join(root, path)
+		if _, err := os.Stat(fullPath); err == nil {
+			return fullPath
+		}
+	}
+	t.Fatalf("%s not found", path)
+	return ""
+}
+
+func compileSchema(
+	t *testing.T,
+	path string,
+	deps []schema_idl.Schema,
+) schema_idl.Schema {
+	t.Helper()
+	src, err := os.ReadFile(repoPath(t, path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return compileSource(t, path, src, deps)
+}
+
+func compileSource(
+	t *testing.T,
+	path string,
+	src []uint8,
+	deps []schema_idl.Schema,
+) schema_idl.Schema {
+	t.Helper()
+	parsed, err := syntax.Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []compiler.CompileOption{
+		compiler.WithSourcePath(strings.Split(path, "/")),
+	}
+	if len(deps) > 0 {
+		depSet, err := compiler.Merge(deps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts = append(opts, compiler.WithDependencies(depSet))
+	}
+	result := compiler.Compile(parsed, opts...)
+	for _, err := range result.Errors {
+		t.Fatalf("%s: %v", path, err)
+	}
+	encoded, err := result.EncodedSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := idol.DecodeAs[schema_idl.Schema](nil, []uint8(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+// checkGenerated compares generated code against a checked-in file, which
+// has a license header preceding the gofmt-formatted generated content.
+func checkGenerated(t *testing.T, c *codegen, path string) {
+	t.Helper()
+	if err := c.emitSchema(); err != nil {
+		t.Fatal(err)
+	}
+	generated, err := format.Source(c.output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fullPath := repoPath(t, path)
+	existing, err := os.ReadFile(fullPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _, ok := bytes.Cut(existing, []uint8("// Code generated"))
+	if !ok {
+		t.Fatalf("%s: missing generated code marker", path)
+	}
+	// Clip the header, which shares its backing array with existing.
+	expect := append(slices.Clip(header), generated...)
+	if bytes.Equal(expect, existing) {
+		return
+	}
+	if *update {
+		if err := os.WriteFile(fullPath, expect, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	t.Errorf("%s is out of date; regenerate it with `go test -update`", path)
+}
+
+func TestGeneratedFiles(t *testing.T) {
+	schema := compileSchema(t, "idol/idl/schema.idol", nil)
+	checkGenerated(t, &codegen{schema: schema}, "idol/schema_idl/schema_idl.go")
+
+	codegenSchema := compileSchema(t, "idol/idl/codegen.idol", []schema_idl.Schema{schema})
+	checkGenerated(t, &codegen{
+		schema:       codegenSchema,
+		dependencies: []schema_idl.Schema{schema},
+	}, "idol/codegen_idl/codegen_idl.go")
+}
+
+// generateSource runs the Go codegen for a schema source, returning the
+// generated code.
+func generateSource(t *testing.T, src string) (string, error) {
+	t.Helper()
+	c := &codegen{
+		schema: compileSource(t, "test.idol", []uint8(src), nil),
+	}
+	if err := c.emitSchema(); err != nil {
+		return "", err
+	}
+	return string(c.output), nil
+}
+
+func TestDocComments(t *testing.T) {
+	output, err := generateSource(t, `namespace "test"
+## Doc for E.
+enum E : u8 {
+	## Doc for E.A.
+	A = 1
+}
+
+## Doc for M.
+##
+## Second paragraph.
+message M {
+	## Doc for M.a.
+	a @1 : u8
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"// Doc for E.\ntype E uint8",
+		"// Doc for E.A.\nE_A E = 1",
+		"// Doc for M.\n//\n// Second paragraph.\ntype M struct",
+		"// Doc for M.a.\nfunc (m M) A() uint8",
+	} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("generated code doesn't contain %q", expect)
+		}
+	}
+}
+
+func TestUnsupportedDecls(t *testing.T) {
+	// Declarations without Go codegen support are skipped, so that the
+	// messages of a schema can still be used.
+	output, err := generateSource(t, `namespace "test"
+struct S {
+	a : u8
+}
+union U {
+	a @1 : u8
+}
+message M {
+	a @1 : u8
+}
+protocol P {
+	event E : M
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "type M struct") {
+		t.Errorf("generated code doesn't contain message M")
+	}
+}
+
+func TestBytes(t *testing.T) {
+	output, err := generateSource(t, `namespace "test"
+message M {
+	data @1 : bytes
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Uint8ArrayFieldBuilder has a SetBytes([]byte) setter.
+	for _, expect := range []string{
+		"func (m M) Data() idol.Uint8Array { return m.msg.GetUint8Array(1) }",
+		"Data idol.Uint8ArrayFieldBuilder",
+	} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("generated code doesn't contain %q", expect)
+		}
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	output, err := generateSource(t, `namespace "test"
+enum E : u8 {
+	A = 1
+	B = 2
+}
+message M {
+	@{default = .true}
+	a @1 : bool
+	@{default = "abc"}
+	b @2 : text
+	@{default = 7}
+	c @3 : u32
+	@{default = E.B}
+	d @4 : E
+	@{default = -8}
+	e @5 : i8
+	@{default = -16}
+	f @6 : i16
+	@{default = -32}
+	g @7 : i32
+	@{default = -64}
+	h @8 : i64
+	@{default = 3}
+	i @9 : f32
+	@{default = -5}
+	j @10 : f64
+	k @11 : i32
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"func (m M) A() bool {\nif !m.msg.Has(1) { return true }\nreturn m.msg.GetBool(1) }",
+		"func (m M) B() idol.Text {\nif !m.msg.Has(2) { return \"abc\" }",
+		"func (m M) C() uint32 {\nif !m.msg.Has(3) { return 7 }",
+		"func (m M) D() E {\nif !m.msg.Has(4) { return E(2) }",
+		"func (m M) E() int8 {\nif !m.msg.Has(5) { return -8 }\nreturn int8(m.msg.GetInt32(5)) }",
+		"func (m M) F() int16 {\nif !m.msg.Has(6) { return -16 }\nreturn int16(m.msg.GetInt32(6)) }",
+		"func (m M) G() int32 {\nif !m.msg.Has(7) { return -32 }\nreturn m.msg.GetInt32(7) }",
+		"func (m M) H() int64 {\nif !m.msg.Has(8) { return -64 }\nreturn m.msg.GetInt64(8) }",
+		"func (m M) I() float32 {\nif !m.msg.Has(9) { return 3 }\nreturn m.msg.GetFloat32(9) }",
+		"func (m M) J() float64 {\nif !m.msg.Has(10) { return -5 }\nreturn m.msg.GetFloat64(10) }",
+		"func (m M) K() int32 { return m.msg.GetInt32(11) }",
+		"E idol.Int8FieldBuilder",
+		"H idol.Int64FieldBuilder",
+		"J idol.Float64FieldBuilder",
+		"d.Int16(6)",
+		"d.Float32(9)",
+	} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("generated code doesn't contain %q", expect)
+		}
+	}
+}
+
+// encodePluginOptions encodes plugin options as `idol codegen` does, with
+// each option given as a name, type, and value.
+func encodePluginOptions(
+	t *testing.T,
+	options [][3]string,
+) []schema_idl.UninterpretedOptions {
+	t.Helper()
+	if len(options) == 0 {
+		return nil
+	}
+	builder := &schema_idl.UninterpretedOptions__Builder{}
+	for _, opt := range options {
+		optBuilder := &schema_idl.UninterpretedOption__Builder{}
+		optBuilder.Name.Set(opt[0])
+		var optType schema_idl.Type = schema_idl.Type_TEXT
+		if opt[1] != "text" {
+			optType = schema_idl.Type_UNKNOWN
+		}
+		optBuilder.Type.Set(optType)
+		optBuilder.Value.SetBytes([]uint8(opt[2]))
+		builder.Options.Add(optBuilder)
+	}
+	encoded, err := idol.Encode(&idol.EncodeCtx{}, builder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := idol.DecodeAs[schema_idl.UninterpretedOptions](nil, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []schema_idl.UninterpretedOptions{decoded}
+}
+
+func TestParseOptions(t *testing.T) {
+	defaults := codegenOptions{
+		importPaths:        map[string]string{},
+		fingerprints:       true,
+		deprecatedComments: true,
+	}
+	tests := []struct {
+		options [][3]string
+		want    codegenOptions
+	}{
+		{nil, defaults},
+		{
+			[][3]string{{"package", "text", "example.com/test"}},
+			codegenOptions{
+				goPackage:          "example.com/test",
+				importPaths:        map[string]string{},
+				fingerprints:       true,
+				deprecatedComments: true,
+			},
+		},
+		{
+			// Options from schema sources hold the text literal.
+			[][3]string{{"package", "source", `"example.com/test"`}},
+			codegenOptions{
+				goPackage:          "example.com/test",
+				importPaths:        map[string]string{},
+				fingerprints:       true,
+				deprecatedComments: true,
+			},
+		},
+		{
+			[][3]string{
+				{"import.a", "text", "example.com/a"},
+				{"import.b.c", "text", "example.com/b/c"},
+				{"import.a", "text", "example.com/a2"},
+			},
+			codegenOptions{
+				importPaths: map[string]string{
+					"a":   "example.com/a2",
+					"b.c": "example.com/b/c",
+				},
+				fingerprints:       true,
+				deprecatedComments: true,
+			},
+		},
+		{
+			[][3]string{
+				{"fingerprints", "text", "false"},
+				{"deprecated_comments", "text", "false"},
+			},
+			codegenOptions{
+				importPaths: map[string]string{},
+			},
+		},
+		{
+			[][3]string{
+				{"fingerprints", "text", "false"},
+				{"fingerprints", "text", "true"},
+			},
+			defaults,
+		},
+	}
+	for _, test := range tests {
+		c := &codegen{pluginOptions: encodePluginOptions(t, test.options)}
+		if err := c.parseOptions(); err != nil {
+			t.Errorf("%q: unexpected error: %v", test.options, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.options, test.want) {
+			t.Errorf("%q: expected %+v, got %+v", test.options, test.want, c.options)
+		}
+	}
+}
+
+func TestParseOptions_Errors(t *testing.T) {
+	tests := []struct {
+		options [][3]string
+		err     string
+	}{
+		{
+			[][3]string{{"pakage", "text", "example.com/test"}},
+			`Unknown plugin option "pakage"`,
+		},
+		{
+			[][3]string{{"package", "text", "a"}, {"unknown", "text", ""}},
+			`Unknown plugin option "unknown"`,
+		},
+		{
+			[][3]string{{"fingerprints", "text", "yes"}},
+			`Invalid value for plugin option "fingerprints": "yes" (expected true or false)`,
+		},
+		{
+			[][3]string{{"package", "source", `"unterminated`}},
+			`Invalid value for plugin option "package": "unterminated`,
+		},
+	}
+	for _, test := range tests {
+		c := &codegen{pluginOptions: encodePluginOptions(t, test.options)}
+		err := c.parseOptions()
+		if err == nil {
+			t.Errorf("%q: expected error %q", test.options, test.err)
+		} else if err.Error() != test.err {
+			t.Errorf("%q: expected error %q, got %q", test.options, test.err, err)
+		}
+	}
+}
+
+func TestPackageOption(t *testing.T) {
+	c := &codegen{
+		schema: compileSource(t, "test.idol", []uint8(`namespace "test"
+message M {
+	a @1 : u32
+}
+`), nil),
+		pluginOptions: encodePluginOptions(t, [][3]string{
+			{"package", "text", "example.com/override"},
+		}),
+	}
+	if err := c.emitSchema(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(c.output), "package override\n") {
+		t.Errorf("expected package override, got:\n%s", c.output)
+	}
+}
@@ -67,13 +67,9 @@ func idolCodegenGenerateGo(requestPtr *uint8, responsePtrPtr **uint8) uint8 {
 	requestStr := unsafe.String(requestPtr, requestLen)
 	request := *(*codegen_idl.CodegenRequest)(unsafe.Pointer(&requestStr))
 
-	c := codegen{
-		schema:        request.Schema(),
-		dependencies:  request.Dependencies().Collect(),
-		pluginOptions: request.PluginOptions().Collect(),
-	}
-
-	if err := c.emitSchema(); err != nil {
+	responseBuilder, err := generate(request)
+	if err != nil {
+		responseBuilder = &codegen_idl.CodegenResponse__Builder{}
 		responseBuilder.Error.Set(fmt.Sprintf("%v", err))
 		response, _ := idol.Encode(&idol.EncodeCtx{}, responseBuilder)
 		responsePtr := unsafe.SliceData(response)
@@ -82,11 +78,6 @@ func idolCodegenGenerateGo(requestPtr *uint8, responsePtrPtr **uint8) uint8 {
 		return 1
 	}
 
-	outputBuilder := &codegen_idl.OutputFile__Builder{}
-	outputBuilder.Path.Set(strings.Split(c.goPackage+".go", "/"))
-	outputBuilder.Content.SetBytes(c.output)
-	responseBuilder.OutputFiles.Add(outputBuilder)
-
 	response, err := idol.Encode(&idol.EncodeCtx{}, responseBuilder)
 	if err != nil {
 		var stderrBuf strings.Builder
@@ -3,6 +3,7 @@ module go.idol-lang.org/bin/idol
 go 1.23.1
 
 require (
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/cobra v1.8.1
 	github.com/spf13/pflag v1.0.5
 	github.com/tetratelabs/wazero v1.8.0
@@ -54,7 +54,11 @@ func main() {
 	commands := []command{
 		&cmdCompile{},
 		&cmdCodegen{},
+		&cmdBreaking{},
+		&cmdLint{},
 		&cmdFormat{},
+		&cmdDecode{},
+		&cmdEncode{},
 	}
 	for _, cmd := range commands {
 		help := cmd.help()
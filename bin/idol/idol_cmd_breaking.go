@@ -0,0 +1,77 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/breaking"
+	"go.idol-lang.org/idol/schema_idl"
+)
+
+type cmdBreaking struct {
+	ignoreWarnings bool
+}
+
+func (*cmdBreaking) help() *commandHelp {
+	return &commandHelp{
+		usage:   "breaking PREV_SCHEMA NEXT_SCHEMA",
+		summary: "Report incompatible changes between two compiled schemas",
+	}
+}
+
+func (cmd *cmdBreaking) flags(flags *pflag.FlagSet) {
+	flags.BoolVar(&cmd.ignoreWarnings, "ignore-warnings", false, "(docs TODO)")
+}
+
+func (cmd *cmdBreaking) run(ctx context.Context, argv []string) int {
+	if len(argv) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: idol breaking PREV_SCHEMA NEXT_SCHEMA")
+		return 1
+	}
+
+	var schemas [2]schema_idl.Schema
+	for ii, path := range argv {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		schemas[ii], err = idol.DecodeAs[schema_idl.Schema](&idol.DecodeCtx{}, buf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			return 1
+		}
+	}
+
+	exitCode := 0
+	for _, finding := range breaking.Check(schemas[0], schemas[1]) {
+		if finding.Severity() == breaking.SeverityWarning {
+			if cmd.ignoreWarnings {
+				continue
+			}
+		} else {
+			exitCode = 1
+		}
+		fmt.Fprintf(os.Stderr, "%v\n", finding)
+	}
+	return exitCode
+}
@@ -16,34 +16,72 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/pflag"
 	wasm "github.com/tetratelabs/wazero"
 
 	"go.idol-lang.org/idol"
 	"go.idol-lang.org/idol/codegen_idl"
+	"go.idol-lang.org/idol/compiler"
 	"go.idol-lang.org/idol/schema_idl"
 )
 
 type cmdCodegen struct {
-	outDir     string
-	pluginPath string
+	outDir         string
+	pluginPath     string
+	importPath     []string
+	cacheDir       string
+	depfilePath    string
+	manifestPath   string
+	check          bool
+	pluginOpts     []string
+	pluginOptFiles []string
+	languages      []string
+
+	pluginRuntime     string
+	pluginMemoryLimit string
+	pluginTimeout     time.Duration
+	pluginCacheDir    string
+
+	warnings warningFlags
 }
 
 func (*cmdCodegen) help() *commandHelp {
 	return &commandHelp{
-		usage: "codegen",
+		usage:   "codegen IDOL_SCHEMA [DEPENDENCY...]",
+		summary: "Generate code from a compiled schema or schema source",
 	}
 }
 
 func (cmd *cmdCodegen) flags(flags *pflag.FlagSet) {
 	flags.StringVarP(&cmd.outDir, "output", "o", "", "(docs TODO)")
 	flags.StringVar(&cmd.pluginPath, "plugin-path", "", "(docs TODO)")
+	flags.StringArrayVar(&cmd.languages, "lang", nil, "(docs TODO)")
+	flags.StringVar(&cmd.pluginRuntime, "plugin-runtime", "auto", "(docs TODO)")
+	flags.StringVar(&cmd.pluginMemoryLimit, "plugin-memory-limit", "1GiB", "(docs TODO)")
+	flags.DurationVar(&cmd.pluginTimeout, "plugin-timeout", 0, "(docs TODO)")
+	flags.StringVar(&cmd.pluginCacheDir, "plugin-cache-dir", "", "(docs TODO)")
+	flags.StringArrayVarP(&cmd.importPath, "import-path", "I", nil, "(docs TODO)")
+	flags.StringVar(&cmd.cacheDir, "cache-dir", "", "(docs TODO)")
+	flags.StringVar(&cmd.depfilePath, "depfile", "", "(docs TODO)")
+	flags.StringVar(&cmd.manifestPath, "manifest", "", "(docs TODO)")
+	flags.BoolVar(&cmd.check, "check", false, "(docs TODO)")
+	flags.StringArrayVar(&cmd.pluginOpts, "plugin-opt", nil, "(docs TODO)")
+	flags.StringArrayVar(&cmd.pluginOptFiles, "plugin-opt-file", nil, "(docs TODO)")
+	cmd.warnings.flags(flags)
 }
 
 func (cmd *cmdCodegen) run(ctx context.Context, argv []string) int {
@@ -53,32 +91,19 @@ func (cmd *cmdCodegen) run(ctx context.Context, argv []string) int {
 		return 1
 	}
 
-	if cmd.outDir == "" {
-		fmt.Fprintln(os.Stderr, "No output directory specified (set --output=)")
+	targets, err := cmd.targets()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
 
 	type Schema = schema_idl.Schema
 	type RequestBuilder = codegen_idl.CodegenRequest__Builder
-	type Response = codegen_idl.CodegenResponse
 
 	schemaPath := argv[0]
-	schemaBuf, err := os.ReadFile(schemaPath)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
-	}
-
 	requestBuilder := &RequestBuilder{}
 
-	// FIXME: include directly in the request, without decoding first?
-	schema, err := idol.DecodeAs[Schema](&idol.DecodeCtx{}, schemaBuf)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
-	}
-	requestBuilder.Schema.Set(idol.Clone(schema).Self())
-
+	var deps []Schema
 	for _, depPath := range argv[1:] {
 		depBuf, err := os.ReadFile(depPath)
 		if err != nil {
@@ -92,119 +117,174 @@ func (cmd *cmdCodegen) run(ctx context.Context, argv []string) int {
 			fmt.Fprintln(os.Stderr, err)
 			return 1
 		}
-		requestBuilder.Dependencies.Add(idol.Clone(dep).Self())
-	}
-
-	requestBuf, err := idol.Encode(&idol.EncodeCtx{}, requestBuilder)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+		deps = append(deps, dep)
 	}
 
-	runtimeConfig := wasm.NewRuntimeConfigInterpreter()
-	runtimeConfig = runtimeConfig.WithMemoryLimitPages(16384)
-	runtime := wasm.NewRuntimeWithConfig(ctx, runtimeConfig)
-	defer runtime.Close(ctx)
-
-	pluginPath, err := cmd.locatePlugin("go")
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+	// Sources with an ".idol" extension are compiled before being passed to
+	// the plugin.
+	var cache *compiler.Cache
+	if cmd.cacheDir != "" {
+		cache = compiler.NewCache(cmd.cacheDir)
 	}
-	pluginBin, err := os.ReadFile(pluginPath)
+	compileOpts, err := cmd.warnings.compileOptions()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
-	pluginExe, err := runtime.CompileModule(ctx, pluginBin)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	schemas, sourcePaths, ok := loadSchema(schemaPath, deps, cmd.importPath, cache, compileOpts)
+	if !ok {
 		return 1
 	}
-
-	moduleConfig := wasm.NewModuleConfig()
-	plugin, err := runtime.InstantiateModule(ctx, pluginExe, moduleConfig)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+	requestBuilder.Schema.Set(idol.Clone(schemas[0]).Self())
+	for _, dep := range schemas[1:] {
+		requestBuilder.Dependencies.Add(idol.Clone(dep).Self())
 	}
-	mem := plugin.Memory()
 
-	wasmAlloc := plugin.ExportedFunction("idol_codegen_allocate")
-	//wasmDealloc := plugin.ExportedFunction("idol_codegen_deallocate")
-	wasmGenerate := plugin.ExportedFunction("idol_codegen_generate/go")
+	// Files read by this run, for the depfile.
+	inputs := append(slices.Clone(argv), sourcePaths...)
+	inputs = append(inputs, cmd.pluginOptFiles...)
 
-	results, err := wasmAlloc.Call(ctx, uint64(len(requestBuf)))
+	pluginOpts, err := cmd.pluginOptions(targets)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
 
-	requestPtr := results[0]
-
-	mem.Write(uint32(requestPtr), requestBuf)
+	// Each plugin is sent only the options for its language.
+	requestBufs := make([][]uint8, len(targets))
+	for ii, target := range targets {
+		requestBuilder.PluginOptions.Clear()
+		if opts, ok := pluginOpts[target.language]; ok {
+			requestBuilder.PluginOptions.Add(opts)
+		}
+		requestBufs[ii], err = idol.Encode(&idol.EncodeCtx{}, requestBuilder)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
 
-	results, err = wasmAlloc.Call(ctx, 4)
+	runtimeConfig, compilationCache, err := cmd.runtimeConfig()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
-	responsePtrPtr := uint32(results[0])
+	if compilationCache != nil {
+		defer compilationCache.Close(ctx)
+	}
+	runtime := wasm.NewRuntimeWithConfig(ctx, runtimeConfig)
+	defer runtime.Close(ctx)
 
-	results, err = wasmGenerate.Call(ctx, requestPtr, uint64(responsePtrPtr))
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+	finder := newPluginFinder(cmd.pluginSearchPath(), runtime)
+	plugins := make([]codegenPlugin, len(targets))
+	for ii, target := range targets {
+		plugins[ii], err = finder.find(ctx, target.language)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		inputs = append(inputs, plugins[ii].path())
 	}
-	rc := uint8(results[0])
 
-	responsePtr, _ := mem.ReadUint32Le(responsePtrPtr)
-	responseLen, ok := mem.ReadUint32Le(responsePtr)
-	if !ok {
-		fmt.Fprintln(os.Stderr, "Failed to read response message length")
-		return 1
+	// Plugins run in parallel, and their output is written once they've
+	// all finished.
+	responses := make([]codegen_idl.CodegenResponse, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for ii, plugin := range plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pluginCtx := ctx
+			if cmd.pluginTimeout > 0 {
+				var cancel context.CancelFunc
+				pluginCtx, cancel = context.WithTimeout(ctx, cmd.pluginTimeout)
+				defer cancel()
+			}
+			responses[ii], errs[ii] = plugin.generate(pluginCtx, requestBufs[ii])
+			if errs[ii] != nil && errors.Is(pluginCtx.Err(), context.DeadlineExceeded) {
+				errs[ii] = fmt.Errorf("Plugin timed out after %v", cmd.pluginTimeout)
+			}
+		}()
 	}
-	responseBuf, ok := mem.Read(responsePtr, responseLen)
-	if !ok {
-		fmt.Fprintln(os.Stderr, "Failed to read response message")
-		return 1
+	wg.Wait()
+	failed := false
+	for ii, err := range errs {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", plugins[ii].path(), err)
+			failed = true
+			continue
+		}
+		if responses[ii].OutputFiles().Len() == 0 {
+			fmt.Fprintf(os.Stderr, "%s: Plugin did not generate any output files\n", plugins[ii].path())
+			failed = true
+		}
 	}
-
-	response, err := idol.DecodeAs[Response](&idol.DecodeCtx{}, responseBuf)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	if failed {
 		return 1
 	}
 
-	if rc != 0 {
-		// TODO: Replace any control characters with U+FFFD.
-		// TODO: trim newlines if present
-		fmt.Fprintf(os.Stderr, "%s\n", response.Error())
-		return 1
+	type output struct {
+		path    string
+		content []uint8
 	}
-
-	outputFiles := response.OutputFiles()
-	if outputFiles.Len() == 0 {
-		fmt.Fprintln(os.Stderr, "Plugin did not generate any output files")
-		return 1
+	var outputs []output
+	seenOutPaths := make(map[string]string)
+	for ii, target := range targets {
+		for _, outputFile := range responses[ii].OutputFiles().Iter() {
+			outPath, err := outputPath(target.outDir, outputFile)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			if other, ok := seenOutPaths[outPath]; ok {
+				fmt.Fprintf(
+					os.Stderr,
+					"Plugins for %q and %q both generated %s\n",
+					other, target.language, outPath,
+				)
+				return 1
+			}
+			seenOutPaths[outPath] = target.language
+			outputs = append(outputs, output{outPath, outputFile.Content().Collect()})
+		}
 	}
-	if err := os.MkdirAll(cmd.outDir, 0o755); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+
+	if cmd.check {
+		rc := 0
+		for _, output := range outputs {
+			upToDate, err := checkOutputFile(os.Stdout, output.path, output.content)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			if !upToDate {
+				rc = 1
+			}
+		}
+		return rc
 	}
-	for _, outputFile := range outputFiles.Iter() {
-		outPath, err := cmd.outPath(outputFile)
-		if err != nil {
+	var outPaths []string
+	for _, output := range outputs {
+		outPaths = append(outPaths, output.path)
+		if err := os.MkdirAll(filepath.Dir(output.path), 0o755); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			return 1
 		}
-
-		if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		if err := os.WriteFile(output.path, output.content, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if cmd.manifestPath != "" {
+		if err := writeOutput(cmd.manifestPath, manifestFile(outPaths)); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			return 1
 		}
-		content := outputFile.Content().Collect()
-		if err := os.WriteFile(outPath, content, 0o644); err != nil {
+	}
+	if cmd.depfilePath != "" {
+		depfile := depfileRule(outPaths, inputs)
+		if err := writeOutput(cmd.depfilePath, depfile); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			return 1
 		}
@@ -212,29 +292,241 @@ func (cmd *cmdCodegen) run(ctx context.Context, argv []string) int {
 	return 0
 }
 
-func (cmd *cmdCodegen) locatePlugin(language string) (string, error) {
+// runtimeConfig returns the configuration of the WASM runtime that plugins
+// run in, and the compilation cache (if any) that it uses.
+//
+// The compilation cache is stored in --plugin-cache-dir, or in a "wazero"
+// subdirectory of --cache-dir. Only the compiler runtime uses it, so the
+// interpreter ignores --cache-dir and rejects --plugin-cache-dir.
+func (cmd *cmdCodegen) runtimeConfig() (wasm.RuntimeConfig, wasm.CompilationCache, error) {
+	var config wasm.RuntimeConfig
+	switch cmd.pluginRuntime {
+	case "interpreter":
+		config = wasm.NewRuntimeConfigInterpreter()
+	case "compiler":
+		config = wasm.NewRuntimeConfigCompiler()
+	case "auto":
+		config = wasm.NewRuntimeConfig()
+	default:
+		return nil, nil, fmt.Errorf(
+			"Unsupported plugin runtime %q (choose 'interpreter', 'compiler', or 'auto')",
+			cmd.pluginRuntime,
+		)
+	}
+
+	memoryLimitPages, err := parseMemoryLimit(cmd.pluginMemoryLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	config = config.WithMemoryLimitPages(memoryLimitPages)
+
+	// Plugins are stopped when their context is cancelled, so that the
+	// --plugin-timeout limit can be enforced.
+	config = config.WithCloseOnContextDone(true)
+
+	if cmd.pluginRuntime == "interpreter" {
+		if cmd.pluginCacheDir != "" {
+			return nil, nil, errors.New(
+				"The interpreter plugin runtime can't use --plugin-cache-dir" +
+					" (choose --plugin-runtime 'compiler' or 'auto')",
+			)
+		}
+		return config, nil, nil
+	}
+
+	cacheDir := cmd.pluginCacheDir
+	if cacheDir == "" && cmd.cacheDir != "" {
+		cacheDir = filepath.Join(cmd.cacheDir, "wazero")
+	}
+	if cacheDir == "" {
+		return config, nil, nil
+	}
+	cache, err := wasm.NewCompilationCacheWithDir(cacheDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return config.WithCompilationCache(cache), cache, nil
+}
+
+// A codegenTarget is a language to generate code for, and the directory
+// its output files are written to.
+type codegenTarget struct {
+	language string
+	outDir   string
+}
+
+// targets parses the --lang flags, which are either "LANG" (writing to the
+// --output directory) or "LANG=DIR". The default is to generate Go.
+func (cmd *cmdCodegen) targets() ([]codegenTarget, error) {
+	languages := cmd.languages
+	if len(languages) == 0 {
+		languages = []string{"go"}
+	}
+	var targets []codegenTarget
+	for _, lang := range languages {
+		language, outDir, ok := strings.Cut(lang, "=")
+		if !ok {
+			outDir = cmd.outDir
+		}
+		if language == "" {
+			return nil, fmt.Errorf("Invalid language %q", lang)
+		}
+		if outDir == "" {
+			return nil, fmt.Errorf(
+				"No output directory specified for language %q (set --output= or --lang=%s=DIR)",
+				language, language,
+			)
+		}
+		for _, other := range targets {
+			if other.language == language {
+				return nil, fmt.Errorf("Language %q specified more than once", language)
+			}
+		}
+		targets = append(targets, codegenTarget{language, outDir})
+	}
+	return targets, nil
+}
+
+// pluginOptions collects the options set with --plugin-opt-file and
+// --plugin-opt, in that order, and returns the options of each target by
+// language. Option files contain one option per line, and may contain blank
+// lines and comments starting with '#'.
+//
+// Options have the form "LANG:KEY=VALUE", and are only sent to the plugin
+// for LANG. The "LANG:" prefix may be omitted if there's a single target.
+func (cmd *cmdCodegen) pluginOptions(
+	targets []codegenTarget,
+) (map[string]*schema_idl.UninterpretedOptions__Builder, error) {
+	type option struct {
+		name  string
+		value string
+	}
+	options := make(map[string][]option)
+	addOption := func(opt, name, value string) error {
+		language, scopedName, scoped := strings.Cut(name, ":")
+		if !scoped {
+			if len(targets) != 1 {
+				return fmt.Errorf(
+					"Plugin option %q must specify a language when generating more than one (expected LANG:KEY=VALUE)",
+					opt,
+				)
+			}
+			language, scopedName = targets[0].language, name
+		}
+		if !slices.ContainsFunc(targets, func(target codegenTarget) bool {
+			return target.language == language
+		}) {
+			return fmt.Errorf("Plugin option %q is for language %q, which isn't being generated", opt, language)
+		}
+		options[language] = append(options[language], option{scopedName, value})
+		return nil
+	}
+	for _, path := range cmd.pluginOptFiles {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for ii, line := range strings.Split(string(buf), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			name, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("%s:%d: Expected plugin option of the form [LANG:]KEY=VALUE", path, ii+1)
+			}
+			err := addOption(line, strings.TrimSpace(name), strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, ii+1, err)
+			}
+		}
+	}
+	for _, opt := range cmd.pluginOpts {
+		name, value, ok := strings.Cut(opt, "=")
+		if !ok {
+			return nil, fmt.Errorf("Invalid plugin option %q (expected [LANG:]KEY=VALUE)", opt)
+		}
+		if err := addOption(opt, name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	// Option values are passed as text, for the plugin to interpret.
+	builders := make(map[string]*schema_idl.UninterpretedOptions__Builder)
+	for language, languageOptions := range options {
+		builder := &schema_idl.UninterpretedOptions__Builder{}
+		for _, opt := range languageOptions {
+			optBuilder := &schema_idl.UninterpretedOption__Builder{}
+			optBuilder.Name.Set(opt.name)
+			optBuilder.Type.Set(schema_idl.Type_TEXT)
+			optBuilder.Value.SetBytes([]uint8(opt.value))
+			builder.Options.Add(optBuilder)
+		}
+		builders[language] = builder
+	}
+	return builders, nil
+}
+
+// checkOutputFile compares generated content with the file at outPath,
+// writing a unified diff to w if they differ or the file is missing.
+func checkOutputFile(w io.Writer, outPath string, content []uint8) (bool, error) {
+	fromFile := outPath
+	existing, err := os.ReadFile(outPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		fromFile = "/dev/null"
+	} else if err != nil {
+		return false, err
+	}
+	if bytes.Equal(existing, content) {
+		return true, nil
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(existing),
+		B:        diffLines(content),
+		FromFile: fromFile,
+		ToFile:   outPath,
+		Context:  3,
+	})
+	if err != nil {
+		return false, err
+	}
+	if diff == "" {
+		// The contents differ only in a trailing newline.
+		diff = fmt.Sprintf("--- %s\n+++ %s\n(differs in trailing newline)\n", fromFile, outPath)
+	}
+	if _, err := io.WriteString(w, diff); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// diffLines splits text into newline-terminated lines. A missing newline at
+// the end is added, so that contents differing only in a trailing newline
+// have an empty diff.
+func diffLines(text []uint8) []string {
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n"
+	}
+	return lines
+}
+
+// pluginSearchPath returns the directories set with --plugin-path or
+// $IDOL_CODEGEN_PLUGIN_PATH. Plugins are also found in $PATH.
+func (cmd *cmdCodegen) pluginSearchPath() []string {
 	path := cmd.pluginPath
 	if path == "" {
 		path = os.Getenv("IDOL_CODEGEN_PLUGIN_PATH")
 	}
 	if path == "" {
-		return "", fmt.Errorf("No plugin path set, use --plugin-path= or $IDOL_CODEGEN_PLUGIN_PATH")
-	}
-	basename := fmt.Sprintf("idol-codegen-%s.wasm", language)
-	// FIXME
-	for _, path := range strings.Split(path, ":") {
-		pluginPath := filepath.Join(path, basename)
-		if _, err := os.Stat(pluginPath); err == nil {
-			return pluginPath, nil
-		}
+		return nil
 	}
-	return "", fmt.Errorf(
-		"Idol codegen plugin %s not found in plugin path",
-		basename,
-	)
+	return filepath.SplitList(path)
 }
 
-func (cmd *cmdCodegen) outPath(file codegen_idl.OutputFile) (string, error) {
+func outputPath(outDir string, file codegen_idl.OutputFile) (string, error) {
 	parts := file.Path().Collect()
 	if len(parts) == 0 {
 		return "", fmt.Errorf("Invalid output path %#v: empty", parts)
@@ -250,5 +542,5 @@ func (cmd *cmdCodegen) outPath(file codegen_idl.OutputFile) (string, error) {
 			return "", fmt.Errorf("Invalid output path %#v: component %q contains '/'", parts, part)
 		}
 	}
-	return filepath.Join(append([]string{cmd.outDir}, parts...)...), nil
+	return filepath.Join(append([]string{outDir}, parts...)...), nil
 }
@@ -0,0 +1,503 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/codegen_idl"
+	"go.idol-lang.org/idol/schema_idl"
+)
+
+func TestRuntimeConfig(t *testing.T) {
+	tests := []struct {
+		runtime        string
+		cacheDir       string
+		pluginCacheDir string
+		cached         bool
+		err            string
+	}{
+		{"auto", "", "", false, ""},
+		{"auto", "cache", "", true, ""},
+		{"auto", "", "plugin-cache", true, ""},
+		{"compiler", "cache", "", true, ""},
+		{"interpreter", "", "", false, ""},
+		{"interpreter", "cache", "", false, ""},
+		{
+			"interpreter", "", "plugin-cache", false,
+			"The interpreter plugin runtime can't use --plugin-cache-dir" +
+				" (choose --plugin-runtime 'compiler' or 'auto')",
+		},
+		{
+			"jit", "", "", false,
+			`Unsupported plugin runtime "jit" (choose 'interpreter', 'compiler', or 'auto')`,
+		},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		cmd := &cmdCodegen{
+			pluginRuntime:     test.runtime,
+			pluginMemoryLimit: "512MiB",
+		}
+		if test.cacheDir != "" {
+			cmd.cacheDir = filepath.Join(dir, test.cacheDir)
+		}
+		if test.pluginCacheDir != "" {
+			cmd.pluginCacheDir = filepath.Join(dir, test.pluginCacheDir)
+		}
+		_, cache, err := cmd.runtimeConfig()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%+v: expected error %q, got %v", test, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: %v", test, err)
+			continue
+		}
+		if (cache != nil) != test.cached {
+			t.Errorf("%+v: expected cached=%v, got %v", test, test.cached, cache != nil)
+		}
+	}
+}
+
+// decodePluginOptions returns plugin options as "KEY=VALUE" strings, by
+// language.
+func decodePluginOptions(
+	t *testing.T,
+	builders map[string]*schema_idl.UninterpretedOptions__Builder,
+) map[string][]string {
+	t.Helper()
+	decoded := make(map[string][]string)
+	for language, builder := range builders {
+		encoded, err := idol.Encode(&idol.EncodeCtx{}, builder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		options, err := idol.DecodeAs[schema_idl.UninterpretedOptions](nil, encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, opt := range options.Options().Iter() {
+			if opt.Type() != schema_idl.Type_TEXT {
+				t.Errorf("option %q: expected type TEXT, got %v", opt.Name(), opt.Type())
+			}
+			decoded[language] = append(decoded[language], opt.Name()+"="+string(opt.Value().Collect()))
+		}
+	}
+	return decoded
+}
+
+func TestPluginOptions(t *testing.T) {
+	dir := t.TempDir()
+	optFile := filepath.Join(dir, "opts.txt")
+	err := os.WriteFile(optFile, []uint8(`# Go options
+package = example.com/test
+
+import.other=example.com/other
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := &cmdCodegen{
+		pluginOptFiles: []string{optFile},
+		pluginOpts:     []string{"fingerprints=false", "go:package=example.com/a=b"},
+	}
+	builders, err := cmd.pluginOptions([]codegenTarget{{"go", "out"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := decodePluginOptions(t, builders)
+	expect := []string{
+		"package=example.com/test",
+		"import.other=example.com/other",
+		"fingerprints=false",
+		"package=example.com/a=b",
+	}
+	if len(got) != 1 || !slices.Equal(got["go"], expect) {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+
+	builders, err = (&cmdCodegen{}).pluginOptions([]codegenTarget{{"go", "out"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builders) != 0 {
+		t.Errorf("expected no options, got %q", decodePluginOptions(t, builders))
+	}
+}
+
+func TestPluginOptions_MultipleLanguages(t *testing.T) {
+	dir := t.TempDir()
+	optFile := filepath.Join(dir, "opts.txt")
+	if err := os.WriteFile(optFile, []uint8("py:py_module = foo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &cmdCodegen{
+		pluginOptFiles: []string{optFile},
+		pluginOpts:     []string{"go:package=example.com/test", "py:py_path=a:b"},
+	}
+	builders, err := cmd.pluginOptions([]codegenTarget{{"go", "out"}, {"py", "out2"}, {"rust", "out3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := decodePluginOptions(t, builders)
+	if expect := []string{"package=example.com/test"}; !slices.Equal(got["go"], expect) {
+		t.Errorf("go: expected %q, got %q", expect, got["go"])
+	}
+	if expect := []string{"py_module=foo", "py_path=a:b"}; !slices.Equal(got["py"], expect) {
+		t.Errorf("py: expected %q, got %q", expect, got["py"])
+	}
+	if _, ok := builders["rust"]; ok {
+		t.Errorf("rust: expected no options, got %q", got["rust"])
+	}
+}
+
+func TestPluginOptions_Errors(t *testing.T) {
+	dir := t.TempDir()
+	optFile := filepath.Join(dir, "opts.txt")
+	if err := os.WriteFile(optFile, []uint8("a=1\n\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	goTarget := []codegenTarget{{"go", "out"}}
+	goPyTargets := []codegenTarget{{"go", "out"}, {"py", "out2"}}
+	tests := []struct {
+		cmd     *cmdCodegen
+		targets []codegenTarget
+		err     string
+	}{
+		{
+			&cmdCodegen{pluginOpts: []string{"package"}},
+			goTarget,
+			`Invalid plugin option "package" (expected [LANG:]KEY=VALUE)`,
+		},
+		{
+			&cmdCodegen{pluginOptFiles: []string{optFile}},
+			goTarget,
+			optFile + ":3: Expected plugin option of the form [LANG:]KEY=VALUE",
+		},
+		{
+			&cmdCodegen{pluginOpts: []string{"py_module=foo"}},
+			goPyTargets,
+			`Plugin option "py_module=foo" must specify a language when generating more than one (expected LANG:KEY=VALUE)`,
+		},
+		{
+			&cmdCodegen{pluginOptFiles: []string{optFile}},
+			goPyTargets,
+			optFile + `:1: Plugin option "a=1" must specify a language when generating more than one (expected LANG:KEY=VALUE)`,
+		},
+		{
+			&cmdCodegen{pluginOpts: []string{"rust:crate=foo"}},
+			goPyTargets,
+			`Plugin option "rust:crate=foo" is for language "rust", which isn't being generated`,
+		},
+		{
+			&cmdCodegen{pluginOpts: []string{":package=foo"}},
+			goTarget,
+			`Plugin option ":package=foo" is for language "", which isn't being generated`,
+		},
+	}
+	for _, test := range tests {
+		_, err := test.cmd.pluginOptions(test.targets)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("expected error %q, got %v", test.err, err)
+		}
+	}
+
+	cmd := &cmdCodegen{pluginOptFiles: []string{filepath.Join(dir, "missing.txt")}}
+	if _, err := cmd.pluginOptions(goTarget); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestTargets(t *testing.T) {
+	tests := []struct {
+		languages []string
+		outDir    string
+		targets   []codegenTarget
+		err       string
+	}{
+		{nil, "out", []codegenTarget{{"go", "out"}}, ""},
+		{[]string{"go"}, "out", []codegenTarget{{"go", "out"}}, ""},
+		{
+			[]string{"go=gen/go", "rust=gen/rust"},
+			"",
+			[]codegenTarget{{"go", "gen/go"}, {"rust", "gen/rust"}},
+			"",
+		},
+		{
+			[]string{"go", "rust=gen/rust"},
+			"out",
+			[]codegenTarget{{"go", "out"}, {"rust", "gen/rust"}},
+			"",
+		},
+		{
+			nil, "", nil,
+			`No output directory specified for language "go" (set --output= or --lang=go=DIR)`,
+		},
+		{
+			[]string{"go=gen/go", "rust"}, "", nil,
+			`No output directory specified for language "rust" (set --output= or --lang=rust=DIR)`,
+		},
+		{
+			[]string{"go=", "rust"}, "out", nil,
+			`No output directory specified for language "go" (set --output= or --lang=go=DIR)`,
+		},
+		{[]string{"=gen"}, "out", nil, `Invalid language "=gen"`},
+		{[]string{""}, "out", nil, `Invalid language ""`},
+		{
+			[]string{"go", "go=gen/go"}, "out", nil,
+			`Language "go" specified more than once`,
+		},
+	}
+	for _, test := range tests {
+		cmd := &cmdCodegen{languages: test.languages, outDir: test.outDir}
+		targets, err := cmd.targets()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%q: expected error %q, got %v", test.languages, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.languages, err)
+			continue
+		}
+		if !slices.Equal(targets, test.targets) {
+			t.Errorf("%q: expected %v, got %v", test.languages, test.targets, targets)
+		}
+	}
+}
+
+func TestCheckOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "out.txt")
+	missingPath := filepath.Join(dir, "missing.txt")
+	if err := os.WriteFile(outPath, []uint8("a\nb\nc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path     string
+		content  string
+		upToDate bool
+		diff     string
+	}{
+		{outPath, "a\nb\nc\n", true, ""},
+		{
+			outPath, "a\nB\nc\n", false,
+			"--- {out}\n+++ {out}\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			outPath, "a\nb\nc", false,
+			"--- {out}\n+++ {out}\n(differs in trailing newline)\n",
+		},
+		{
+			missingPath, "a\n", false,
+			"--- /dev/null\n+++ {missing}\n@@ -0,0 +1 @@\n+a\n",
+		},
+	}
+	for _, test := range tests {
+		var diff strings.Builder
+		upToDate, err := checkOutputFile(&diff, test.path, []uint8(test.content))
+		if err != nil {
+			t.Errorf("%q: %v", test.content, err)
+			continue
+		}
+		if upToDate != test.upToDate {
+			t.Errorf("%q: expected upToDate=%v, got %v", test.content, test.upToDate, upToDate)
+		}
+		expect := strings.NewReplacer("{out}", outPath, "{missing}", missingPath).Replace(test.diff)
+		if diff.String() != expect {
+			t.Errorf("%q: expected diff %q, got %q", test.content, expect, diff.String())
+		}
+	}
+
+	// Checking never creates or modifies the file.
+	if _, err := os.Stat(missingPath); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %s to not exist, got %v", missingPath, err)
+	}
+	if buf, err := os.ReadFile(outPath); err != nil || string(buf) != "a\nb\nc\n" {
+		t.Errorf("%s was modified: %q, %v", outPath, buf, err)
+	}
+}
+
+// fakePlugin writes a native plugin for a language to dir, which saves each
+// request to "<dir>/<language>.request" and responds by writing response to
+// stdout.
+func fakePlugin(
+	t *testing.T,
+	dir string,
+	language string,
+	response *codegen_idl.CodegenResponse__Builder,
+) {
+	t.Helper()
+	responseBuf, err := idol.Encode(&idol.EncodeCtx{}, response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responsePath := filepath.Join(dir, language+".response")
+	if err := os.WriteFile(responsePath, responseBuf, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	requestPath := filepath.Join(dir, language+".request")
+	script := "#!/bin/sh\ncat >'" + requestPath + "'\ncat '" + responsePath + "'\n"
+	pluginPath := filepath.Join(dir, "idol-codegen-"+language)
+	if err := os.WriteFile(pluginPath, []uint8(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTestSchema writes a minimal schema source to dir, returning its path.
+func writeTestSchema(t *testing.T, dir string) string {
+	t.Helper()
+	schemaPath := filepath.Join(dir, "test.idol")
+	if err := os.WriteFile(schemaPath, []uint8("namespace \"test\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return schemaPath
+}
+
+// testResponse returns a plugin response containing one output file.
+func testResponse(path []string, content string) *codegen_idl.CodegenResponse__Builder {
+	outputFile := &codegen_idl.OutputFile__Builder{}
+	outputFile.Path.Set(path)
+	outputFile.Content.SetString(content)
+	response := &codegen_idl.CodegenResponse__Builder{}
+	response.OutputFiles.Add(outputFile)
+	return response
+}
+
+func TestCodegen_PluginOptions(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := writeTestSchema(t, dir)
+	fakePlugin(t, dir, "go", testResponse([]string{"test.go"}, "package test\n"))
+	fakePlugin(t, dir, "py", testResponse([]string{"test.py"}, "pass\n"))
+
+	cmd := &cmdCodegen{
+		outDir:            filepath.Join(dir, "out"),
+		pluginPath:        dir,
+		languages:         []string{"go", "py=" + filepath.Join(dir, "out2")},
+		pluginOpts:        []string{"py:py_module=foo"},
+		pluginRuntime:     "interpreter",
+		pluginMemoryLimit: "512MiB",
+	}
+	if rc := cmd.run(context.Background(), []string{schemaPath}); rc != 0 {
+		t.Fatalf("expected exit status 0, got %d", rc)
+	}
+
+	requestOptions := func(language string) []string {
+		t.Helper()
+		buf, err := os.ReadFile(filepath.Join(dir, language+".request"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		request, err := idol.DecodeAs[codegen_idl.CodegenRequest](nil, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var options []string
+		for _, opts := range request.PluginOptions().Iter() {
+			for _, opt := range opts.Options().Iter() {
+				options = append(options, opt.Name()+"="+string(opt.Value().Collect()))
+			}
+		}
+		return options
+	}
+	if got := requestOptions("go"); len(got) != 0 {
+		t.Errorf("go: expected no options, got %q", got)
+	}
+	if got, expect := requestOptions("py"), []string{"py_module=foo"}; !slices.Equal(got, expect) {
+		t.Errorf("py: expected %q, got %q", expect, got)
+	}
+}
+
+func TestCodegen_Check(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := writeTestSchema(t, dir)
+	fakePlugin(t, dir, "test", testResponse([]string{"gen", "test.txt"}, "generated\n"))
+
+	// Diffs are written to stdout.
+	stdout, err := os.Create(filepath.Join(dir, "stdout.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	realStdout := os.Stdout
+	os.Stdout = stdout
+	defer func() { os.Stdout = realStdout }()
+
+	outDir := filepath.Join(dir, "out")
+	manifestPath := filepath.Join(dir, "manifest.txt")
+	depfilePath := filepath.Join(dir, "out.d")
+	check := func() int {
+		t.Helper()
+		cmd := &cmdCodegen{
+			outDir:            outDir,
+			pluginPath:        dir,
+			languages:         []string{"test"},
+			check:             true,
+			manifestPath:      manifestPath,
+			depfilePath:       depfilePath,
+			pluginRuntime:     "interpreter",
+			pluginMemoryLimit: "512MiB",
+		}
+		return cmd.run(context.Background(), []string{schemaPath})
+	}
+	expectNotExist := func(path string) {
+		t.Helper()
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected %s to not exist, got %v", path, err)
+		}
+	}
+
+	// A missing output is reported, and not written.
+	if rc := check(); rc != 1 {
+		t.Errorf("expected exit status 1, got %d", rc)
+	}
+	expectNotExist(outDir)
+	expectNotExist(manifestPath)
+	expectNotExist(depfilePath)
+	outPath := filepath.Join(outDir, "gen", "test.txt")
+	diff, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(diff), "+++ "+outPath+"\n") {
+		t.Errorf("expected a diff of %s, got %q", outPath, diff)
+	}
+
+	// An up-to-date output is left alone.
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(outPath, []uint8("generated\n"), 0o444); err != nil {
+		t.Fatal(err)
+	}
+	if rc := check(); rc != 0 {
+		t.Errorf("expected exit status 0, got %d", rc)
+	}
+	expectNotExist(manifestPath)
+	expectNotExist(depfilePath)
+}
@@ -16,65 +16,187 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/pflag"
 
 	"go.idol-lang.org/idol"
 	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/encoding/idoljson"
 	"go.idol-lang.org/idol/encoding/idoltext"
 	"go.idol-lang.org/idol/schema_idl"
 	"go.idol-lang.org/idol/syntax"
 )
 
 type cmdCompile struct {
-	outPath string
-	format  string
+	outPaths         []string
+	depfilePath      string
+	format           string
+	stripDocComments bool
+	sourceInfo       bool
+	importPath       []string
+	cacheDir         string
+	warnings         warningFlags
 }
 
 func (*cmdCompile) help() *commandHelp {
 	return &commandHelp{
-		usage: "compile",
+		usage:   "compile IDOL_SOURCE... [DEPENDENCY...]",
+		summary: "Compile schema sources to binary, text, or JSON",
 	}
 }
 
 func (cmd *cmdCompile) flags(flags *pflag.FlagSet) {
-	flags.StringVarP(&cmd.outPath, "output", "o", "", "(docs TODO)")
+	flags.StringArrayVarP(&cmd.outPaths, "output", "o", nil, "(docs TODO)")
 	flags.StringVarP(&cmd.format, "format", "f", "", "(docs TODO)")
+	flags.StringVar(&cmd.depfilePath, "depfile", "", "(docs TODO)")
+	flags.BoolVar(&cmd.stripDocComments, "strip-doc-comments", false, "(docs TODO)")
+	flags.BoolVar(&cmd.sourceInfo, "source-info", false, "(docs TODO)")
+	flags.StringArrayVarP(&cmd.importPath, "import-path", "I", nil, "(docs TODO)")
+	flags.StringVar(&cmd.cacheDir, "cache-dir", "", "(docs TODO)")
+	cmd.warnings.flags(flags)
+}
+
+type outputFormat uint8
+
+const (
+	outputBinary outputFormat = iota + 1
+	outputText
+	outputJSON
+)
+
+func parseOutputFormat(format string) (outputFormat, bool) {
+	switch format {
+	case "text", "idoltext":
+		return outputText, true
+	case "bin", "binary", "idolbin":
+		return outputBinary, true
+	case "json":
+		return outputJSON, true
+	}
+	return 0, false
+}
+
+// guessOutputFormat selects an output format based on the extension of the
+// output path.
+func guessOutputFormat(outPath string) (outputFormat, bool) {
+	switch filepath.Ext(outPath) {
+	case ".txt", ".idoltext":
+		return outputText, true
+	case ".bin", ".idolbin":
+		return outputBinary, true
+	case ".json":
+		return outputJSON, true
+	}
+	return 0, false
+}
+
+// expandOutputPath expands an output path template for a source file. The
+// template may contain "{dir}" (the directory containing the source) and
+// "{name}" (the source's file name without its ".idol" extension).
+func expandOutputPath(template, srcPath string) string {
+	name := strings.TrimSuffix(filepath.Base(srcPath), ".idol")
+	return strings.NewReplacer(
+		"{dir}", filepath.Dir(srcPath),
+		"{name}", name,
+	).Replace(template)
+}
+
+// splitCompileArgs splits arguments into source paths and compiled
+// dependency paths. Sources are recognized by their ".idol" extension. If no
+// argument has the extension then the first is the source, as in earlier
+// versions which compiled a single source.
+func splitCompileArgs(argv []string) ([]string, []string) {
+	var srcPaths, depPaths []string
+	for _, arg := range argv {
+		if filepath.Ext(arg) == ".idol" {
+			srcPaths = append(srcPaths, arg)
+		} else {
+			depPaths = append(depPaths, arg)
+		}
+	}
+	if len(srcPaths) == 0 && len(depPaths) > 0 {
+		srcPaths, depPaths = depPaths[:1], depPaths[1:]
+	}
+	return srcPaths, depPaths
 }
 
 func (cmd *cmdCompile) run(ctx context.Context, argv []string) int {
-	if len(argv) < 1 {
-		// log.Fatalf("usage: %s IDOL_SCHEMA [deps...]", os.Args[0])
-		panic("todo: message on not enough args")
+	srcPaths, depPaths := splitCompileArgs(argv)
+	if len(srcPaths) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: idol compile IDOL_SOURCE... [DEPENDENCY...]")
 		return 1
 	}
-	srcPath := argv[0]
-
-	outputText := false
-	switch cmd.format {
-	case "":
-		// FIXME: Select default format based on output path extension.
-		//
-		// ".txt", ".idoltext" -> "text"
-		// ".bin", ".idolbin" -> "binary"
-		//
-		// Error out only if can't guess.
-		fmt.Fprintln(os.Stderr, "No format selected (choose 'text' or 'binary')")
-		return 1
-	case "text", "idoltext":
-		outputText = true
-	case "bin", "binary", "idolbin":
+
+	// A single output path is a template for every source, otherwise each
+	// source has its own output path.
+	outPaths := make([]string, len(srcPaths))
+	switch len(cmd.outPaths) {
+	case 0:
+		if len(srcPaths) > 1 {
+			fmt.Fprintln(os.Stderr, "Multiple sources require an output path (set --output=)")
+			return 1
+		}
+	case 1:
+		for ii, srcPath := range srcPaths {
+			outPaths[ii] = expandOutputPath(cmd.outPaths[0], srcPath)
+		}
+	case len(srcPaths):
+		for ii, srcPath := range srcPaths {
+			outPaths[ii] = expandOutputPath(cmd.outPaths[ii], srcPath)
+		}
 	default:
-		fmt.Fprintf(os.Stderr, "Unsupported output format %q\n", cmd.format)
+		fmt.Fprintf(
+			os.Stderr,
+			"Got %d output paths for %d sources (set --output= once, or once per source)\n",
+			len(cmd.outPaths), len(srcPaths),
+		)
+		return 1
+	}
+	seenOutPaths := make(map[string]string)
+	for ii, outPath := range outPaths {
+		if other, ok := seenOutPaths[outPath]; ok {
+			fmt.Fprintf(
+				os.Stderr,
+				"Sources %s and %s have the same output path %q\n",
+				other, srcPaths[ii], outPath,
+			)
+			return 1
+		}
+		seenOutPaths[outPath] = srcPaths[ii]
+	}
+	if cmd.depfilePath != "" && len(cmd.outPaths) == 0 {
+		fmt.Fprintln(os.Stderr, "Writing a depfile requires an output path (set --output=)")
 		return 1
 	}
 
+	formats := make([]outputFormat, len(srcPaths))
+	for ii, outPath := range outPaths {
+		var ok bool
+		if cmd.format != "" {
+			formats[ii], ok = parseOutputFormat(cmd.format)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Unsupported output format %q\n", cmd.format)
+				return 1
+			}
+			continue
+		}
+		formats[ii], ok = guessOutputFormat(outPath)
+		if !ok {
+			fmt.Fprintln(os.Stderr, "No format selected (choose 'text', 'binary', or 'json')")
+			return 1
+		}
+	}
+
 	var deps []schema_idl.Schema
-	for _, depPath := range argv[1:] {
+	for _, depPath := range depPaths {
 		depBuf, err := os.ReadFile(depPath)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
@@ -91,85 +213,154 @@ func (cmd *cmdCompile) run(ctx context.Context, argv []string) int {
 	}
 
 	var opts []compiler.CompileOption
-	if len(deps) > 0 {
-		mergedDeps, err := compiler.Merge(deps)
-		if err != nil {
+	if cmd.stripDocComments {
+		opts = append(opts, compiler.WithoutDocComments())
+	}
+
+	if cmd.sourceInfo {
+		opts = append(opts, compiler.WithSourceInfo(true))
+	}
+
+	var cache *compiler.Cache
+	if cmd.cacheDir != "" {
+		cache = compiler.NewCache(cmd.cacheDir)
+	}
+
+	// Imported schemas are compiled without the warning options.
+	loaderOpts := slices.Clip(opts)
+
+	warningOpts, err := cmd.warnings.compileOptions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	opts = append(opts, warningOpts...)
+
+	// Errors in one source don't prevent the others from being compiled.
+	rc := 0
+	var depfile strings.Builder
+	for ii, srcPath := range srcPaths {
+		output, inputs, ok := cmd.compile(srcPath, deps, cache, loaderOpts, opts, formats[ii])
+		if !ok {
+			rc = 1
+			continue
+		}
+		if err := writeOutput(outPaths[ii], output); err != nil {
 			fmt.Fprintln(os.Stderr, err)
-			return 1
+			rc = 1
+			continue
 		}
-		opts = append(opts, compiler.WithDependencies(mergedDeps))
+		depfile.WriteString(depfileRule(outPaths[ii:ii+1], append(inputs, depPaths...)))
 	}
-
-	if !filepath.IsAbs(srcPath) {
-		opts = append(opts, compiler.WithSourcePath(splitPath(srcPath)))
+	if rc == 0 && cmd.depfilePath != "" {
+		if err := writeOutput(cmd.depfilePath, depfile.String()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
 	}
+	return rc
+}
 
+func (cmd *cmdCompile) compile(
+	srcPath string,
+	deps []schema_idl.Schema,
+	cache *compiler.Cache,
+	loaderOpts []compiler.CompileOption,
+	opts []compiler.CompileOption,
+	format outputFormat,
+) (string, []string, bool) {
+	inputs := []string{srcPath}
 	src, err := os.ReadFile(srcPath)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		return 1
+		return "", nil, false
 	}
 	parsed, err := syntax.Parse(src)
 	if err != nil {
 		// TODO: Map error span to line + column location
 		fmt.Fprintln(os.Stderr, err)
-		return 1
+		return "", nil, false
+	}
+
+	if len(cmd.importPath) > 0 {
+		loader := compiler.NewLoader(cmd.importPath, loaderOpts...)
+		if cache != nil {
+			loader.SetCache(cache)
+		}
+		for _, dep := range deps {
+			loader.AddSchema(dep)
+		}
+		deps, err = loader.Imports(parsed)
+		if err != nil {
+			reportLoadError(err, srcPath, src)
+			return "", nil, false
+		}
+		inputs = append(inputs, loader.SourcePaths()...)
+	}
+
+	opts = slices.Clip(opts)
+	if !filepath.IsAbs(srcPath) {
+		opts = append(opts, compiler.WithSourcePath(splitPath(srcPath)))
 	}
 
 	// TODO:
 	// - interleave by line number?
 	// - Different colors for warnings vs errors?
-	// - Map spans to line + column locations
-	result := compiler.Compile(parsed, opts...)
-	for _, warn := range result.Warnings {
-		fmt.Fprintf(os.Stderr, "%v\n", warn)
+	result, err := compileSource(src, parsed, deps, cache, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return "", nil, false
 	}
-	if len(result.Errors) > 0 {
-		for _, err := range result.Errors {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-		}
-		return 1
+	if !reportDiagnostics(srcPath, src, result) {
+		return "", nil, false
 	}
 
-	var output string
-	if outputText {
-		schema, err := result.Schema()
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			return 1
-		}
-		output = idoltext.Encode(schema)
-	} else {
-		output, err = result.EncodedSchema()
+	if format == outputBinary {
+		output, err := result.EncodedSchema()
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
-			return 1
+			return "", nil, false
 		}
+		return output, inputs, true
 	}
 
-	if cmd.outPath == "" {
-		if _, err := os.Stdout.WriteString(output); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			return 1
-		}
-		return 0
+	schema, err := result.Schema()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return "", nil, false
+	}
+	if format == outputText {
+		return idoltext.Encode(schema), inputs, true
+	}
+	output, err := idoljson.Encode(schema)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return "", nil, false
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, output, "", "  "); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return "", nil, false
+	}
+	return indented.String() + "\n", inputs, true
+}
+
+// writeOutput writes output to outPath, or to stdout if outPath is empty.
+func writeOutput(outPath string, output string) error {
+	if outPath == "" {
+		_, err := os.Stdout.WriteString(output)
+		return err
 	}
 
 	openFlags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
-	fp, err := os.OpenFile(cmd.outPath, openFlags, 0o666)
+	fp, err := os.OpenFile(outPath, openFlags, 0o666)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+		return err
 	}
 	_, writeErr := fp.WriteString(output)
 	closeErr := fp.Close()
 	if writeErr != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
-	}
-	if closeErr != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+		return writeErr
 	}
-	return 0
+	return closeErr
 }
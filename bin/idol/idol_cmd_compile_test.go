@@ -0,0 +1,125 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitCompileArgs(t *testing.T) {
+	tests := []struct {
+		argv     []string
+		srcPaths []string
+		depPaths []string
+	}{
+		{nil, nil, nil},
+		{[]string{"a.idol"}, []string{"a.idol"}, nil},
+		{[]string{"a.idol", "dep.idolbin"}, []string{"a.idol"}, []string{"dep.idolbin"}},
+		{[]string{"dep.idolbin", "a.idol", "b.idol"}, []string{"a.idol", "b.idol"}, []string{"dep.idolbin"}},
+		// Without any ".idol" argument, the first argument is the source.
+		{[]string{"a.schema"}, []string{"a.schema"}, nil},
+		{[]string{"a.schema", "dep.idolbin"}, []string{"a.schema"}, []string{"dep.idolbin"}},
+	}
+	for _, test := range tests {
+		srcPaths, depPaths := splitCompileArgs(test.argv)
+		if !slices.Equal(srcPaths, test.srcPaths) || !slices.Equal(depPaths, test.depPaths) {
+			t.Errorf(
+				"splitCompileArgs(%q): expected %q, %q, got %q, %q",
+				test.argv, test.srcPaths, test.depPaths, srcPaths, depPaths,
+			)
+		}
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		want   outputFormat
+		ok     bool
+	}{
+		{"text", outputText, true},
+		{"idoltext", outputText, true},
+		{"bin", outputBinary, true},
+		{"binary", outputBinary, true},
+		{"idolbin", outputBinary, true},
+		{"json", outputJSON, true},
+		{"", 0, false},
+		{"JSON", 0, false},
+		{"yaml", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := parseOutputFormat(test.format)
+		if got != test.want || ok != test.ok {
+			t.Errorf(
+				"parseOutputFormat(%q): expected %v, %v, got %v, %v",
+				test.format, test.want, test.ok, got, ok,
+			)
+		}
+	}
+}
+
+func TestGuessOutputFormat(t *testing.T) {
+	tests := []struct {
+		outPath string
+		want    outputFormat
+		ok      bool
+	}{
+		{"out/schema.txt", outputText, true},
+		{"schema.idoltext", outputText, true},
+		{"schema.bin", outputBinary, true},
+		{"out/schema.idolbin", outputBinary, true},
+		{"schema.json", outputJSON, true},
+		{"schema", 0, false},
+		{"schema.idol", 0, false},
+		{"schema.json.gz", 0, false},
+		{"json", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := guessOutputFormat(test.outPath)
+		if got != test.want || ok != test.ok {
+			t.Errorf(
+				"guessOutputFormat(%q): expected %v, %v, got %v, %v",
+				test.outPath, test.want, test.ok, got, ok,
+			)
+		}
+	}
+}
+
+func TestExpandOutputPath(t *testing.T) {
+	tests := []struct {
+		template string
+		srcPath  string
+		want     string
+	}{
+		{"out.idolbin", "a/b.idol", "out.idolbin"},
+		{"{dir}/{name}.idolbin", "a/b.idol", "a/b.idolbin"},
+		{"out/{name}.json", "a/b/c.idol", "out/c.json"},
+		{"{dir}/{name}.idolbin", "b.idol", "./b.idolbin"},
+		{"{name}-{name}.txt", "b.idol", "b-b.txt"},
+		// Only the ".idol" extension is removed.
+		{"{name}.idolbin", "a/b.schema", "b.schema.idolbin"},
+	}
+	for _, test := range tests {
+		got := expandOutputPath(test.template, test.srcPath)
+		if got != test.want {
+			t.Errorf(
+				"expandOutputPath(%q, %q): expected %q, got %q",
+				test.template, test.srcPath, test.want, got,
+			)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"go.idol-lang.org/idol/encoding/dynamic"
+	"go.idol-lang.org/idol/encoding/idoltext"
+)
+
+type cmdDecode struct {
+	format     string
+	importPath []string
+	deps       []string
+}
+
+func (*cmdDecode) help() *commandHelp {
+	return &commandHelp{
+		usage:   "decode IDOL_SCHEMA MESSAGE [FILE]",
+		summary: "Print a binary message as text or JSON",
+	}
+}
+
+func (cmd *cmdDecode) flags(flags *pflag.FlagSet) {
+	flags.StringVarP(&cmd.format, "format", "f", "text", "(docs TODO)")
+	flags.StringArrayVarP(&cmd.importPath, "import-path", "I", nil, "(docs TODO)")
+	flags.StringArrayVar(&cmd.deps, "dep", nil, "(docs TODO)")
+}
+
+func (cmd *cmdDecode) run(ctx context.Context, argv []string) int {
+	if len(argv) < 2 || len(argv) > 3 {
+		fmt.Fprintln(os.Stderr, "usage: idol decode IDOL_SCHEMA MESSAGE [FILE]")
+		return 1
+	}
+
+	outputJSON := false
+	switch cmd.format {
+	case "text", "idoltext":
+	case "json":
+		outputJSON = true
+	default:
+		fmt.Fprintf(os.Stderr, "Unsupported output format %q\n", cmd.format)
+		return 1
+	}
+
+	schemas, namespace, name, ok := loadMessageSchema(argv[0], argv[1], cmd.deps, cmd.importPath)
+	if !ok {
+		return 1
+	}
+
+	inputPath := "<stdin>"
+	var buf []uint8
+	var err error
+	if len(argv) < 3 || argv[2] == "-" {
+		buf, err = io.ReadAll(os.Stdin)
+	} else {
+		inputPath = argv[2]
+		buf, err = os.ReadFile(inputPath)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	msg, err := dynamic.Decode(schemas, namespace, name, buf)
+	if err != nil {
+		var decodeErr *dynamic.DecodeError
+		if errors.As(err, &decodeErr) {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", inputPath, err)
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return 1
+	}
+
+	var output string
+	if outputJSON {
+		encoded, err := json.MarshalIndent(msg, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		output = string(encoded) + "\n"
+	} else {
+		output = idoltext.EncodeFields(msg)
+	}
+	if _, err := os.Stdout.WriteString(output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"go.idol-lang.org/idol/encoding/dynamic"
+	"go.idol-lang.org/idol/encoding/idoltext"
+)
+
+type cmdEncode struct {
+	outPath    string
+	importPath []string
+	deps       []string
+}
+
+func (*cmdEncode) help() *commandHelp {
+	return &commandHelp{
+		usage:   "encode IDOL_SCHEMA MESSAGE [FILE]",
+		summary: "Encode a message from text to binary",
+	}
+}
+
+func (cmd *cmdEncode) flags(flags *pflag.FlagSet) {
+	flags.StringVarP(&cmd.outPath, "output", "o", "", "(docs TODO)")
+	flags.StringArrayVarP(&cmd.importPath, "import-path", "I", nil, "(docs TODO)")
+	flags.StringArrayVar(&cmd.deps, "dep", nil, "(docs TODO)")
+}
+
+func (cmd *cmdEncode) run(ctx context.Context, argv []string) int {
+	if len(argv) < 2 || len(argv) > 3 {
+		fmt.Fprintln(os.Stderr, "usage: idol encode IDOL_SCHEMA MESSAGE [FILE]")
+		return 1
+	}
+
+	schemas, namespace, name, ok := loadMessageSchema(argv[0], argv[1], cmd.deps, cmd.importPath)
+	if !ok {
+		return 1
+	}
+
+	inputPath := "<stdin>"
+	var src []uint8
+	var err error
+	if len(argv) < 3 || argv[2] == "-" {
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		inputPath = argv[2]
+		src, err = os.ReadFile(inputPath)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	msg, err := dynamic.ParseText(schemas, namespace, name, src)
+	if err != nil {
+		var parseErr *idoltext.ParseError
+		var textErr *dynamic.TextError
+		if errors.As(err, &parseErr) || errors.As(err, &textErr) {
+			fmt.Fprintf(os.Stderr, "%s:%v\n", inputPath, err)
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return 1
+	}
+	output, err := dynamic.Encode(msg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if cmd.outPath == "" {
+		if _, err := os.Stdout.Write(output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	if err := os.WriteFile(cmd.outPath, output, 0o666); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
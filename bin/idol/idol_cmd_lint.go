@@ -0,0 +1,131 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/pflag"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/lint"
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+type cmdLint struct {
+	importPath    []string
+	disableRules  []string
+	maxStructSize uint32
+}
+
+func (*cmdLint) help() *commandHelp {
+	return &commandHelp{
+		usage:   "lint IDOL_SCHEMA [deps...]",
+		summary: "Check a schema against style and convention rules",
+	}
+}
+
+func (cmd *cmdLint) flags(flags *pflag.FlagSet) {
+	flags.StringArrayVarP(&cmd.importPath, "import-path", "I", nil, "(docs TODO)")
+	flags.StringArrayVar(&cmd.disableRules, "disable", nil, "(docs TODO)")
+	flags.Uint32Var(&cmd.maxStructSize, "max-struct-size", 0, "(docs TODO)")
+}
+
+func (cmd *cmdLint) run(ctx context.Context, argv []string) int {
+	if len(argv) < 1 {
+		fmt.Fprintln(os.Stderr, "usage: idol lint IDOL_SCHEMA [deps...]")
+		return 1
+	}
+	srcPath := argv[0]
+
+	var lintOpts []lint.Option
+	for _, rule := range cmd.disableRules {
+		if !slices.Contains(lint.Rules(), rule) {
+			fmt.Fprintf(os.Stderr, "Unknown lint rule %q\n", rule)
+			return 1
+		}
+		lintOpts = append(lintOpts, lint.WithoutRule(rule))
+	}
+	if cmd.maxStructSize != 0 {
+		lintOpts = append(lintOpts, lint.WithMaxStructSize(cmd.maxStructSize))
+	}
+
+	var deps []schema_idl.Schema
+	for _, depPath := range argv[1:] {
+		depBuf, err := os.ReadFile(depPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		dep, err := idol.DecodeAs[schema_idl.Schema](&idol.DecodeCtx{}, depBuf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", depPath, err)
+			return 1
+		}
+		deps = append(deps, dep)
+	}
+
+	src, err := os.ReadFile(srcPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	parsed, err := syntax.Parse(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", srcPath, err)
+		return 1
+	}
+
+	if len(cmd.importPath) > 0 {
+		loader := compiler.NewLoader(cmd.importPath)
+		for _, dep := range deps {
+			loader.AddSchema(dep)
+		}
+		deps, err = loader.Imports(parsed)
+		if err != nil {
+			reportLoadError(err, srcPath, src)
+			return 1
+		}
+	}
+
+	result, err := compileSource(src, parsed, deps, nil, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !reportDiagnostics(srcPath, src, result) {
+		return 1
+	}
+	schema, err := result.Schema()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	findings := lint.Lint(parsed, schema, lintOpts...)
+	for _, finding := range findings {
+		reportAt(srcPath, src, finding.Span(), finding)
+	}
+	if len(findings) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,349 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	wasm "github.com/tetratelabs/wazero"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/codegen_idl"
+)
+
+const wasmPageSize = 64 * 1024
+
+// parseMemoryLimit parses a WASM memory limit such as "512MiB" or "1GiB",
+// returning it as a number of 64 KiB pages. Limits without a unit are in
+// bytes, and are rounded up to a whole number of pages.
+func parseMemoryLimit(limit string) (uint32, error) {
+	units := []struct {
+		suffix string
+		size   uint64
+	}{
+		{"KiB", 1 << 10},
+		{"MiB", 1 << 20},
+		{"GiB", 1 << 30},
+	}
+	number, size := limit, uint64(1)
+	for _, unit := range units {
+		if trimmed, ok := strings.CutSuffix(limit, unit.suffix); ok {
+			number, size = trimmed, unit.size
+			break
+		}
+	}
+	value, err := strconv.ParseUint(number, 10, 64)
+	if err != nil || value == 0 {
+		return 0, fmt.Errorf("Invalid plugin memory limit %q", limit)
+	}
+	if value > math.MaxUint64/size {
+		return 0, fmt.Errorf("Plugin memory limit %q exceeds the maximum of 4GiB", limit)
+	}
+	pages := value * size / wasmPageSize
+	if value*size%wasmPageSize != 0 {
+		pages += 1
+	}
+	if pages > 65536 {
+		return 0, fmt.Errorf("Plugin memory limit %q exceeds the maximum of 4GiB", limit)
+	}
+	return uint32(pages), nil
+}
+
+// A codegenPlugin generates code for a single language.
+type codegenPlugin interface {
+	// path returns the path of the plugin, for error messages and depfiles.
+	path() string
+	generate(ctx context.Context, request []uint8) (codegen_idl.CodegenResponse, error)
+}
+
+// A pluginFinder locates codegen plugins in the plugin search path, falling
+// back to native executables in $PATH.
+//
+// Within each directory of the search path, the plugin for a language is
+// the first of:
+//
+//   - A WASM module named "idol-codegen-<lang>.wasm".
+//   - A native executable named "idol-codegen-<lang>".
+//   - Any other "idol-codegen-*.wasm" module that exports a generator for the
+//     language. A WASM module may generate code for several languages, by
+//     exporting an "idol_codegen_generate/<lang>" function for each.
+type pluginFinder struct {
+	searchPath []string
+	runtime    wasm.Runtime
+	modules    map[string]wasm.CompiledModule
+}
+
+func newPluginFinder(searchPath []string, runtime wasm.Runtime) *pluginFinder {
+	return &pluginFinder{
+		searchPath: searchPath,
+		runtime:    runtime,
+		modules:    make(map[string]wasm.CompiledModule),
+	}
+}
+
+func (f *pluginFinder) find(ctx context.Context, language string) (codegenPlugin, error) {
+	exeName := "idol-codegen-" + language
+	for _, dir := range f.searchPath {
+		modulePath := filepath.Join(dir, exeName+".wasm")
+		if _, err := os.Stat(modulePath); err == nil {
+			return f.wasmPlugin(ctx, modulePath, language)
+		}
+		// A path without a separator would be looked up in $PATH, which
+		// happens when dir is ".".
+		exePath := filepath.Join(dir, exeName)
+		if !strings.ContainsRune(exePath, filepath.Separator) {
+			exePath = "." + string(filepath.Separator) + exePath
+		}
+		if exePath, err := exec.LookPath(exePath); err == nil {
+			return &execPlugin{exePath: exePath, language: language}, nil
+		}
+		plugin, err := f.findExport(ctx, dir, language)
+		if err != nil || plugin != nil {
+			return plugin, err
+		}
+	}
+	if exePath, err := exec.LookPath(exeName); err == nil {
+		return &execPlugin{exePath: exePath, language: language}, nil
+	}
+	return nil, fmt.Errorf(
+		"Idol codegen plugin for language %q not found in plugin path or $PATH",
+		language,
+	)
+}
+
+func (f *pluginFinder) wasmPlugin(
+	ctx context.Context,
+	modulePath string,
+	language string,
+) (codegenPlugin, error) {
+	module, err := f.compile(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := module.ExportedFunctions()["idol_codegen_generate/"+language]; !ok {
+		return nil, fmt.Errorf("%s: Plugin does not generate code for language %q", modulePath, language)
+	}
+	return &wasmPlugin{
+		runtime:    f.runtime,
+		module:     module,
+		modulePath: modulePath,
+		language:   language,
+	}, nil
+}
+
+// findExport searches the WASM modules in dir for one exporting a generator
+// for language, returning nil if there are none. Modules that fail to
+// compile are skipped, since they may be plugins for other languages.
+func (f *pluginFinder) findExport(
+	ctx context.Context,
+	dir string,
+	language string,
+) (codegenPlugin, error) {
+	candidates, err := filepath.Glob(filepath.Join(dir, "idol-codegen-*.wasm"))
+	if err != nil {
+		return nil, err
+	}
+	for _, modulePath := range candidates {
+		module, err := f.compile(ctx, modulePath)
+		if err != nil {
+			continue
+		}
+		if _, ok := module.ExportedFunctions()["idol_codegen_generate/"+language]; ok {
+			return f.wasmPlugin(ctx, modulePath, language)
+		}
+	}
+	return nil, nil
+}
+
+func (f *pluginFinder) compile(ctx context.Context, modulePath string) (wasm.CompiledModule, error) {
+	if module, ok := f.modules[modulePath]; ok {
+		return module, nil
+	}
+	moduleBin, err := os.ReadFile(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	module, err := f.runtime.CompileModule(ctx, moduleBin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", modulePath, err)
+	}
+	f.modules[modulePath] = module
+	return module, nil
+}
+
+type wasmPlugin struct {
+	runtime    wasm.Runtime
+	module     wasm.CompiledModule
+	modulePath string
+	language   string
+}
+
+func (p *wasmPlugin) path() string {
+	return p.modulePath
+}
+
+func (p *wasmPlugin) generate(
+	ctx context.Context,
+	requestBuf []uint8,
+) (codegen_idl.CodegenResponse, error) {
+	type Response = codegen_idl.CodegenResponse
+
+	// Each language gets its own instance, so that plugins can run in
+	// parallel. Instance names must be unique within the runtime.
+	moduleConfig := wasm.NewModuleConfig().WithName(p.modulePath + "#" + p.language)
+	plugin, err := p.runtime.InstantiateModule(ctx, p.module, moduleConfig)
+	if err != nil {
+		return Response{}, err
+	}
+	defer plugin.Close(ctx)
+	mem := plugin.Memory()
+
+	wasmAlloc := plugin.ExportedFunction("idol_codegen_allocate")
+	//wasmDealloc := plugin.ExportedFunction("idol_codegen_deallocate")
+	wasmGenerate := plugin.ExportedFunction("idol_codegen_generate/" + p.language)
+
+	results, err := wasmAlloc.Call(ctx, uint64(len(requestBuf)))
+	if err != nil {
+		return Response{}, err
+	}
+
+	requestPtr := results[0]
+
+	mem.Write(uint32(requestPtr), requestBuf)
+
+	results, err = wasmAlloc.Call(ctx, 4)
+	if err != nil {
+		return Response{}, err
+	}
+	responsePtrPtr := uint32(results[0])
+
+	results, err = wasmGenerate.Call(ctx, requestPtr, uint64(responsePtrPtr))
+	if err != nil {
+		return Response{}, err
+	}
+	rc := uint8(results[0])
+
+	responsePtr, _ := mem.ReadUint32Le(responsePtrPtr)
+	responseLen, ok := mem.ReadUint32Le(responsePtr)
+	if !ok {
+		return Response{}, fmt.Errorf("Failed to read response message length")
+	}
+	responseBuf, ok := mem.Read(responsePtr, responseLen)
+	if !ok {
+		return Response{}, fmt.Errorf("Failed to read response message")
+	}
+
+	// The response is copied out of the plugin's memory, which is released
+	// when the instance is closed.
+	response, err := idol.DecodeAs[Response](&idol.DecodeCtx{}, slices.Clone(responseBuf))
+	if err != nil {
+		return Response{}, err
+	}
+
+	if rc != 0 {
+		// TODO: Replace any control characters with U+FFFD.
+		// TODO: trim newlines if present
+		return Response{}, fmt.Errorf("%s", response.Error())
+	}
+	return response, nil
+}
+
+// An execPlugin is a native executable, which is run with the argument
+// "--generate=<lang>". It reads an encoded CodegenRequest from stdin and
+// writes an encoded CodegenResponse to stdout, exiting with a non-zero
+// status if code generation failed.
+type execPlugin struct {
+	exePath  string
+	language string
+}
+
+// execPluginWaitDelay is how long to wait for a native plugin's output to be
+// closed after it exits or is killed.
+const execPluginWaitDelay = time.Second
+
+func (p *execPlugin) path() string {
+	return p.exePath
+}
+
+func (p *execPlugin) generate(
+	ctx context.Context,
+	requestBuf []uint8,
+) (codegen_idl.CodegenResponse, error) {
+	type Response = codegen_idl.CodegenResponse
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.exePath, "--generate="+p.language)
+	cmd.Stdin = bytes.NewReader(requestBuf)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	// The plugin is killed when ctx is done, but processes it started may
+	// hold stdout open and keep Wait from returning.
+	cmd.WaitDelay = execPluginWaitDelay
+	runErr := cmd.Run()
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+
+	// A plugin that fails may still write a response describing the error.
+	if err := checkResponseHeader(stdout.Bytes()); err != nil {
+		if runErr != nil {
+			return Response{}, runErr
+		}
+		return Response{}, err
+	}
+	response, err := idol.DecodeAs[Response](&idol.DecodeCtx{}, stdout.Bytes())
+	if err != nil {
+		if runErr != nil {
+			return Response{}, runErr
+		}
+		return Response{}, err
+	}
+	if runErr != nil {
+		if msg := response.Error(); msg != "" {
+			return Response{}, fmt.Errorf("%s", msg)
+		}
+		return Response{}, runErr
+	}
+	return response, nil
+}
+
+// checkResponseHeader checks that a response written by a native plugin has
+// the header of a complete message. The decoder doesn't yet report every
+// malformed message as an error, so output that isn't a response at all is
+// rejected before decoding.
+func checkResponseHeader(buf []uint8) error {
+	if len(buf) == 0 {
+		return fmt.Errorf("Plugin did not write a response")
+	}
+	if len(buf) < 8 ||
+		len(buf)%8 != 0 ||
+		uint64(binary.LittleEndian.Uint32(buf[0:4])) != uint64(len(buf)) ||
+		binary.LittleEndian.Uint16(buf[4:6]) != 0 {
+		return fmt.Errorf("Plugin wrote an invalid response (%d bytes)", len(buf))
+	}
+	return nil
+}
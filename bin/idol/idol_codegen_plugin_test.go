@@ -0,0 +1,302 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	wasm "github.com/tetratelabs/wazero"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/codegen_idl"
+)
+
+func TestParseMemoryLimit(t *testing.T) {
+	tests := []struct {
+		limit string
+		pages uint32
+		err   string
+	}{
+		{"65536", 1, ""},
+		{"1", 1, ""},
+		{"65537", 2, ""},
+		{"64KiB", 1, ""},
+		{"65KiB", 2, ""},
+		{"512MiB", 8192, ""},
+		{"4GiB", 65536, ""},
+		{"4294967296", 65536, ""},
+		{"4294967297", 0, `Plugin memory limit "4294967297" exceeds the maximum of 4GiB`},
+		{"5GiB", 0, `Plugin memory limit "5GiB" exceeds the maximum of 4GiB`},
+		{"18446744073709551615GiB", 0, `Plugin memory limit "18446744073709551615GiB" exceeds the maximum of 4GiB`},
+		{"", 0, `Invalid plugin memory limit ""`},
+		{"0", 0, `Invalid plugin memory limit "0"`},
+		{"0MiB", 0, `Invalid plugin memory limit "0MiB"`},
+		{"MiB", 0, `Invalid plugin memory limit "MiB"`},
+		{"-1MiB", 0, `Invalid plugin memory limit "-1MiB"`},
+		{"1.5GiB", 0, `Invalid plugin memory limit "1.5GiB"`},
+		{"1GB", 0, `Invalid plugin memory limit "1GB"`},
+	}
+	for _, test := range tests {
+		pages, err := parseMemoryLimit(test.limit)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("parseMemoryLimit(%q): expected error %q, got %v", test.limit, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseMemoryLimit(%q): %v", test.limit, err)
+		} else if pages != test.pages {
+			t.Errorf("parseMemoryLimit(%q): expected %d pages, got %d", test.limit, test.pages, pages)
+		}
+	}
+}
+
+// wasmModule returns a minimal WASM module exporting empty functions with
+// the given names.
+func wasmModule(exports ...string) []uint8 {
+	section := func(buf []uint8, id uint8, content []uint8) []uint8 {
+		buf = append(buf, id)
+		buf = binary.AppendUvarint(buf, uint64(len(content)))
+		return append(buf, content...)
+	}
+	count := uint64(len(exports))
+	funcs := binary.AppendUvarint(nil, count)
+	exportSec := binary.AppendUvarint(nil, count)
+	code := binary.AppendUvarint(nil, count)
+	for ii, name := range exports {
+		funcs = append(funcs, 0)
+		exportSec = binary.AppendUvarint(exportSec, uint64(len(name)))
+		exportSec = append(exportSec, name...)
+		exportSec = append(exportSec, 0)
+		exportSec = binary.AppendUvarint(exportSec, uint64(ii))
+		code = append(code, 2, 0, 0x0B)
+	}
+	buf := []uint8{0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00}
+	buf = section(buf, 1, []uint8{1, 0x60, 0, 0})
+	buf = section(buf, 3, funcs)
+	buf = section(buf, 7, exportSec)
+	return section(buf, 10, code)
+}
+
+func TestPluginFinder(t *testing.T) {
+	const exeScript = "#!/bin/sh\nexit 1\n"
+	files := map[string]string{
+		// Each kind of plugin, preferred in this order.
+		"all/idol-codegen-go.wasm": string(wasmModule("idol_codegen_generate/go")),
+		"all/idol-codegen-go":      exeScript,
+		"all/idol-codegen-multi.wasm": string(wasmModule(
+			"idol_codegen_generate/go",
+			"idol_codegen_generate/rust",
+		)),
+
+		"exe/idol-codegen-go": exeScript,
+		"exe/idol-codegen-multi.wasm": string(wasmModule(
+			"idol_codegen_generate/go",
+		)),
+
+		"export/idol-codegen-bad.wasm": "not a WASM module",
+		"export/idol-codegen-c.wasm":   string(wasmModule("idol_codegen_generate/c")),
+		"export/idol-codegen-multi.wasm": string(wasmModule(
+			"idol_codegen_generate/go",
+			"idol_codegen_generate/rust",
+		)),
+
+		"wrong/idol-codegen-go.wasm": string(wasmModule("idol_codegen_generate/rust")),
+
+		"bin/idol-codegen-go":  exeScript,
+		"bin/idol-codegen-zig": exeScript,
+	}
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []uint8(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", filepath.Join(dir, "bin"))
+
+	tests := []struct {
+		searchPath []string
+		language   string
+		path       string
+		err        string
+	}{
+		{[]string{"all"}, "go", "all/idol-codegen-go.wasm", ""},
+		{[]string{"all"}, "rust", "all/idol-codegen-multi.wasm", ""},
+		{[]string{"exe"}, "go", "exe/idol-codegen-go", ""},
+		{[]string{"export"}, "c", "export/idol-codegen-c.wasm", ""},
+		{[]string{"export"}, "zig", "bin/idol-codegen-zig", ""},
+
+		// Earlier directories in the search path take precedence.
+		{[]string{"export", "all"}, "go", "export/idol-codegen-multi.wasm", ""},
+		{[]string{"exe", "all"}, "go", "exe/idol-codegen-go", ""},
+		{[]string{"all", "exe"}, "go", "all/idol-codegen-go.wasm", ""},
+
+		// $PATH is searched after the plugin path.
+		{nil, "go", "bin/idol-codegen-go", ""},
+		{[]string{"export"}, "go", "export/idol-codegen-multi.wasm", ""},
+
+		{
+			[]string{"wrong"}, "go", "",
+			`{dir}/wrong/idol-codegen-go.wasm: Plugin does not generate code for language "go"`,
+		},
+		{
+			[]string{"export"}, "java", "",
+			`Idol codegen plugin for language "java" not found in plugin path or $PATH`,
+		},
+	}
+	ctx := context.Background()
+	runtime := wasm.NewRuntimeWithConfig(ctx, wasm.NewRuntimeConfigInterpreter())
+	defer runtime.Close(ctx)
+	for _, test := range tests {
+		var searchPath []string
+		for _, path := range test.searchPath {
+			searchPath = append(searchPath, filepath.Join(dir, path))
+		}
+		plugin, err := newPluginFinder(searchPath, runtime).find(ctx, test.language)
+		if test.err != "" {
+			expect := strings.ReplaceAll(test.err, "{dir}", dir)
+			if err == nil || err.Error() != expect {
+				t.Errorf("%q %s: expected error %q, got %v", test.searchPath, test.language, expect, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q %s: %v", test.searchPath, test.language, err)
+			continue
+		}
+		if expect := filepath.Join(dir, test.path); plugin.path() != expect {
+			t.Errorf("%q %s: expected %s, got %s", test.searchPath, test.language, expect, plugin.path())
+		}
+	}
+}
+
+func TestPluginFinder_CurrentDir(t *testing.T) {
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "idol-codegen-go")
+	if err := os.WriteFile(exePath, []uint8("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", "")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	ctx := context.Background()
+	runtime := wasm.NewRuntimeWithConfig(ctx, wasm.NewRuntimeConfigInterpreter())
+	defer runtime.Close(ctx)
+	plugin, err := newPluginFinder([]string{"."}, runtime).find(ctx, "go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "./idol-codegen-go"; plugin.path() != expect {
+		t.Errorf("expected %s, got %s", expect, plugin.path())
+	}
+}
+
+func TestExecPlugin(t *testing.T) {
+	dir := t.TempDir()
+	writeResponse := func(name string, response *codegen_idl.CodegenResponse__Builder) string {
+		t.Helper()
+		buf, err := idol.Encode(&idol.EncodeCtx{}, response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	okResponse := writeResponse("ok.response", testResponse([]string{"test.txt"}, "generated\n"))
+	errResponse := &codegen_idl.CodegenResponse__Builder{}
+	errResponse.Error.Set("Unsupported declaration")
+	errResponsePath := writeResponse("err.response", errResponse)
+
+	tests := []struct {
+		name   string
+		script string
+		err    string
+	}{
+		{"success", "cat >/dev/null\ncat '" + okResponse + "'\n", ""},
+		{"error response", "cat '" + errResponsePath + "'\nexit 1\n", "Unsupported declaration"},
+		{"non-zero exit", "exit 2\n", "exit status 2"},
+		{"non-zero exit with garbage", "echo 'panic: oops'\nexit 2\n", "exit status 2"},
+		{"empty stdout", "exit 0\n", "Plugin did not write a response"},
+		{"short stdout", "printf 'abc'\n", "Plugin wrote an invalid response (3 bytes)"},
+		{"garbage stdout", "echo 'not a response'\n", "Plugin wrote an invalid response (15 bytes)"},
+		{"garbage header", "printf 'garbage!'\n", "Plugin wrote an invalid response (8 bytes)"},
+		{"language argument", "[ \"$1\" = --generate=test ] || exit 3\ncat '" + okResponse + "'\n", ""},
+	}
+	for ii, test := range tests {
+		exePath := filepath.Join(dir, fmt.Sprintf("plugin-%d", ii))
+		if err := os.WriteFile(exePath, []uint8("#!/bin/sh\n"+test.script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		plugin := &execPlugin{exePath: exePath, language: "test"}
+		response, err := plugin.generate(context.Background(), []uint8("request"))
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: expected error %q, got %v", test.name, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := response.OutputFiles().Len(); got != 1 {
+			t.Errorf("%s: expected 1 output file, got %d", test.name, got)
+		}
+	}
+}
+
+func TestExecPlugin_Timeout(t *testing.T) {
+	// The shell is killed when the context is done, but sleep keeps its
+	// stdout open.
+	exePath := filepath.Join(t.TempDir(), "plugin")
+	if err := os.WriteFile(exePath, []uint8("#!/bin/sh\nsleep 5 2>/dev/null\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	plugin := &execPlugin{exePath: exePath, language: "test"}
+	_, err := plugin.generate(ctx, []uint8("request"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 4*time.Second {
+		t.Errorf("plugin wasn't stopped at the timeout (took %v)", elapsed)
+	}
+}
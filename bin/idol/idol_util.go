@@ -16,8 +16,20 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"os"
 	"path/filepath"
 	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/pflag"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
 )
 
 func splitPath(path string) []string {
@@ -33,3 +45,314 @@ func splitPath(path string) []string {
 		path = dir[:len(dir)-1]
 	}
 }
+
+// lineColumn returns the 1-based line and column of a byte offset in src.
+func lineColumn(src []byte, offset uint32) (int, int) {
+	prefix := src[:min(int(offset), len(src))]
+	line := bytes.Count(prefix, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(prefix, '\n') + 1
+	return line, utf8.RuneCount(prefix[lineStart:]) + 1
+}
+
+// warningFlags control how compiler warnings in schema sources are
+// reported, for commands that compile sources.
+type warningFlags struct {
+	warningsAsErrors bool
+	warningAsError   []string
+	suppressWarning  []string
+}
+
+func (f *warningFlags) flags(flags *pflag.FlagSet) {
+	flags.BoolVar(&f.warningsAsErrors, "warnings-as-errors", false, "(docs TODO)")
+	flags.StringArrayVar(&f.warningAsError, "warning-as-error", nil, "(docs TODO)")
+	flags.StringArrayVar(&f.suppressWarning, "suppress-warning", nil, "(docs TODO)")
+}
+
+func (f *warningFlags) compileOptions() ([]compiler.CompileOption, error) {
+	var opts []compiler.CompileOption
+	if f.warningsAsErrors {
+		opts = append(opts, compiler.WithWarningsAsErrors())
+	}
+	for _, code := range f.warningAsError {
+		parsedCode, err := compiler.ParseWarningCode(code)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, compiler.WithWarningAsError(parsedCode))
+	}
+	for _, code := range f.suppressWarning {
+		parsedCode, err := compiler.ParseWarningCode(code)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, compiler.WithSuppressedWarning(parsedCode))
+	}
+	return opts, nil
+}
+
+// compileSource compiles a parsed schema source, using the cache (if any).
+func compileSource(
+	src []byte,
+	parsed *syntax.Schema,
+	deps []schema_idl.Schema,
+	cache *compiler.Cache,
+	opts []compiler.CompileOption,
+) (compiler.CompileResult, error) {
+	if cache != nil {
+		return cache.Compile(src, deps, opts...)
+	}
+	if len(deps) > 0 {
+		mergedDeps, err := compiler.Merge(deps)
+		if err != nil {
+			return compiler.CompileResult{}, err
+		}
+		opts = append(slices.Clip(opts), compiler.WithDependencies(mergedDeps))
+	}
+	return compiler.Compile(parsed, opts...), nil
+}
+
+// reportDiagnostics writes the warnings and errors of a compiled source to
+// stderr, at their line and column in the source. It returns false if the
+// source had errors.
+func reportDiagnostics(path string, src []byte, result compiler.CompileResult) bool {
+	for _, warn := range result.Warnings {
+		reportAt(path, src, warn.Span(), warn)
+	}
+	for _, err := range result.Errors {
+		reportAt(path, src, err.Span(), err)
+		for _, note := range err.Notes() {
+			reportAt(path, src, note.Span(), "note: "+note.Message())
+		}
+	}
+	return len(result.Errors) == 0
+}
+
+func reportAt(path string, src []byte, span syntax.Span, msg any) {
+	line, col := lineColumn(src, span.Start())
+	fmt.Fprintf(os.Stderr, "%s:%d:%d: %v\n", path, line, col, msg)
+}
+
+// reportLoadError writes an error from loading the imports of a schema to
+// stderr. Errors in an imported schema are reported at their line and
+// column in its source, followed by the location of the import.
+//
+// srcPath and src are the schema whose imports were loaded, and may be
+// empty if it's a compiled schema.
+func reportLoadError(err error, srcPath string, src []byte) {
+	switch err := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, err := range err.Unwrap() {
+			reportLoadError(err, srcPath, src)
+		}
+	case *compiler.Error:
+		if src == nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		reportDiagnostics(srcPath, src, compiler.CompileResult{
+			Errors: []*compiler.Error{err},
+		})
+	case *compiler.LoadError:
+		loadedSrc, readErr := os.ReadFile(err.Path())
+		if len(err.Errors()) == 0 || readErr != nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else {
+			reportDiagnostics(err.Path(), loadedSrc, compiler.CompileResult{
+				Errors: err.Errors(),
+			})
+		}
+		importerPath, span, ok := err.ImportedAt()
+		if !ok {
+			return
+		}
+		importerSrc := src
+		if importerPath == "" {
+			importerPath = srcPath
+		} else {
+			importerSrc, _ = os.ReadFile(importerPath)
+		}
+		if importerSrc == nil {
+			return
+		}
+		msg := fmt.Sprintf("note: namespace %q imported here", err.Namespace())
+		reportAt(importerPath, importerSrc, span, msg)
+	default:
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// loadSchema reads a compiled schema, or compiles it if the path has an
+// ".idol" extension. It returns the schema followed by its dependencies,
+// which are taken from deps or loaded from importPath, and the paths of any
+// sources read from importPath.
+//
+// Sources are compiled with opts, and their warnings and errors are reported
+// in the same way as `idol compile`. Other errors are written to stderr.
+func loadSchema(
+	path string,
+	deps []schema_idl.Schema,
+	importPath []string,
+	cache *compiler.Cache,
+	opts []compiler.CompileOption,
+) ([]schema_idl.Schema, []string, bool) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, false
+	}
+
+	var sourcePaths []string
+	newLoader := func() *compiler.Loader {
+		loader := compiler.NewLoader(importPath)
+		if cache != nil {
+			loader.SetCache(cache)
+		}
+		for _, dep := range deps {
+			loader.AddSchema(dep)
+		}
+		return loader
+	}
+
+	if filepath.Ext(path) != ".idol" {
+		schema, err := idol.DecodeAs[schema_idl.Schema](&idol.DecodeCtx{}, buf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			return nil, nil, false
+		}
+		if len(importPath) > 0 {
+			loader := newLoader()
+			for _, imp := range schema.Imports().Iter() {
+				if _, err := loader.Load(imp.Namespace()); err != nil {
+					reportLoadError(err, path, nil)
+					return nil, nil, false
+				}
+			}
+			deps = loader.Schemas()
+			sourcePaths = loader.SourcePaths()
+		}
+		return append([]schema_idl.Schema{schema}, deps...), sourcePaths, true
+	}
+
+	parsed, err := syntax.Parse(buf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return nil, nil, false
+	}
+	if len(importPath) > 0 {
+		loader := newLoader()
+		deps, err = loader.Imports(parsed)
+		if err != nil {
+			reportLoadError(err, path, buf)
+			return nil, nil, false
+		}
+		sourcePaths = loader.SourcePaths()
+	}
+	opts = slices.Clip(opts)
+	if !filepath.IsAbs(path) {
+		opts = append(opts, compiler.WithSourcePath(splitPath(path)))
+	}
+	result, err := compileSource(buf, parsed, deps, cache, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, false
+	}
+	if !reportDiagnostics(path, buf, result) {
+		return nil, nil, false
+	}
+	schema, err := result.Schema()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, false
+	}
+	return append([]schema_idl.Schema{schema}, deps...), sourcePaths, true
+}
+
+// loadMessageSchema loads the schema at schemaPath and its dependencies,
+// and splits a message name of the form "<ns>.<name>" into its namespace
+// and name. Names without a namespace are looked up in the loaded schema.
+func loadMessageSchema(
+	schemaPath string,
+	messageName string,
+	depPaths []string,
+	importPath []string,
+) (*compiler.SchemaSet, string, string, bool) {
+	var deps []schema_idl.Schema
+	for _, depPath := range depPaths {
+		depBuf, err := os.ReadFile(depPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil, "", "", false
+		}
+		dep, err := idol.DecodeAs[schema_idl.Schema](&idol.DecodeCtx{}, depBuf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", depPath, err)
+			return nil, "", "", false
+		}
+		deps = append(deps, dep)
+	}
+
+	schemas, _, ok := loadSchema(schemaPath, deps, importPath, nil, nil)
+	if !ok {
+		return nil, "", "", false
+	}
+	set, err := compiler.Merge(schemas)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, "", "", false
+	}
+
+	namespace := schemas[0].Namespace()
+	name := messageName
+	if idx := strings.LastIndexByte(messageName, '.'); idx >= 0 {
+		namespace, name = messageName[:idx], messageName[idx+1:]
+	}
+	return set, namespace, name, true
+}
+
+// manifestFile formats a list of generated files, one path per line.
+func manifestFile(outPaths []string) string {
+	var buf strings.Builder
+	for _, outPath := range outPaths {
+		buf.WriteString(outPath)
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// depfileRule formats a Make rule declaring that targets were generated
+// from the files in deps, for use in a depfile.
+func depfileRule(targets, deps []string) string {
+	var buf strings.Builder
+	for ii, target := range targets {
+		if ii > 0 {
+			buf.WriteString(" ")
+		}
+		buf.WriteString(escapeDepfilePath(target))
+	}
+	buf.WriteString(":")
+	seen := make(map[string]bool)
+	for _, dep := range deps {
+		if seen[dep] {
+			continue
+		}
+		seen[dep] = true
+		buf.WriteString(" \\\n  ")
+		buf.WriteString(escapeDepfilePath(dep))
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+func escapeDepfilePath(path string) string {
+	var buf strings.Builder
+	for _, c := range path {
+		switch c {
+		case ' ', '\\', '#', ':':
+			buf.WriteByte('\\')
+		case '$':
+			buf.WriteByte('$')
+		}
+		buf.WriteRune(c)
+	}
+	return buf.String()
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package main
+
+import (
+	"testing"
+)
+
+func TestEscapeDepfilePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"a/b.idol", "a/b.idol"},
+		{"a b.idol", `a\ b.idol`},
+		{`C:\a.idol`, `C\:\\a.idol`},
+		{"a#b.idol", `a\#b.idol`},
+		{"$HOME/a.idol", "$$HOME/a.idol"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		got := escapeDepfilePath(test.path)
+		if got != test.want {
+			t.Errorf("escapeDepfilePath(%q): expected %q, got %q", test.path, test.want, got)
+		}
+	}
+}
+
+func TestDepfileRule(t *testing.T) {
+	tests := []struct {
+		targets []string
+		deps    []string
+		want    string
+	}{
+		{
+			[]string{"out.idolbin"},
+			nil,
+			"out.idolbin:\n",
+		},
+		{
+			[]string{"out.idolbin"},
+			[]string{"a.idol", "dep.idolbin"},
+			"out.idolbin: \\\n  a.idol \\\n  dep.idolbin\n",
+		},
+		{
+			[]string{"a.go", "b.go"},
+			[]string{"a.idol"},
+			"a.go b.go: \\\n  a.idol\n",
+		},
+		{
+			// Duplicate dependencies are listed once.
+			[]string{"out.idolbin"},
+			[]string{"a.idol", "b.idol", "a.idol"},
+			"out.idolbin: \\\n  a.idol \\\n  b.idol\n",
+		},
+		{
+			[]string{"out dir/a.go"},
+			[]string{"src dir/a.idol"},
+			"out\\ dir/a.go: \\\n  src\\ dir/a.idol\n",
+		},
+	}
+	for _, test := range tests {
+		got := depfileRule(test.targets, test.deps)
+		if got != test.want {
+			t.Errorf(
+				"depfileRule(%q, %q): expected %q, got %q",
+				test.targets, test.deps, test.want, got,
+			)
+		}
+	}
+}
+
+func TestManifestFile(t *testing.T) {
+	tests := []struct {
+		outPaths []string
+		want     string
+	}{
+		{nil, ""},
+		{[]string{"a.go"}, "a.go\n"},
+		{[]string{"out/a.go", "out/b dir/b.go"}, "out/a.go\nout/b dir/b.go\n"},
+	}
+	for _, test := range tests {
+		got := manifestFile(test.outPaths)
+		if got != test.want {
+			t.Errorf("manifestFile(%q): expected %q, got %q", test.outPaths, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,476 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package breaking
+
+import (
+	"fmt"
+	"strings"
+
+	"go.idol-lang.org/idol/schema_idl"
+)
+
+type Severity uint8
+
+const (
+	// The change breaks wire compatibility between the two schemas.
+	SeverityError Severity = iota + 1
+
+	// The change is wire-compatible, but breaks code generated from (or
+	// written against) the previous schema.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity(%d)", uint8(s))
+}
+
+type Finding struct {
+	rule     string
+	severity Severity
+	message  string
+}
+
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s[%s]: %s", f.severity, f.rule, f.message)
+}
+
+func (f *Finding) Rule() string {
+	return f.rule
+}
+
+func (f *Finding) Severity() Severity {
+	return f.severity
+}
+
+func (f *Finding) Message() string {
+	return f.message
+}
+
+// Check compares two versions of a schema, and returns the changes in next
+// that are incompatible with prev.
+func Check(prev, next schema_idl.Schema) []*Finding {
+	c := &checker{}
+	if prev.Namespace() != next.Namespace() {
+		c.error(
+			"NAMESPACE_CHANGED",
+			"Namespace changed from %q to %q",
+			prev.Namespace(), next.Namespace(),
+		)
+	}
+	c.checkEnums(prev, next)
+	c.checkStructs(prev, next)
+	c.checkMessages(prev, next)
+	c.checkUnions(prev, next)
+	c.checkProtocols(prev, next)
+	return c.findings
+}
+
+type checker struct {
+	findings []*Finding
+}
+
+func (c *checker) error(rule, format string, args ...any) {
+	c.findings = append(c.findings, &Finding{
+		rule:     rule,
+		severity: SeverityError,
+		message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) warn(rule, format string, args ...any) {
+	c.findings = append(c.findings, &Finding{
+		rule:     rule,
+		severity: SeverityWarning,
+		message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func fmtType(type_ schema_idl.Type, typeName string, arrayLen uint32) string {
+	var name string
+	if typeName == "" {
+		name = strings.ToLower(type_.String())
+	} else if namespace, local, ok := strings.Cut(typeName, "\x1F"); ok {
+		name = fmt.Sprintf("%q.%s", namespace, local)
+	} else {
+		name = typeName
+	}
+	switch arrayLen {
+	case 0:
+		return name
+	case 0xFFFFFFFF:
+		return name + "[]"
+	}
+	return fmt.Sprintf("%s[%d]", name, arrayLen)
+}
+
+func byName[T interface{ Name() string }](items []T) map[string]T {
+	out := make(map[string]T, len(items))
+	for _, item := range items {
+		out[item.Name()] = item
+	}
+	return out
+}
+
+func (c *checker) checkEnums(prev, next schema_idl.Schema) {
+	nextEnums := byName(next.Enums().Collect())
+	for _, prevEnum := range prev.Enums().Iter() {
+		name := prevEnum.Name()
+		nextEnum, ok := nextEnums[name]
+		if !ok {
+			c.error("ENUM_REMOVED", "Enum '%s' was removed", name)
+			continue
+		}
+		if prevEnum.Type() != nextEnum.Type() {
+			c.error(
+				"ENUM_TYPE_CHANGED",
+				"Enum '%s' type changed from '%s' to '%s'", name,
+				fmtType(prevEnum.Type(), "", 0),
+				fmtType(nextEnum.Type(), "", 0),
+			)
+		}
+		nextItems := byName(nextEnum.Items().Collect())
+		for _, prevItem := range prevEnum.Items().Iter() {
+			itemName := prevItem.Name()
+			nextItem, ok := nextItems[itemName]
+			if !ok {
+				c.error(
+					"ENUM_ITEM_REMOVED",
+					"Enum item '%s.%s' was removed", name, itemName,
+				)
+				continue
+			}
+			if prevItem.Value() != nextItem.Value() {
+				c.error(
+					"ENUM_ITEM_RENUMBERED",
+					"Enum item '%s.%s' value changed from %d to %d",
+					name, itemName, prevItem.Value(), nextItem.Value(),
+				)
+			}
+		}
+
+		nextItemsByValue := make(map[uint64]schema_idl.EnumItem)
+		for _, item := range nextEnum.Items().Iter() {
+			nextItemsByValue[item.Value()] = item
+		}
+		for _, value := range prevEnum.ReservedValues().Iter() {
+			if item, ok := nextItemsByValue[value]; ok {
+				c.error(
+					"ENUM_RESERVED_VALUE_REUSED",
+					"Enum item '%s.%s' uses value %d, which was reserved",
+					name, item.Name(), value,
+				)
+			}
+		}
+	}
+}
+
+func (c *checker) checkStructs(prev, next schema_idl.Schema) {
+	nextStructs := byName(next.Structs().Collect())
+	for _, prevStruct := range prev.Structs().Iter() {
+		name := prevStruct.Name()
+		nextStruct, ok := nextStructs[name]
+		if !ok {
+			c.error("STRUCT_REMOVED", "Struct '%s' was removed", name)
+			continue
+		}
+
+		// Struct fields are encoded by position, so any change to the
+		// number, order, or types of fields changes the struct layout.
+		prevFields := prevStruct.Fields().Collect()
+		nextFields := nextStruct.Fields().Collect()
+		if len(prevFields) != len(nextFields) {
+			c.error(
+				"STRUCT_LAYOUT_CHANGED",
+				"Struct '%s' field count changed from %d to %d",
+				name, len(prevFields), len(nextFields),
+			)
+			continue
+		}
+		for ii, prevField := range prevFields {
+			nextField := nextFields[ii]
+			prevType := fmtType(prevField.Type(), prevField.TypeName(), prevField.ArrayLen())
+			nextType := fmtType(nextField.Type(), nextField.TypeName(), nextField.ArrayLen())
+			if prevType != nextType {
+				c.error(
+					"STRUCT_LAYOUT_CHANGED",
+					"Struct '%s' field %d ('%s') type changed from '%s' to '%s'",
+					name, ii, prevField.Name(), prevType, nextType,
+				)
+			} else if prevField.Name() != nextField.Name() {
+				c.warn(
+					"STRUCT_FIELD_RENAMED",
+					"Struct '%s' field '%s' was renamed to '%s'",
+					name, prevField.Name(), nextField.Name(),
+				)
+			}
+		}
+	}
+}
+
+func (c *checker) checkMessages(prev, next schema_idl.Schema) {
+	nextMessages := byName(next.Messages().Collect())
+	for _, prevMessage := range prev.Messages().Iter() {
+		name := prevMessage.Name()
+		nextMessage, ok := nextMessages[name]
+		if !ok {
+			c.error("MESSAGE_REMOVED", "Message '%s' was removed", name)
+			continue
+		}
+
+		nextFields := byName(nextMessage.Fields().Collect())
+		nextFieldsByTag := make(map[uint16]schema_idl.MessageField)
+		for _, field := range nextMessage.Fields().Iter() {
+			nextFieldsByTag[field.Tag()] = field
+		}
+
+		for _, prevField := range prevMessage.Fields().Iter() {
+			fieldName := prevField.Name()
+			nextField, ok := nextFieldsByTag[prevField.Tag()]
+			if !ok {
+				if moved, ok := nextFields[fieldName]; ok {
+					c.error(
+						"MESSAGE_FIELD_RETAGGED",
+						"Message field '%s.%s' tag changed from @%d to @%d",
+						name, fieldName, prevField.Tag(), moved.Tag(),
+					)
+				} else {
+					c.error(
+						"MESSAGE_FIELD_REMOVED",
+						"Message field '%s.%s' (@%d) was removed",
+						name, fieldName, prevField.Tag(),
+					)
+				}
+				continue
+			}
+			if nextField.Name() != fieldName {
+				c.warn(
+					"MESSAGE_FIELD_RENAMED",
+					"Message field '%s.%s' (@%d) was renamed to '%s'",
+					name, fieldName, prevField.Tag(), nextField.Name(),
+				)
+			}
+			if prevField.Type() != nextField.Type() || prevField.TypeName() != nextField.TypeName() {
+				c.error(
+					"MESSAGE_FIELD_TYPE_CHANGED",
+					"Message field '%s.%s' type changed from '%s' to '%s'",
+					name, fieldName,
+					fmtType(prevField.Type(), prevField.TypeName(), 0),
+					fmtType(nextField.Type(), nextField.TypeName(), 0),
+				)
+			} else if prevField.ArrayLen() != nextField.ArrayLen() {
+				c.error(
+					"MESSAGE_FIELD_ARRAY_LEN_CHANGED",
+					"Message field '%s.%s' type changed from '%s' to '%s'",
+					name, fieldName,
+					fmtType(prevField.Type(), prevField.TypeName(), prevField.ArrayLen()),
+					fmtType(nextField.Type(), nextField.TypeName(), nextField.ArrayLen()),
+				)
+			}
+			if prevField.Options().Optional() && !nextField.Options().Optional() {
+				c.error(
+					"MESSAGE_FIELD_OPTIONAL_TO_REQUIRED",
+					"Message field '%s.%s' changed from optional to required",
+					name, fieldName,
+				)
+			}
+		}
+
+		for _, tag := range prevMessage.ReservedTags().Iter() {
+			if field, ok := nextFieldsByTag[tag]; ok {
+				c.error(
+					"MESSAGE_RESERVED_TAG_REUSED",
+					"Message field '%s.%s' uses tag @%d, which was reserved",
+					name, field.Name(), tag,
+				)
+			}
+		}
+	}
+}
+
+func (c *checker) checkUnions(prev, next schema_idl.Schema) {
+	nextUnions := byName(next.Unions().Collect())
+	for _, prevUnion := range prev.Unions().Iter() {
+		name := prevUnion.Name()
+		nextUnion, ok := nextUnions[name]
+		if !ok {
+			c.error("UNION_REMOVED", "Union '%s' was removed", name)
+			continue
+		}
+
+		nextFields := byName(nextUnion.Fields().Collect())
+		nextFieldsByTag := make(map[uint16]schema_idl.UnionField)
+		for _, field := range nextUnion.Fields().Iter() {
+			nextFieldsByTag[field.Tag()] = field
+		}
+
+		for _, prevField := range prevUnion.Fields().Iter() {
+			fieldName := prevField.Name()
+			nextField, ok := nextFieldsByTag[prevField.Tag()]
+			if !ok {
+				if moved, ok := nextFields[fieldName]; ok {
+					c.error(
+						"UNION_VARIANT_RETAGGED",
+						"Union variant '%s.%s' tag changed from @%d to @%d",
+						name, fieldName, prevField.Tag(), moved.Tag(),
+					)
+				} else {
+					c.error(
+						"UNION_VARIANT_REMOVED",
+						"Union variant '%s.%s' (@%d) was removed",
+						name, fieldName, prevField.Tag(),
+					)
+				}
+				continue
+			}
+			if nextField.Name() != fieldName {
+				c.warn(
+					"UNION_VARIANT_RENAMED",
+					"Union variant '%s.%s' (@%d) was renamed to '%s'",
+					name, fieldName, prevField.Tag(), nextField.Name(),
+				)
+			}
+			prevType := fmtType(prevField.Type(), prevField.TypeName(), prevField.ArrayLen())
+			nextType := fmtType(nextField.Type(), nextField.TypeName(), nextField.ArrayLen())
+			if prevType != nextType {
+				c.error(
+					"UNION_VARIANT_TYPE_CHANGED",
+					"Union variant '%s.%s' type changed from '%s' to '%s'",
+					name, fieldName, prevType, nextType,
+				)
+			}
+		}
+
+		for _, tag := range prevUnion.ReservedTags().Iter() {
+			if field, ok := nextFieldsByTag[tag]; ok {
+				c.error(
+					"UNION_RESERVED_TAG_REUSED",
+					"Union variant '%s.%s' uses tag @%d, which was reserved",
+					name, field.Name(), tag,
+				)
+			}
+		}
+	}
+}
+
+func fmtTag(tag uint64, hasTag bool) string {
+	if !hasTag {
+		return "(none)"
+	}
+	return fmt.Sprintf("@%d", tag)
+}
+
+func fmtStream(type_ schema_idl.Type, typeName string, isStream bool) string {
+	if type_ == schema_idl.Type_UNKNOWN && typeName == "" {
+		return "(none)"
+	}
+	if isStream {
+		return "stream " + fmtType(type_, typeName, 0)
+	}
+	return fmtType(type_, typeName, 0)
+}
+
+func (c *checker) checkProtocols(prev, next schema_idl.Schema) {
+	nextProtocols := byName(next.Protocols().Collect())
+	for _, prevProtocol := range prev.Protocols().Iter() {
+		name := prevProtocol.Name()
+		nextProtocol, ok := nextProtocols[name]
+		if !ok {
+			c.error("PROTOCOL_REMOVED", "Protocol '%s' was removed", name)
+			continue
+		}
+
+		nextRpcs := byName(nextProtocol.Rpcs().Collect())
+		for _, prevRpc := range prevProtocol.Rpcs().Iter() {
+			rpcName := prevRpc.Name()
+			nextRpc, ok := nextRpcs[rpcName]
+			if !ok {
+				c.error(
+					"PROTOCOL_RPC_REMOVED",
+					"Protocol RPC '%s.%s' was removed", name, rpcName,
+				)
+				continue
+			}
+			prevTag, prevHasTag := prevRpc.Tag()
+			nextTag, nextHasTag := nextRpc.Tag()
+			if prevTag != nextTag || prevHasTag != nextHasTag {
+				c.error(
+					"PROTOCOL_RPC_RETAGGED",
+					"Protocol RPC '%s.%s' tag changed from %s to %s",
+					name, rpcName,
+					fmtTag(prevTag, prevHasTag),
+					fmtTag(nextTag, nextHasTag),
+				)
+			}
+			prevReq := fmtStream(prevRpc.RequestType(), prevRpc.RequestTypeName(), prevRpc.RequestIsStream())
+			nextReq := fmtStream(nextRpc.RequestType(), nextRpc.RequestTypeName(), nextRpc.RequestIsStream())
+			if prevReq != nextReq {
+				c.error(
+					"PROTOCOL_RPC_TYPE_CHANGED",
+					"Protocol RPC '%s.%s' request type changed from '%s' to '%s'",
+					name, rpcName, prevReq, nextReq,
+				)
+			}
+			prevResp := fmtStream(prevRpc.ResponseType(), prevRpc.ResponseTypeName(), prevRpc.ResponseIsStream())
+			nextResp := fmtStream(nextRpc.ResponseType(), nextRpc.ResponseTypeName(), nextRpc.ResponseIsStream())
+			if prevResp != nextResp {
+				c.error(
+					"PROTOCOL_RPC_TYPE_CHANGED",
+					"Protocol RPC '%s.%s' response type changed from '%s' to '%s'",
+					name, rpcName, prevResp, nextResp,
+				)
+			}
+		}
+
+		nextEvents := byName(nextProtocol.Events().Collect())
+		for _, prevEvent := range prevProtocol.Events().Iter() {
+			eventName := prevEvent.Name()
+			nextEvent, ok := nextEvents[eventName]
+			if !ok {
+				c.error(
+					"PROTOCOL_EVENT_REMOVED",
+					"Protocol event '%s.%s' was removed", name, eventName,
+				)
+				continue
+			}
+			prevTag, prevHasTag := prevEvent.Tag()
+			nextTag, nextHasTag := nextEvent.Tag()
+			if prevTag != nextTag || prevHasTag != nextHasTag {
+				c.error(
+					"PROTOCOL_EVENT_RETAGGED",
+					"Protocol event '%s.%s' tag changed from %s to %s",
+					name, eventName,
+					fmtTag(prevTag, prevHasTag),
+					fmtTag(nextTag, nextHasTag),
+				)
+			}
+			prevType := fmtType(prevEvent.PayloadType(), prevEvent.PayloadTypeName(), 0)
+			nextType := fmtType(nextEvent.PayloadType(), nextEvent.PayloadTypeName(), 0)
+			if prevType != nextType {
+				c.error(
+					"PROTOCOL_EVENT_TYPE_CHANGED",
+					"Protocol event '%s.%s' payload type changed from '%s' to '%s'",
+					name, eventName, prevType, nextType,
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package breaking_test
+
+import (
+	"testing"
+
+	"go.idol-lang.org/idol/breaking"
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+func compileSchema(t *testing.T, src string) schema_idl.Schema {
+	t.Helper()
+	parsed, err := syntax.Parse([]byte(src))
+	testutil.AssertNoError(t, err)
+	result := compiler.Compile(parsed)
+	for _, err := range result.Errors {
+		t.Fatal(err)
+	}
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+	return schema
+}
+
+func checkRules(t *testing.T, prevSrc, nextSrc string) []string {
+	t.Helper()
+	prev := compileSchema(t, prevSrc)
+	next := compileSchema(t, nextSrc)
+	var rules []string
+	for _, finding := range breaking.Check(prev, next) {
+		rules = append(rules, finding.Severity().String()+" "+finding.Rule())
+	}
+	return rules
+}
+
+func TestCheck_Unchanged(t *testing.T) {
+	src := `namespace "test"
+enum E : u8 {
+	A = 1
+}
+message M {
+	a @1 : u32
+}
+`
+	testutil.ExpectSliceEq(t, nil, checkRules(t, src, src))
+}
+
+func TestCheck_MessageFields(t *testing.T) {
+	prev := `namespace "test"
+message M {
+	a @1 : u32
+	b @2 : u32
+	c @3 : u32
+	d @4 : text
+	e @5 : u8[]
+	@{optional}
+	f @6 : u8
+}
+`
+	next := `namespace "test"
+message M {
+	a @1 : u32
+	b @7 : u32
+	c_renamed @3 : u32
+	d @4 : u64
+	e @5 : u8[4]
+	f @6 : u8
+}
+`
+	testutil.ExpectSliceEq(t, []string{
+		"error MESSAGE_FIELD_RETAGGED",
+		"warning MESSAGE_FIELD_RENAMED",
+		"error MESSAGE_FIELD_TYPE_CHANGED",
+		"error MESSAGE_FIELD_ARRAY_LEN_CHANGED",
+		"error MESSAGE_FIELD_OPTIONAL_TO_REQUIRED",
+	}, checkRules(t, prev, next))
+}
+
+func TestCheck_EnumsAndStructs(t *testing.T) {
+	prev := `namespace "test"
+enum E : u8 {
+	A = 1
+	B = 2
+	C = 3
+}
+struct S {
+	x : u32
+	y : u32
+}
+struct T {
+	x : u32
+}
+`
+	next := `namespace "test"
+enum E : u8 {
+	A = 1
+	B = 4
+}
+struct S {
+	x : u32
+	y : u64
+}
+struct T {
+	z : u32
+}
+`
+	testutil.ExpectSliceEq(t, []string{
+		"error ENUM_ITEM_RENUMBERED",
+		"error ENUM_ITEM_REMOVED",
+		"error STRUCT_LAYOUT_CHANGED",
+		"warning STRUCT_FIELD_RENAMED",
+	}, checkRules(t, prev, next))
+}
+
+func TestCheck_ReservedReused(t *testing.T) {
+	prev := `namespace "test"
+enum E : u8 {
+	reserved 2
+	A = 1
+}
+message M {
+	reserved @2
+	a @1 : u32
+}
+`
+	next := `namespace "test"
+enum E : u8 {
+	A = 1
+	B = 2
+}
+message M {
+	a @1 : u32
+	b @2 : u32
+}
+`
+	testutil.ExpectSliceEq(t, []string{
+		"error ENUM_RESERVED_VALUE_REUSED",
+		"error MESSAGE_RESERVED_TAG_REUSED",
+	}, checkRules(t, prev, next))
+}
+
+func TestCheck_Unions(t *testing.T) {
+	prev := `namespace "test"
+union U {
+	reserved @9
+	a @1 : u32
+	b @2 : u32
+	c @3 : u32
+	d @4 : text
+	e @5 : u8
+}
+union V {
+	a @1 : u8
+}
+`
+	next := `namespace "test"
+union U {
+	a @1 : u32
+	b @6 : u32
+	c_renamed @3 : u32
+	d @4 : u64
+	f @9 : u8
+}
+`
+	testutil.ExpectSliceEq(t, []string{
+		"error UNION_VARIANT_RETAGGED",
+		"warning UNION_VARIANT_RENAMED",
+		"error UNION_VARIANT_TYPE_CHANGED",
+		"error UNION_VARIANT_REMOVED",
+		"error UNION_RESERVED_TAG_REUSED",
+		"error UNION_REMOVED",
+	}, checkRules(t, prev, next))
+}
+
+func TestCheck_Protocols(t *testing.T) {
+	prev := `namespace "test"
+message A {}
+message B {}
+protocol P {
+	rpc Get @1(A) : B
+	rpc Put @2(A) : ()
+	rpc Watch @3(A) : (B stream)
+	rpc Upload @4(A stream) : B
+	rpc Delete @5(A) : B
+	event Changed @10: A
+	event Moved @11: A
+	event Deleted @12: A
+}
+protocol Q {
+	rpc Get (A) : B
+}
+`
+	next := `namespace "test"
+message A {}
+message B {}
+protocol P {
+	rpc Get @6(A) : B
+	rpc Put @2(A) : B
+	rpc Watch @3(A) : B
+	rpc Upload @4(A) : B
+	event Changed @13: A
+	event Moved @11: B
+}
+`
+	testutil.ExpectSliceEq(t, []string{
+		"error PROTOCOL_RPC_RETAGGED",
+		"error PROTOCOL_RPC_TYPE_CHANGED",
+		"error PROTOCOL_RPC_TYPE_CHANGED",
+		"error PROTOCOL_RPC_TYPE_CHANGED",
+		"error PROTOCOL_RPC_REMOVED",
+		"error PROTOCOL_EVENT_RETAGGED",
+		"error PROTOCOL_EVENT_TYPE_CHANGED",
+		"error PROTOCOL_EVENT_REMOVED",
+		"error PROTOCOL_REMOVED",
+	}, checkRules(t, prev, next))
+}
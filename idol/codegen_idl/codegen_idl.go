@@ -49,6 +49,10 @@ func (CodegenRequest) Idol__MessageType() idol.MessageType[CodegenRequest] {
 	return _CodegenRequest__MessageType{}
 }
 
+func (CodegenRequest) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xF0, 0xD8, 0x53, 0x54, 0xAA, 0xAD, 0x2D, 0xD8, 0x8D, 0x3B, 0x25, 0x25, 0xB1, 0xA8, 0xD7, 0x86, 0xAA, 0x39, 0x92, 0x3F, 0xE0, 0x00, 0xF3, 0x53, 0x2D, 0xC5, 0xC4, 0xC5, 0x22, 0x86, 0x1A, 0x26}
+}
+
 func (m _CodegenRequest__Message) Self() CodegenRequest { return m.self }
 
 func (m _CodegenRequest__Message) Type() idol.MessageType[CodegenRequest] {
@@ -233,6 +237,10 @@ func (CodegenResponse) Idol__MessageType() idol.MessageType[CodegenResponse] {
 	return _CodegenResponse__MessageType{}
 }
 
+func (CodegenResponse) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xB9, 0x29, 0xEF, 0x91, 0xE5, 0xEF, 0xDD, 0x77, 0x41, 0xD8, 0xEA, 0xC9, 0x51, 0xA3, 0xB7, 0x94, 0x82, 0x57, 0x1D, 0x9B, 0x71, 0x40, 0xBB, 0xB9, 0x8D, 0x36, 0x39, 0xFE, 0x1F, 0x4F, 0xAE, 0xC6}
+}
+
 func (m _CodegenResponse__Message) Self() CodegenResponse { return m.self }
 
 func (m _CodegenResponse__Message) Type() idol.MessageType[CodegenResponse] {
@@ -386,6 +394,10 @@ func (OutputFile) Idol__MessageType() idol.MessageType[OutputFile] {
 	return _OutputFile__MessageType{}
 }
 
+func (OutputFile) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xF6, 0x00, 0x84, 0x73, 0x4C, 0xB1, 0x98, 0x20, 0x95, 0x5A, 0xC5, 0xB7, 0xD3, 0x81, 0x42, 0x3D, 0xCA, 0xA8, 0x8C, 0xF5, 0x50, 0x12, 0x46, 0x67, 0x74, 0xF7, 0x4F, 0x3A, 0x8F, 0x8A, 0xEB, 0xA5}
+}
+
 func (m _OutputFile__Message) Self() OutputFile { return m.self }
 
 func (m _OutputFile__Message) Type() idol.MessageType[OutputFile] {
@@ -61,6 +61,7 @@ var builtinTypes = map[string]schema_idl.Type{
 	"text":   schema_idl.Type_TEXT,
 	"asciz":  schema_idl.Type_ASCIZ,
 	"handle": schema_idl.Type_HANDLE,
+	"bytes":  schema_idl.Type_U8,
 }
 
 type CompileOption interface {
@@ -72,8 +73,14 @@ type compileOption func(*CompileOptions)
 func (f compileOption) apply(opts *CompileOptions) { f(opts) }
 
 type CompileOptions struct {
-	deps       *SchemaSet
-	sourcePath []string
+	deps             *SchemaSet
+	sourcePath       []string
+	stripDocComments bool
+	sourceInfo       bool
+
+	warningsAsErrors   bool
+	warningErrorCodes  []uint32
+	suppressedWarnings []uint32
 }
 
 func WithDependencies(dependencies *SchemaSet) CompileOption {
@@ -88,6 +95,39 @@ func WithSourcePath(sourcePath []string) CompileOption {
 	})
 }
 
+func WithoutDocComments() CompileOption {
+	return compileOption(func(opts *CompileOptions) {
+		opts.stripDocComments = true
+	})
+}
+
+func WithSourceInfo(sourceInfo bool) CompileOption {
+	return compileOption(func(opts *CompileOptions) {
+		opts.sourceInfo = sourceInfo
+	})
+}
+
+// WithWarningsAsErrors reports all warnings as errors.
+func WithWarningsAsErrors() CompileOption {
+	return compileOption(func(opts *CompileOptions) {
+		opts.warningsAsErrors = true
+	})
+}
+
+// WithWarningAsError reports warnings with the given code as errors.
+func WithWarningAsError(code uint32) CompileOption {
+	return compileOption(func(opts *CompileOptions) {
+		opts.warningErrorCodes = append(opts.warningErrorCodes, code)
+	})
+}
+
+// WithSuppressedWarning discards warnings with the given code.
+func WithSuppressedWarning(code uint32) CompileOption {
+	return compileOption(func(opts *CompileOptions) {
+		opts.suppressedWarnings = append(opts.suppressedWarnings, code)
+	})
+}
+
 type CompileResult struct {
 	schema *schema_idl.Schema__Builder
 
@@ -138,6 +178,7 @@ func (opts *CompileOptions) Compile(parsedSchema *syntax.Schema) CompileResult {
 		c.schema.SourcePath.Set(opts.sourcePath)
 	}
 	c.compileSchema()
+	c.applyWarningOptions()
 	if len(c.errors) > 0 {
 		return CompileResult{
 			Errors:   c.errors,
@@ -210,6 +251,15 @@ type compiler struct {
 	// Set by registerDecls()
 	decls       []*declInfo
 	declsByName map[string]*declInfo
+
+	// Set by compileStruct()
+	structEdges map[string][]*structEdge
+
+	// Used by ensureCompiled()
+	compileStack []*declInfo
+
+	// Set by `suppress_warnings` options.
+	suppressions []*warningSuppression
 }
 
 type importCtx struct {
@@ -229,6 +279,9 @@ type typeInfo struct {
 	typeName string
 	imported any
 	decl     *declInfo
+
+	// The builtin `bytes`, an alias of `u8[]`.
+	isBytes bool
 }
 
 type declInfo struct {
@@ -239,10 +292,23 @@ type declInfo struct {
 	constType *typeInfo
 
 	// Set by compileConst()
-	constValue []byte
+	constValue   []byte
+	constBuilder *schema_idl.Const__Builder
 
 	// Set by compileEnum()
-	enumValues map[string]uint64
+	enumValues  map[string]uint64
+	enumBuilder *schema_idl.Enum__Builder
+
+	// Set by ensureCompiled()
+	compiling bool
+	compiled  bool
+}
+
+type structEdge struct {
+	structName string
+	fieldName  string
+	typeName   string
+	node       *syntax.StructField
 }
 
 type constInfo struct {
@@ -281,11 +347,26 @@ const (
 	_OPTS_PROTOCOL_EVENT
 )
 
+func (s builtinOptionsSchema) allowsDeprecated() bool {
+	switch s {
+	case _OPTS_ENUM_ITEM,
+		_OPTS_MESSAGE,
+		_OPTS_MESSAGE_FIELD,
+		_OPTS_PROTOCOL_RPC,
+		_OPTS_PROTOCOL_EVENT:
+		return true
+	}
+	return false
+}
+
 func (t *typeInfo) isImported() bool {
 	return strings.Contains(t.typeName, "\x1F")
 }
 
 func (c *compiler) err(err error) {
+	if err == errPoisoned {
+		return
+	}
 	c.errors = append(c.errors, err.(*Error))
 }
 
@@ -293,6 +374,35 @@ func (c *compiler) warn(warning *Warning) {
 	c.warnings = append(c.warnings, warning)
 }
 
+func (c *compiler) docComment(
+	node interface{ DocComments() []*syntax.Comment },
+) (string, bool) {
+	docs := node.DocComments()
+	if c.opts.stripDocComments || len(docs) == 0 {
+		return "", false
+	}
+	lines := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		line := strings.TrimPrefix(doc.Text(), "##")
+		line = strings.TrimPrefix(line, " ")
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+func (c *compiler) sourceSpan(
+	node syntax.Node,
+) (*schema_idl.SourceSpan__Builder, bool) {
+	if !c.opts.sourceInfo {
+		return nil, false
+	}
+	span := node.Span()
+	return build(func(b *schema_idl.SourceSpan__Builder) {
+		b.Start.Set(span.Start())
+		b.Len.Set(span.Len())
+	}), true
+}
+
 func (c *compiler) compileSchema() {
 	namespace, err := checkNamespace(c.nodes.namespace.Namespace())
 	if err != nil {
@@ -638,6 +748,13 @@ func (c *compiler) compileSchemaOptions() *schema_idl.SchemaOptions__Builder {
 			}
 		}
 	}
+	if len(ctx.suppressedWarnings) > 0 {
+		c.suppressions = append(c.suppressions, &warningSuppression{
+			start: 0,
+			end:   math.MaxUint32,
+			codes: ctx.suppressedWarnings,
+		})
+	}
 	if len(ctx.uninterpreted.builders) > 0 {
 		if b == nil {
 			b = &schema_idl.SchemaOptions__Builder{}
@@ -650,8 +767,14 @@ func (c *compiler) compileSchemaOptions() *schema_idl.SchemaOptions__Builder {
 }
 
 type optionsCtx struct {
-	uninterpreted *uninterpretedOptions
-	seen          map[string]map[string][]uint8
+	uninterpreted      *uninterpretedOptions
+	seen               map[string]map[string][]uint8
+	suppressedWarnings []uint32
+
+	// The decorated message field, for checking its default value.
+	field         *syntax.MessageField
+	fieldType     *typeInfo
+	fieldArrayLen uint32
 }
 
 func newOptionsCtx() *optionsCtx {
@@ -764,7 +887,7 @@ func (c *compiler) compileOption(
 	}
 
 	if optType != nil && schema.builtin != _OPTS_NOT_BUILTIN {
-		return c.compileBuiltinOption(schema.builtin, name, option)
+		return c.compileBuiltinOption(ctx, name, option)
 	}
 
 	optsBuilder := ctx.getUninterpretedOptionsBuilder(schema.typeName)
@@ -813,9 +936,21 @@ func (c *compiler) resolveOptionType(
 				type_: schema_idl.Type_BOOL,
 			}
 		}
-		c.warn(warnOptionNameNotFound(name, nameNode))
-		return nil
+		if name == "default" {
+			return &typeInfo{}
+		}
+		fallthrough
 	default:
+		if name == "deprecated" && schema.builtin.allowsDeprecated() {
+			return &typeInfo{
+				type_: schema_idl.Type_BOOL,
+			}
+		}
+		if name == "suppress_warnings" {
+			return &typeInfo{
+				type_: schema_idl.Type_TEXT,
+			}
+		}
 		c.warn(warnOptionNameNotFound(name, nameNode))
 		return nil
 	}
@@ -891,7 +1026,7 @@ func (c *compiler) resolveOptionType(
 }
 
 func (c *compiler) compileBuiltinOption(
-	schema builtinOptionsSchema,
+	ctx *optionsCtx,
 	name string,
 	option interface {
 		syntax.Node
@@ -900,50 +1035,139 @@ func (c *compiler) compileBuiltinOption(
 	},
 ) optionsUpdater {
 	valueNode := option.Value()
-	switch schema {
-	case _OPTS_MESSAGE_FIELD:
-		if name == "optional" {
-			var value bool
-			if valueNode == nil {
-				value = true
-			} else {
-				enumRef, ok := valueNode.(*syntax.EnumRef)
-				if !ok {
-					optType := &typeInfo{
-						type_: schema_idl.Type_BOOL,
-					}
-					c.err(errValueTypeMismatch(option, optType, valueNode))
-					return nil
-				}
-				name := enumRef.Name().Get()
-				if name == "true" {
-					value = true
-				}
-				if name != "false" {
-					c.err(errInvalidBoolValue(enumRef))
-				}
-			}
-			if !value {
-				return nil
+	if name == "suppress_warnings" {
+		textLit, ok := valueNode.(*syntax.TextLit)
+		if !ok {
+			c.err(errSuppressWarningsValue(option))
+			return nil
+		}
+		text, _ := textLit.GetText()
+		for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == ' '
+		}) {
+			code, err := ParseWarningCode(field)
+			if err != nil {
+				c.err(errInvalidWarningCode(field, textLit))
+				continue
 			}
-			return optionsUpdater(func(optsBuilder any) {
-				b := optsBuilder.(*schema_idl.MessageFieldOptions__Builder)
-				b.Optional.Set(true)
-			})
+			ctx.suppressedWarnings = append(ctx.suppressedWarnings, code)
 		}
+		return nil
+	}
+	if name == "default" {
+		return c.compileDefaultOption(ctx, option)
+	}
+	value, ok := c.compileBuiltinBoolOption(option)
+	if !ok || !value {
+		return nil
+	}
+	switch name {
+	case "optional":
+		return optionsUpdater(func(optsBuilder any) {
+			b := optsBuilder.(*schema_idl.MessageFieldOptions__Builder)
+			b.Optional.Set(true)
+		})
+	case "deprecated":
+		return optionsUpdater(func(optsBuilder any) {
+			switch b := optsBuilder.(type) {
+			case *schema_idl.MessageOptions__Builder:
+				b.Deprecated.Set(true)
+			case *schema_idl.MessageFieldOptions__Builder:
+				b.Deprecated.Set(true)
+			case *schema_idl.EnumItemOptions__Builder:
+				b.Deprecated.Set(true)
+			case *schema_idl.ProtocolRpcOptions__Builder:
+				b.Deprecated.Set(true)
+			case *schema_idl.ProtocolEventOptions__Builder:
+				b.Deprecated.Set(true)
+			default:
+				panic("unreachable")
+			}
+		})
 	}
 	panic("unreachable")
 }
 
+func (c *compiler) compileDefaultOption(
+	ctx *optionsCtx,
+	option interface {
+		syntax.Node
+		Value() syntax.Node
+	},
+) optionsUpdater {
+	fieldType := ctx.fieldType
+	if fieldType == nil {
+		return nil
+	}
+	if ctx.fieldArrayLen > 0 || !fieldType.canCompileValue() {
+		c.err(errDefaultValueNotSupported(option, ctx.field))
+		return nil
+	}
+	valueNode := option.Value()
+	if valueNode == nil {
+		c.err(errOptionValueRequired("default", option))
+		return nil
+	}
+	value, err := c.compileValue(option, fieldType, valueNode)
+	if err != nil {
+		c.err(err)
+		return nil
+	}
+	return optionsUpdater(func(optsBuilder any) {
+		b := optsBuilder.(*schema_idl.MessageFieldOptions__Builder)
+		b.Default.SetBytes(value)
+	})
+}
+
+// compileBuiltinBoolOption parses the value of a boolean builtin option,
+// where a bare option name (`@{optional}`) is equivalent to `true`.
+func (c *compiler) compileBuiltinBoolOption(option interface {
+	syntax.Node
+	Value() syntax.Node
+}) (bool, bool) {
+	valueNode := option.Value()
+	if valueNode == nil {
+		return true, true
+	}
+	enumRef, ok := valueNode.(*syntax.EnumRef)
+	if !ok {
+		optType := &typeInfo{
+			type_: schema_idl.Type_BOOL,
+		}
+		c.err(errValueTypeMismatch(option, optType, valueNode))
+		return false, false
+	}
+	switch enumRef.Name().Get() {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+	c.err(errInvalidBoolValue(enumRef))
+	return false, false
+}
+
 func compileDecorators[T any](
 	c *compiler,
 	builtinSchema builtinOptionsSchema,
 	decoratedNode interface {
+		syntax.Node
+		Decorators() []*syntax.Decorator
+	},
+) (*T, *uninterpretedOptions) {
+	return compileDecoratorsCtx[T](c, newOptionsCtx(), builtinSchema, decoratedNode)
+}
+
+func compileDecoratorsCtx[T any](
+	c *compiler,
+	ctx *optionsCtx,
+	builtinSchema builtinOptionsSchema,
+	decoratedNode interface {
+		syntax.Node
 		Decorators() []*syntax.Decorator
 	},
 ) (*T, *uninterpretedOptions) {
 	var builder *T
-	ctx := newOptionsCtx()
 	for _, decorator := range decoratedNode.Decorators() {
 		if options := decorator.GetOptions(); options != nil {
 			var schema *optionsSchema
@@ -983,28 +1207,32 @@ func compileDecorators[T any](
 			}
 		}
 	}
+	if len(ctx.suppressedWarnings) > 0 {
+		c.suppressWarnings(decoratedNode, ctx.suppressedWarnings)
+	}
 	return builder, ctx.uninterpreted
 }
 
 func (c *compiler) compileDecls() {
 	for _, decl := range c.decls {
-		if node, ok := decl.node.(*syntax.Const); ok {
-			if b := c.compileConst(decl, node, false); b != nil {
-				c.schema.Consts.Add(b)
+		if _, ok := decl.node.(*syntax.Const); ok {
+			if decl.constType == nil || !decl.constType.isEnum() {
+				c.ensureCompiled(decl)
+				c.schema.Consts.Add(decl.constBuilder)
 			}
 		}
 	}
 	for _, decl := range c.decls {
-		if node, ok := decl.node.(*syntax.Enum); ok {
-			if b := c.compileEnum(decl, node); b != nil {
-				c.schema.Enums.Add(b)
-			}
+		if _, ok := decl.node.(*syntax.Enum); ok {
+			c.ensureCompiled(decl)
+			c.schema.Enums.Add(decl.enumBuilder)
 		}
 	}
 	for _, decl := range c.decls {
-		if node, ok := decl.node.(*syntax.Const); ok {
-			if b := c.compileConst(decl, node, true); b != nil {
-				c.schema.Consts.Add(b)
+		if _, ok := decl.node.(*syntax.Const); ok {
+			if decl.constType != nil && decl.constType.isEnum() {
+				c.ensureCompiled(decl)
+				c.schema.Consts.Add(decl.constBuilder)
 			}
 		}
 	}
@@ -1020,6 +1248,76 @@ func (c *compiler) compileDecls() {
 			c.schema.Protocols.Add(c.compileProtocol(node))
 		}
 	}
+	c.checkStructCycles()
+}
+
+func (c *compiler) ensureCompiled(decl *declInfo) {
+	if decl.compiled {
+		return
+	}
+	if decl.compiling {
+		start := slices.Index(c.compileStack, decl)
+		c.err(errDeclCycle(c.compileStack[start:]))
+		return
+	}
+	decl.compiling = true
+	c.compileStack = append(c.compileStack, decl)
+	switch node := decl.node.(type) {
+	case *syntax.Const:
+		decl.constBuilder = c.compileConst(decl, node)
+	case *syntax.Enum:
+		decl.enumBuilder = c.compileEnum(decl, node)
+	}
+	c.compileStack = c.compileStack[:len(c.compileStack)-1]
+	decl.compiling = false
+	decl.compiled = true
+}
+
+func (c *compiler) checkStructCycles() {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var stack []*structEdge
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		edges := c.structEdges[name]
+		if strings.Contains(name, "\x1F") && c.opts.deps != nil {
+			edges = c.opts.deps.structEdges(name)
+		}
+		for _, edge := range edges {
+			switch state[edge.typeName] {
+			case visiting:
+				path := append(slices.Clone(stack), edge)
+				start := slices.IndexFunc(path, func(e *structEdge) bool {
+					return e.structName == edge.typeName
+				})
+				var entry *structEdge
+				for _, e := range path {
+					if e.node != nil {
+						entry = e
+						break
+					}
+				}
+				c.err(errStructRecursive(path[start:], entry))
+			case unvisited:
+				stack = append(stack, edge)
+				visit(edge.typeName)
+				stack = stack[:len(stack)-1]
+			}
+		}
+		state[name] = visited
+	}
+	for _, decl := range c.decls {
+		if node, ok := decl.node.(*syntax.Struct); ok {
+			if name := node.Name().Get(); state[name] == unvisited {
+				visit(name)
+			}
+		}
+	}
 }
 
 func (c *compiler) registerConstType(
@@ -1037,23 +1335,22 @@ func (c *compiler) registerConstType(
 func (c *compiler) compileConst(
 	declInfo *declInfo,
 	node *syntax.Const,
-	compilingEnumConsts bool,
 ) *schema_idl.Const__Builder {
 	b := &schema_idl.Const__Builder{}
 	b.Name.Set(node.Name().Get())
+	if doc, ok := c.docComment(node); ok {
+		b.Doc.Set(doc)
+	}
+	if span, ok := c.sourceSpan(node); ok {
+		b.SourceSpan.Set(span)
+	}
 	if opts := c.compileConstOptions(node); opts != nil {
 		b.Options.Set(opts)
 	}
 	typeInfo := declInfo.constType
 	if typeInfo == nil {
-		if compilingEnumConsts {
-			return nil
-		}
 		return b
 	}
-	if compilingEnumConsts != typeInfo.isEnum() {
-		return nil
-	}
 	b.Type.Set(typeInfo.type_)
 	b.TypeName.Set(typeInfo.typeName)
 	if !typeInfo.canCompileValue() {
@@ -1086,6 +1383,9 @@ func (c *compiler) compileConstOptions(
 }
 
 func (t *typeInfo) canCompileValue() bool {
+	if t.isBytes {
+		return false
+	}
 	switch t.type_ {
 	case schema_idl.Type_BOOL:
 	case schema_idl.Type_U8:
@@ -1130,7 +1430,13 @@ func (c *compiler) compileValue(
 	valueType *typeInfo,
 	valueNode syntax.Node,
 ) ([]byte, error) {
+	if isConstExpr(valueNode) {
+		return c.compileConstExprValue(dst, valueType, valueNode)
+	}
 	if valueNode, ok := valueNode.(*syntax.ValueName); ok {
+		if _, ok := c.resolveEnumItemScope(valueNode); ok {
+			return c.compileConstExprValue(dst, valueType, valueNode)
+		}
 		return c.compileNamedValue(dst, valueType, valueNode)
 	}
 	if valueType.isEnum() {
@@ -1388,27 +1694,21 @@ func (c *compiler) compileEnumRefValue(
 	valueNode syntax.Node,
 	name string,
 ) ([]uint8, error) {
-	var enumValues map[string]uint64
-	if valueType.imported != nil {
-		// FIXME verify valueType imported Enum is the same type as what the
-		// const/option expects?
-		//
-		// need to compare namespaces, etc
-
-		enumValues = make(map[string]uint64)
-		// FIXME: is this cast correct? Should it have an error fallback for
-		// non-enum ... whatevers?
-		for _, item := range valueType.imported.(schema_idl.Enum).Items().Iter() {
-			enumValues[item.Name()] = item.Value()
-		}
-	} else {
-		enumValues = valueType.decl.enumValues
-	}
+	// FIXME verify valueType imported Enum is the same type as what the
+	// const/option expects?
+	//
+	// need to compare namespaces, etc
+	enumValues := c.enumValues(valueType)
 
 	value, ok := enumValues[name]
 	if !ok {
-		return nil, errEnumRefNotFound()
+		_, enumName, ok := strings.Cut(valueType.typeName, "\x1F")
+		if !ok {
+			enumName = valueType.typeName
+		}
+		return nil, errEnumItemNotFound(enumName, name, valueNode.Span())
 	}
+	c.checkDeprecatedEnumItem(valueType, name, valueNode)
 
 	span := valueNode.Span()
 	switch valueType.type_ {
@@ -1516,12 +1816,43 @@ func (c *compiler) compileEnum(
 	names := make(map[string]struct{})
 	namesByValue := make(map[uint64]string)
 	var pendingAliases []pendingAlias
+	declInfo.enumValues = valuesByName
+
+	var reservedValueList []uint64
+	var reservedNameList []string
+	reservedValues := make(map[uint64]*syntax.IntLit)
+	reservedNames := make(map[string]*syntax.Ident)
+	for _, reserved := range node.Reserved() {
+		for _, valueNode := range reserved.Values() {
+			value, ok := enumIntLitValue(declInfo.enumType, valueNode)
+			if !ok {
+				c.err(errValueOutOfRange(declInfo.enumType, valueNode))
+				continue
+			}
+			if _, dupe := reservedValues[value]; !dupe {
+				reservedValues[value] = valueNode
+				reservedValueList = append(reservedValueList, value)
+			}
+		}
+		for _, nameNode := range reserved.Names() {
+			if _, dupe := reservedNames[nameNode.Get()]; !dupe {
+				reservedNames[nameNode.Get()] = nameNode
+				reservedNameList = append(reservedNameList, nameNode.Get())
+			}
+		}
+	}
 
 	var items []*schema_idl.EnumItem__Builder
 	for _, item := range node.Items() {
 		itemBuilder := &schema_idl.EnumItem__Builder{}
 		itemName := item.Name().Get()
 		itemBuilder.Name.Set(itemName)
+		if doc, ok := c.docComment(item); ok {
+			itemBuilder.Doc.Set(doc)
+		}
+		if span, ok := c.sourceSpan(item); ok {
+			itemBuilder.SourceSpan.Set(span)
+		}
 		if opts := c.compileEnumItemOptions(item); opts != nil {
 			itemBuilder.Options.Set(opts)
 		}
@@ -1539,51 +1870,16 @@ func (c *compiler) compileEnum(
 			))
 		}
 		names[itemName] = struct{}{}
+		if reservedNode, ok := reservedNames[itemName]; ok {
+			c.err(errEnumItemNameReserved(item.Name(), reservedNode))
+		}
 
 		var value uint64
 		var isAlias bool
 		switch valueNode := item.Value().(type) {
 		case *syntax.IntLit:
 			var ok bool
-			switch declInfo.enumType {
-			case schema_idl.Type_U8:
-				var v uint8
-				if v, ok = valueNode.GetUint8(); ok {
-					value = uint64(v)
-				}
-			case schema_idl.Type_U16:
-				var v uint16
-				if v, ok = valueNode.GetUint16(); ok {
-					value = uint64(v)
-				}
-			case schema_idl.Type_U32:
-				var v uint32
-				if v, ok = valueNode.GetUint32(); ok {
-					value = uint64(v)
-				}
-			case schema_idl.Type_U64:
-				value, ok = valueNode.GetUint64()
-			case schema_idl.Type_I8:
-				var v int8
-				if v, ok = valueNode.GetInt8(); ok {
-					value = uint64(uint8(v))
-				}
-			case schema_idl.Type_I16:
-				var v int16
-				if v, ok = valueNode.GetInt16(); ok {
-					value = uint64(uint16(v))
-				}
-			case schema_idl.Type_I32:
-				var v int32
-				if v, ok = valueNode.GetInt32(); ok {
-					value = uint64(uint32(v))
-				}
-			case schema_idl.Type_I64:
-				var v int64
-				if v, ok = valueNode.GetInt64(); ok {
-					value = uint64(v)
-				}
-			}
+			value, ok = enumIntLitValue(declInfo.enumType, valueNode)
 			if ok {
 				valuesByName[itemName] = value
 				if prevName, conflict := namesByValue[value]; conflict {
@@ -1592,6 +1888,12 @@ func (c *compiler) compileEnum(
 						item.Value(),
 					))
 				}
+				if reservedNode, ok := reservedValues[value]; ok {
+					c.err(errEnumItemValueReserved(
+						declInfo.enumType, value, itemName,
+						item.Value(), reservedNode,
+					))
+				}
 				namesByValue[value] = itemName
 			} else {
 				c.err(errValueOutOfRange(declInfo.enumType, valueNode))
@@ -1609,9 +1911,14 @@ func (c *compiler) compileEnum(
 				})
 			}
 			aliases[itemName] = targetName
-		case *syntax.ValueName:
+		case *syntax.ValueName, *syntax.BinaryExpr, *syntax.UnaryExpr, *syntax.ParenExpr:
+			scope := &exprScope{
+				valueType:  declInfo.enumType,
+				enumName:   node.Name().Get(),
+				enumValues: valuesByName,
+			}
 			var err error
-			value, err = c.resolveEnumItemValue(valueNode, declInfo.enumType)
+			value, err = c.evalConstExprBits(valueNode, scope)
 			if err == nil {
 				valuesByName[itemName] = value
 				if prevName, conflict := namesByValue[value]; conflict {
@@ -1620,6 +1927,12 @@ func (c *compiler) compileEnum(
 						item.Value(),
 					))
 				}
+				if reservedNode, ok := reservedValues[value]; ok {
+					c.err(errEnumItemValueReserved(
+						declInfo.enumType, value, itemName,
+						item.Value(), reservedNode,
+					))
+				}
 				namesByValue[value] = itemName
 			} else {
 				c.err(err)
@@ -1641,19 +1954,55 @@ func (c *compiler) compileEnum(
 		}
 	}
 
-	declInfo.enumValues = valuesByName
 	return build(func(b *schema_idl.Enum__Builder) {
 		b.Name.Set(node.Name().Get())
 		b.Type.Set(declInfo.enumType)
+		if doc, ok := c.docComment(node); ok {
+			b.Doc.Set(doc)
+		}
+		if span, ok := c.sourceSpan(node); ok {
+			b.SourceSpan.Set(span)
+		}
 		if enumOpts != nil {
 			b.Options.Set(enumOpts)
 		}
 		for _, item := range items {
 			b.Items.Add(item)
 		}
+		b.ReservedValues.Set(reservedValueList)
+		b.ReservedNames.Set(reservedNameList)
 	})
 }
 
+func enumIntLitValue(enumType schema_idl.Type, node *syntax.IntLit) (uint64, bool) {
+	switch enumType {
+	case schema_idl.Type_U8:
+		v, ok := node.GetUint8()
+		return uint64(v), ok
+	case schema_idl.Type_U16:
+		v, ok := node.GetUint16()
+		return uint64(v), ok
+	case schema_idl.Type_U32:
+		v, ok := node.GetUint32()
+		return uint64(v), ok
+	case schema_idl.Type_U64:
+		return node.GetUint64()
+	case schema_idl.Type_I8:
+		v, ok := node.GetInt8()
+		return uint64(uint8(v)), ok
+	case schema_idl.Type_I16:
+		v, ok := node.GetInt16()
+		return uint64(uint16(v)), ok
+	case schema_idl.Type_I32:
+		v, ok := node.GetInt32()
+		return uint64(uint32(v)), ok
+	case schema_idl.Type_I64:
+		v, ok := node.GetInt64()
+		return uint64(v), ok
+	}
+	return 0, false
+}
+
 func (c *compiler) compileEnumOptions(
 	node *syntax.Enum,
 ) *schema_idl.EnumOptions__Builder {
@@ -1686,125 +2035,24 @@ func (c *compiler) compileEnumItemOptions(
 	return b
 }
 
-func (c *compiler) resolveEnumItemValue(
-	name *syntax.ValueName,
-	enumType schema_idl.Type,
-) (uint64, error) {
-	const_, err := c.resolveConst(name)
-	if err != nil {
-		return 0, err
-	}
-	if const_.typeName != "" {
-		return 0, &Error{message: "TODO: resolveEnumItemValue const must be plain builtin value (not '" + const_.typeName + "')"}
-	}
-
-	signed := false
-	var value uint64
-	switch const_.type_ {
-	case schema_idl.Type_I8:
-		signed = true
-		fallthrough
-	case schema_idl.Type_U8:
-		if len(const_.value) != 1 {
-			return 0, &Error{message: "TODO invalid const value length"}
-		}
-		value = uint64(const_.value[0])
-	case schema_idl.Type_I16:
-		signed = true
-		fallthrough
-	case schema_idl.Type_U16:
-		if len(const_.value) != 2 {
-			return 0, &Error{message: "TODO invalid const value length"}
-		}
-		value = uint64(binary.LittleEndian.Uint16(const_.value))
-	case schema_idl.Type_I32:
-		signed = true
-		fallthrough
-	case schema_idl.Type_U32:
-		if len(const_.value) != 4 {
-			return 0, &Error{message: "TODO invalid const value length"}
-		}
-		value = uint64(binary.LittleEndian.Uint32(const_.value))
-	case schema_idl.Type_I64:
-		signed = true
-		fallthrough
-	case schema_idl.Type_U64:
-		if len(const_.value) != 8 {
-			return 0, &Error{message: "TODO invalid const value length"}
-		}
-		value = binary.LittleEndian.Uint64(const_.value)
-	default:
-		return 0, &Error{message: "TODO: resolveEnumItemValue const can't be assigned to enum value"}
-	}
-
-	switch enumType {
-	case schema_idl.Type_U8:
-		if signed && int64(value) < 0 {
-			return 0, errValueOutOfRange2(enumType, int64(value), name.Span())
-		}
-		if value > math.MaxUint8 {
-			return 0, errValueOutOfRange2(enumType, value, name.Span())
-		}
-	case schema_idl.Type_I8:
-		value := int64(value)
-		if value < math.MinInt8 || value > math.MaxInt8 {
-			return 0, errValueOutOfRange2(enumType, value, name.Span())
-		}
-	case schema_idl.Type_U16:
-		if signed && int64(value) < 0 {
-			return 0, errValueOutOfRange2(enumType, int64(value), name.Span())
-		}
-		if value > math.MaxUint16 {
-			return 0, errValueOutOfRange2(enumType, value, name.Span())
-		}
-	case schema_idl.Type_I16:
-		value := int64(value)
-		if value < math.MinInt16 || value > math.MaxInt16 {
-			return 0, errValueOutOfRange2(enumType, value, name.Span())
-		}
-	case schema_idl.Type_U32:
-		if signed && int64(value) < 0 {
-			return 0, errValueOutOfRange2(enumType, int64(value), name.Span())
-		}
-		if value > math.MaxUint32 {
-			return 0, errValueOutOfRange2(enumType, value, name.Span())
-		}
-	case schema_idl.Type_I32:
-		value := int64(value)
-		if value < math.MinInt32 || value > math.MaxInt32 {
-			return 0, errValueOutOfRange2(enumType, value, name.Span())
-		}
-	case schema_idl.Type_U64:
-		if signed && int64(value) < 0 {
-			return 0, errValueOutOfRange2(enumType, int64(value), name.Span())
-		}
-		if value > math.MaxUint64 {
-			return 0, errValueOutOfRange2(enumType, value, name.Span())
-		}
-	case schema_idl.Type_I64:
-		value := int64(value)
-		if value < math.MinInt64 || value > math.MaxInt64 {
-			return 0, errValueOutOfRange2(enumType, value, name.Span())
-		}
-	default:
-		panic("unreachable")
-	}
-
-	return value, nil
-}
-
 func (c *compiler) compileStruct(
 	node *syntax.Struct,
 ) *schema_idl.Struct__Builder {
 	b := &schema_idl.Struct__Builder{}
 	b.Name.Set(node.Name().Get())
+	if doc, ok := c.docComment(node); ok {
+		b.Doc.Set(doc)
+	}
+	if span, ok := c.sourceSpan(node); ok {
+		b.SourceSpan.Set(span)
+	}
 	if opts := c.compileStructOptions(node); opts != nil {
 		b.Options.Set(opts)
 	}
 
 	fieldsByName := make(map[string]*syntax.StructField)
 	for _, field := range node.Fields() {
-		b.Fields.Add(c.compileStructField(field, fieldsByName))
+		b.Fields.Add(c.compileStructField(node, field, fieldsByName))
 	}
 	if len(fieldsByName) == 0 {
 		c.err(errStructEmpty(node.Name().Get(), node.Span()))
@@ -1829,6 +2077,7 @@ func (c *compiler) compileStructOptions(
 }
 
 func (c *compiler) compileStructField(
+	struct_ *syntax.Struct,
 	node *syntax.StructField,
 	fieldsByName map[string]*syntax.StructField,
 ) *schema_idl.StructField__Builder {
@@ -1839,6 +2088,12 @@ func (c *compiler) compileStructField(
 
 	fieldName := node.Name().Get()
 	b.Name.Set(fieldName)
+	if doc, ok := c.docComment(node); ok {
+		b.Doc.Set(doc)
+	}
+	if span, ok := c.sourceSpan(node); ok {
+		b.SourceSpan.Set(span)
+	}
 	if prev, dupe := fieldsByName[fieldName]; dupe {
 		c.err(errFieldNameConflict("Struct", prev, node))
 	} else {
@@ -1853,8 +2108,20 @@ func (c *compiler) compileStructField(
 	}
 	b.Type.Set(resolved.type_)
 	b.TypeName.Set(resolved.typeName)
+	if resolved.type_ == schema_idl.Type_STRUCT {
+		if c.structEdges == nil {
+			c.structEdges = make(map[string][]*structEdge)
+		}
+		structName := struct_.Name().Get()
+		c.structEdges[structName] = append(c.structEdges[structName], &structEdge{
+			structName: structName,
+			fieldName:  fieldName,
+			typeName:   resolved.typeName,
+			node:       node,
+		})
+	}
 
-	arrayLen := c.checkFieldArrayLen(fieldType, true)
+	arrayLen := c.checkFieldArrayLen(fieldType, resolved, true)
 	b.ArrayLen.Set(arrayLen)
 
 	return b
@@ -1881,14 +2148,24 @@ func (c *compiler) compileMessage(
 ) *schema_idl.Message__Builder {
 	b := &schema_idl.Message__Builder{}
 	b.Name.Set(node.Name().Get())
+	if doc, ok := c.docComment(node); ok {
+		b.Doc.Set(doc)
+	}
+	if span, ok := c.sourceSpan(node); ok {
+		b.SourceSpan.Set(span)
+	}
 	if opts := c.compileMessageOptions(node); opts != nil {
 		b.Options.Set(opts)
 	}
 
+	reserved := c.compileReservedFields(node.Reserved())
+	b.ReservedTags.Set(reserved.tagList)
+	b.ReservedNames.Set(reserved.nameList)
+
 	fieldsByTag := make(map[uint16]*syntax.MessageField)
 	fieldsByName := make(map[string]*syntax.MessageField)
 	for _, field := range node.Fields() {
-		b.Fields.Add(c.compileMessageField(field, fieldsByTag, fieldsByName))
+		b.Fields.Add(c.compileMessageField(field, fieldsByTag, fieldsByName, reserved))
 	}
 	return b
 }
@@ -1913,19 +2190,26 @@ func (c *compiler) compileMessageField(
 	node *syntax.MessageField,
 	fieldsByTag map[uint16]*syntax.MessageField,
 	fieldsByName map[string]*syntax.MessageField,
+	reserved *reservedFields,
 ) *schema_idl.MessageField__Builder {
 	b := &schema_idl.MessageField__Builder{}
-	if options := c.compileMessageFieldOptions(node); options != nil {
-		b.Options.Set(options)
-	}
 
 	fieldName := node.Name().Get()
 	b.Name.Set(fieldName)
+	if doc, ok := c.docComment(node); ok {
+		b.Doc.Set(doc)
+	}
+	if span, ok := c.sourceSpan(node); ok {
+		b.SourceSpan.Set(span)
+	}
 	if prev, dupe := fieldsByName[fieldName]; dupe {
 		c.err(errFieldNameConflict("Message", prev, node))
 	} else {
 		fieldsByName[fieldName] = node
 	}
+	if reservedNode, ok := reserved.names[fieldName]; ok {
+		c.err(errFieldNameReserved("Message", node, reservedNode))
+	}
 
 	tag, tagOk := c.checkFieldTag(node)
 	if tagOk {
@@ -1934,6 +2218,9 @@ func (c *compiler) compileMessageField(
 		} else {
 			fieldsByTag[tag] = node
 		}
+		if reservedNode, ok := reserved.tags[tag]; ok {
+			c.err(errFieldTagReserved(tag, node, reservedNode))
+		}
 		b.Tag.Set(tag)
 	}
 
@@ -1946,17 +2233,27 @@ func (c *compiler) compileMessageField(
 	b.Type.Set(resolved.type_)
 	b.TypeName.Set(resolved.typeName)
 
-	arrayLen := c.checkFieldArrayLen(fieldType, false)
+	arrayLen := c.checkFieldArrayLen(fieldType, resolved, false)
 	b.ArrayLen.Set(arrayLen)
 
+	if options := c.compileMessageFieldOptions(node, resolved, arrayLen); options != nil {
+		b.Options.Set(options)
+	}
+
 	return b
 }
 
 func (c *compiler) compileMessageFieldOptions(
 	node *syntax.MessageField,
+	fieldType *typeInfo,
+	arrayLen uint32,
 ) *schema_idl.MessageFieldOptions__Builder {
 	type T = schema_idl.MessageFieldOptions__Builder
-	b, uninterpreted := compileDecorators[T](c, _OPTS_MESSAGE_FIELD, node)
+	ctx := newOptionsCtx()
+	ctx.field = node
+	ctx.fieldType = fieldType
+	ctx.fieldArrayLen = arrayLen
+	b, uninterpreted := compileDecoratorsCtx[T](c, ctx, _OPTS_MESSAGE_FIELD, node)
 	if len(uninterpreted.builders) > 0 {
 		if b == nil {
 			b = &schema_idl.MessageFieldOptions__Builder{}
@@ -1973,14 +2270,24 @@ func (c *compiler) compileUnion(
 ) *schema_idl.Union__Builder {
 	b := &schema_idl.Union__Builder{}
 	b.Name.Set(node.Name().Get())
+	if doc, ok := c.docComment(node); ok {
+		b.Doc.Set(doc)
+	}
+	if span, ok := c.sourceSpan(node); ok {
+		b.SourceSpan.Set(span)
+	}
 	if opts := c.compileUnionOptions(node); opts != nil {
 		b.Options.Set(opts)
 	}
 
+	reserved := c.compileReservedFields(node.Reserved())
+	b.ReservedTags.Set(reserved.tagList)
+	b.ReservedNames.Set(reserved.nameList)
+
 	fieldsByTag := make(map[uint16]*syntax.UnionField)
 	fieldsByName := make(map[string]*syntax.UnionField)
 	for _, field := range node.Fields() {
-		b.Fields.Add(c.compileUnionField(field, fieldsByTag, fieldsByName))
+		b.Fields.Add(c.compileUnionField(field, fieldsByTag, fieldsByName, reserved))
 	}
 	return b
 }
@@ -2005,6 +2312,7 @@ func (c *compiler) compileUnionField(
 	node *syntax.UnionField,
 	fieldsByTag map[uint16]*syntax.UnionField,
 	fieldsByName map[string]*syntax.UnionField,
+	reserved *reservedFields,
 ) *schema_idl.UnionField__Builder {
 	b := &schema_idl.UnionField__Builder{}
 	if options := c.compileUnionFieldOptions(node); options != nil {
@@ -2013,11 +2321,20 @@ func (c *compiler) compileUnionField(
 
 	fieldName := node.Name().Get()
 	b.Name.Set(fieldName)
+	if doc, ok := c.docComment(node); ok {
+		b.Doc.Set(doc)
+	}
+	if span, ok := c.sourceSpan(node); ok {
+		b.SourceSpan.Set(span)
+	}
 	if prev, dupe := fieldsByName[fieldName]; dupe {
 		c.err(errFieldNameConflict("Union", prev, node))
 	} else {
 		fieldsByName[fieldName] = node
 	}
+	if reservedNode, ok := reserved.names[fieldName]; ok {
+		c.err(errFieldNameReserved("Union", node, reservedNode))
+	}
 
 	tag, tagOk := c.checkFieldTag(node)
 	if tagOk {
@@ -2026,6 +2343,9 @@ func (c *compiler) compileUnionField(
 		} else {
 			fieldsByTag[tag] = node
 		}
+		if reservedNode, ok := reserved.tags[tag]; ok {
+			c.err(errFieldTagReserved(tag, node, reservedNode))
+		}
 		b.Tag.Set(tag)
 	}
 
@@ -2038,7 +2358,7 @@ func (c *compiler) compileUnionField(
 	b.Type.Set(resolved.type_)
 	b.TypeName.Set(resolved.typeName)
 
-	arrayLen := c.checkFieldArrayLen(fieldType, false)
+	arrayLen := c.checkFieldArrayLen(fieldType, resolved, false)
 	b.ArrayLen.Set(arrayLen)
 
 	return b
@@ -2060,6 +2380,40 @@ func (c *compiler) compileUnionFieldOptions(
 	return b
 }
 
+type reservedFields struct {
+	tags     map[uint16]*syntax.Tag
+	names    map[string]*syntax.Ident
+	tagList  []uint16
+	nameList []string
+}
+
+func (c *compiler) compileReservedFields(nodes []*syntax.Reserved) *reservedFields {
+	reserved := &reservedFields{
+		tags:  make(map[uint16]*syntax.Tag),
+		names: make(map[string]*syntax.Ident),
+	}
+	for _, node := range nodes {
+		for _, tagNode := range node.Tags() {
+			tag, ok := tagNode.Value().GetUint16()
+			if !ok || tag == 0 {
+				c.err(errFieldTagOutOfRange(node, tagNode))
+				continue
+			}
+			if _, dupe := reserved.tags[tag]; !dupe {
+				reserved.tags[tag] = tagNode
+				reserved.tagList = append(reserved.tagList, tag)
+			}
+		}
+		for _, nameNode := range node.Names() {
+			if _, dupe := reserved.names[nameNode.Get()]; !dupe {
+				reserved.names[nameNode.Get()] = nameNode
+				reserved.nameList = append(reserved.nameList, nameNode.Get())
+			}
+		}
+	}
+	return reserved
+}
+
 func (c *compiler) checkFieldTag(
 	field interface {
 		syntax.Node
@@ -2077,8 +2431,20 @@ func (c *compiler) checkFieldTag(
 
 func (c *compiler) checkFieldArrayLen(
 	fieldType *syntax.FieldType,
+	resolved *typeInfo,
 	isStructField bool,
 ) uint32 {
+	if resolved.isBytes {
+		if fieldType.IsArray() {
+			c.err(errBytesArray(fieldType))
+			return 0
+		}
+		if isStructField {
+			c.err(errStructFieldBytes(fieldType))
+			return 0
+		}
+		return math.MaxUint32
+	}
 	if !fieldType.IsArray() {
 		return 0
 	}
@@ -2091,10 +2457,24 @@ func (c *compiler) checkFieldArrayLen(
 		return math.MaxUint32
 	}
 
-	arrayLen, ok := arrayLenNode.GetUint32()
-	if !ok {
-		c.err(errArrayLenNotU32())
-		return 0
+	var arrayLen uint32
+	if intLit, ok := arrayLenNode.(*syntax.IntLit); ok {
+		if arrayLen, ok = intLit.GetUint32(); !ok {
+			c.err(errArrayLenOutOfRange(intLit))
+			return 0
+		}
+	} else {
+		scope := &exprScope{valueType: schema_idl.Type_U32}
+		value, err := c.evalConstExpr(arrayLenNode, scope)
+		if err != nil {
+			c.err(err)
+			return 0
+		}
+		if !value.IsUint64() || value.Uint64() > math.MaxUint32 {
+			c.err(errArrayLenOutOfRange(arrayLenNode))
+			return 0
+		}
+		arrayLen = uint32(value.Uint64())
 	}
 	if arrayLen == 0 {
 		c.err(errArrayLenZero())
@@ -2109,6 +2489,12 @@ func (c *compiler) compileProtocol(
 ) *schema_idl.Protocol__Builder {
 	b := &schema_idl.Protocol__Builder{}
 	b.Name.Set(node.Name().Get())
+	if doc, ok := c.docComment(node); ok {
+		b.Doc.Set(doc)
+	}
+	if span, ok := c.sourceSpan(node); ok {
+		b.SourceSpan.Set(span)
+	}
 
 	if options := c.compileProtocolOptions(node); options != nil {
 		b.Options.Set(options)
@@ -2153,6 +2539,12 @@ func (c *compiler) compileProtocolRpc(
 
 	name := node.Name().Get()
 	b.Name.Set(name)
+	if doc, ok := c.docComment(node); ok {
+		b.Doc.Set(doc)
+	}
+	if span, ok := c.sourceSpan(node); ok {
+		b.SourceSpan.Set(span)
+	}
 	if prev, conflict := itemsByName[name]; conflict {
 		c.err(errProtocolItemNameConflict(node, prev))
 	} else {
@@ -2235,6 +2627,12 @@ func (c *compiler) compileProtocolEvent(
 
 	name := node.Name().Get()
 	b.Name.Set(name)
+	if doc, ok := c.docComment(node); ok {
+		b.Doc.Set(doc)
+	}
+	if span, ok := c.sourceSpan(node); ok {
+		b.SourceSpan.Set(span)
+	}
 	if prev, conflict := itemsByName[name]; conflict {
 		c.err(errProtocolItemNameConflict(node, prev))
 	} else {
@@ -2338,6 +2736,7 @@ func (c *compiler) resolveType(
 			return nil, err
 		}
 		ictx.usedNames[name] = struct{}{}
+		c.checkDeprecatedType(ictx.namespace, name, decl.imported, node)
 		return &typeInfo{
 			type_:    decl.type_,
 			typeName: ictx.namespace + "\x1F" + name,
@@ -2403,6 +2802,7 @@ func (c *compiler) resolveType(
 		}
 		importedName.used = true
 		ictx.usedNames[name] = struct{}{}
+		c.checkDeprecatedType(ictx.namespace, name, decl.imported, node)
 		return &typeInfo{
 			type_:    decl.type_,
 			typeName: ictx.namespace + "\x1F" + name,
@@ -2412,13 +2812,43 @@ func (c *compiler) resolveType(
 
 	if type_, ok := builtinTypes[name]; ok {
 		return &typeInfo{
-			type_: type_,
+			type_:   type_,
+			isBytes: name == "bytes",
 		}, nil
 	}
 
 	return nil, errTypeNameNotFound()
 }
 
+func (c *compiler) checkDeprecatedType(
+	namespace, name string,
+	imported any,
+	node syntax.Node,
+) {
+	if msg, ok := imported.(schema_idl.Message); ok {
+		if msg.Options().Deprecated() {
+			c.warn(warnDeprecatedReference(namespace, name, node))
+		}
+	}
+}
+
+func (c *compiler) checkDeprecatedEnumItem(
+	enumType *typeInfo,
+	name string,
+	node syntax.Node,
+) {
+	enum, ok := enumType.imported.(schema_idl.Enum)
+	if !ok {
+		return
+	}
+	for _, item := range enum.Items().Iter() {
+		if string(item.Name()) == name && item.Options().Deprecated() {
+			namespace, enumName, _ := strings.Cut(enumType.typeName, "\x1F")
+			c.warn(warnDeprecatedReference(namespace, enumName+"."+name, node))
+		}
+	}
+}
+
 func (c *compiler) resolveType2(
 	namespace, name string,
 	node syntax.Node,
@@ -2509,8 +2939,9 @@ func (c *compiler) resolveConst(
 		case *syntax.Union:
 			return nil, errResolvedDeclNotConst()
 		case *syntax.Const:
+			c.ensureCompiled(decl)
 			if decl.constValue == nil {
-				return nil, &Error{message: "TODO: resolveConst not compiled yet"}
+				return nil, errPoisoned
 			}
 			return &constInfo{
 				type_:    decl.constType.type_,
@@ -0,0 +1,99 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"math"
+	"testing"
+
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/schema_idl"
+)
+
+func TestBytes(t *testing.T) {
+	errs, result := compileErrors(t, `namespace "test"
+message M {
+	a @1 : bytes
+	b @2 : u8[]
+}
+union U {
+	a @1 : bytes
+}
+`)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+
+	msg, _ := schema.Messages().Get(0)
+	a, _ := msg.Fields().Get(0)
+	b, _ := msg.Fields().Get(1)
+	for _, field := range []schema_idl.MessageField{a, b} {
+		testutil.ExpectEq(t, schema_idl.Type_U8, field.Type())
+		testutil.ExpectEq(t, "", field.TypeName())
+		testutil.ExpectEq(t, uint32(math.MaxUint32), field.ArrayLen())
+	}
+
+	union, _ := schema.Unions().Get(0)
+	unionField, _ := union.Fields().Get(0)
+	testutil.ExpectEq(t, schema_idl.Type_U8, unionField.Type())
+	testutil.ExpectEq(t, uint32(math.MaxUint32), unionField.ArrayLen())
+}
+
+func TestBytes_Errors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{
+			"message M {\n\ta @1 : bytes[]\n}\n",
+			"E3039: Type 'bytes' can't be used as an array element type",
+		},
+		{
+			"message M {\n\ta @1 : bytes[4]\n}\n",
+			"E3039: Type 'bytes' can't be used as an array element type",
+		},
+		{
+			"struct S {\n\ta : bytes\n}\n",
+			"E3052: Type 'bytes' can't be used in a struct field (struct fields must have a fixed size)",
+		},
+	}
+	for _, test := range tests {
+		errs, result := compileErrors(t, "namespace \"test\"\n"+test.src)
+		if len(errs) != 1 {
+			t.Errorf("%q: expected 1 error, got %q", test.src, errs)
+			continue
+		}
+		testutil.ExpectEq(t, test.err, errs[0])
+		if span := result.Errors[0].Span(); span.Len() == 0 {
+			t.Errorf("%q: error %q has no span", test.src, errs[0])
+		}
+	}
+}
+
+func TestBytes_ShadowsBuiltin(t *testing.T) {
+	errs, result := compileErrors(t, `namespace "test"
+message bytes {}
+`)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(result.Warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", result.Warnings)
+	}
+	testutil.ExpectEq(t, uint32(4009), result.Warnings[0].Code())
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+// Bump when a compiler change would produce different output for the same
+// input, to invalidate existing cache entries.
+const cacheVersion = "idol-compile-cache-v2"
+
+// A Cache stores compiled schemas in a directory, keyed by a hash of the
+// schema source, the compiler options, and the compiled dependencies.
+//
+// Entries are written atomically, so a cache directory may be shared by
+// concurrent processes.
+type Cache struct {
+	dir string
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Compile parses and compiles src, or returns the previously compiled schema
+// if the same inputs have been compiled before.
+//
+// Only schemas that compiled without errors or warnings are cached, so that
+// a cache hit never hides a diagnostic.
+func (c *Cache) Compile(
+	src []byte,
+	deps []schema_idl.Schema,
+	opts ...CompileOption,
+) (CompileResult, error) {
+	return c.compile(src, nil, deps, NewCompileOptions(opts...))
+}
+
+func (c *Cache) compile(
+	src []byte,
+	parsed *syntax.Schema,
+	deps []schema_idl.Schema,
+	opts *CompileOptions,
+) (CompileResult, error) {
+	key, err := c.key(src, deps, opts)
+	if err != nil {
+		return CompileResult{}, err
+	}
+	if schema, ok := c.get(key); ok {
+		return CompileResult{
+			schema: idol.Clone(schema).Self().(*schema_idl.Schema__Builder),
+		}, nil
+	}
+
+	if parsed == nil {
+		if parsed, err = syntax.Parse(src); err != nil {
+			return CompileResult{}, err
+		}
+	}
+	mergedDeps, err := Merge(deps)
+	if err != nil {
+		return CompileResult{}, err
+	}
+	depOpts := *opts
+	depOpts.deps = mergedDeps
+	result := depOpts.Compile(parsed)
+	if len(result.Errors) > 0 || len(result.Warnings) > 0 {
+		return result, nil
+	}
+	encoded, err := result.EncodedSchema()
+	if err != nil {
+		return CompileResult{}, err
+	}
+	if err := c.put(key, []byte(encoded)); err != nil {
+		return CompileResult{}, err
+	}
+	return result, nil
+}
+
+func (c *Cache) key(
+	src []byte,
+	deps []schema_idl.Schema,
+	opts *CompileOptions,
+) (string, error) {
+	h := sha256.New()
+	writeField := func(h hash.Hash, value []byte) {
+		var lenBuf [8]byte
+		binary.LittleEndian.PutUint64(lenBuf[:], uint64(len(value)))
+		h.Write(lenBuf[:])
+		h.Write(value)
+	}
+
+	writeCount := func(h hash.Hash, count int) {
+		writeField(h, binary.LittleEndian.AppendUint32(nil, uint32(count)))
+	}
+
+	writeField(h, []byte(cacheVersion))
+	writeField(h, src)
+	writeCount(h, len(opts.sourcePath))
+	for _, part := range opts.sourcePath {
+		writeField(h, []byte(part))
+	}
+	var flags [3]byte
+	if opts.stripDocComments {
+		flags[0] = 1
+	}
+	if opts.sourceInfo {
+		flags[1] = 1
+	}
+	if opts.warningsAsErrors {
+		flags[2] = 1
+	}
+	writeField(h, flags[:])
+	for _, codes := range [][]uint32{opts.warningErrorCodes, opts.suppressedWarnings} {
+		codes = slices.Sorted(slices.Values(codes))
+		writeCount(h, len(codes))
+		for _, code := range codes {
+			writeField(h, binary.LittleEndian.AppendUint32(nil, code))
+		}
+	}
+
+	deps = slices.SortedStableFunc(slices.Values(deps), func(a, b schema_idl.Schema) int {
+		return cmp.Compare(a.Namespace(), b.Namespace())
+	})
+	writeCount(h, len(deps))
+	for _, dep := range deps {
+		encoded, err := idol.Encode(&idol.EncodeCtx{}, idol.Clone(dep).Self())
+		if err != nil {
+			return "", err
+		}
+		depHash := sha256.Sum256(encoded)
+		writeField(h, []byte(dep.Namespace()))
+		writeField(h, depHash[:])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".idolbin")
+}
+
+// get returns a cached schema. Entries that can't be read, or that fail
+// validation, are treated as missing and will be replaced by put.
+func (c *Cache) get(key string) (schema_idl.Schema, bool) {
+	buf, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return schema_idl.Schema{}, false
+	}
+	encoded, ok := checkCacheEntry(buf)
+	if !ok {
+		return schema_idl.Schema{}, false
+	}
+	schema, err := idol.DecodeAs[schema_idl.Schema](&idol.DecodeCtx{}, encoded)
+	if err != nil {
+		return schema_idl.Schema{}, false
+	}
+	return schema, true
+}
+
+// A cache entry is the SHA-256 digest of an encoded schema, followed by the
+// encoded schema. The digest detects corrupt or truncated entries, which
+// would otherwise be passed to the decoder.
+func checkCacheEntry(buf []byte) ([]byte, bool) {
+	if len(buf) < sha256.Size+8 {
+		return nil, false
+	}
+	digest, encoded := buf[:sha256.Size], buf[sha256.Size:]
+	if sum := sha256.Sum256(encoded); !bytes.Equal(digest, sum[:]) {
+		return nil, false
+	}
+	if len(encoded)%8 != 0 || uint64(len(encoded)) > uint64(idol.MaxMessageSize) {
+		return nil, false
+	}
+	if binary.LittleEndian.Uint32(encoded[0:4]) != uint32(len(encoded)) {
+		return nil, false
+	}
+	return encoded, true
+}
+
+func (c *Cache) put(key string, encoded []byte) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file and rename it into place, so that readers
+	// never observe a partially written entry.
+	fp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(encoded)
+	_, writeErr := fp.Write(append(digest[:], encoded...))
+	closeErr := fp.Close()
+	if writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr == nil {
+		writeErr = os.Rename(fp.Name(), path)
+	}
+	if writeErr != nil {
+		os.Remove(fp.Name())
+	}
+	return writeErr
+}
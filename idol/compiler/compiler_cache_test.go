@@ -0,0 +1,171 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/schema_idl"
+)
+
+// cacheEntries returns the paths of entries in a cache directory.
+func cacheEntries(t *testing.T, dir string) []string {
+	t.Helper()
+	var entries []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && filepath.Ext(path) == ".idolbin" {
+			entries = append(entries, path)
+		}
+		return err
+	})
+	testutil.AssertNoError(t, err)
+	return entries
+}
+
+func cacheCompile(
+	t *testing.T,
+	cache *compiler.Cache,
+	src string,
+	deps []schema_idl.Schema,
+	opts ...compiler.CompileOption,
+) schema_idl.Schema {
+	t.Helper()
+	result, err := cache.Compile([]byte(src), deps, opts...)
+	testutil.AssertNoError(t, err)
+	for _, err := range result.Errors {
+		t.Fatal(err)
+	}
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+	return schema
+}
+
+func TestCache_Hit(t *testing.T) {
+	dir := t.TempDir()
+	cache := compiler.NewCache(dir)
+	src := "namespace \"test\"\nmessage M {}\n"
+	cacheCompile(t, cache, src, nil)
+	entries := cacheEntries(t, dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 cache entry, got %q", entries)
+	}
+
+	// Replace the entry with that of another schema, so that a cache hit
+	// is observable.
+	otherDir := t.TempDir()
+	cacheCompile(t, compiler.NewCache(otherDir), "namespace \"other\"\n", nil)
+	otherEntries := cacheEntries(t, otherDir)
+	if len(otherEntries) != 1 {
+		t.Fatalf("expected 1 cache entry, got %q", otherEntries)
+	}
+	otherEntry, err := os.ReadFile(otherEntries[0])
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, os.WriteFile(entries[0], otherEntry, 0o644))
+
+	schema := cacheCompile(t, cache, src, nil)
+	testutil.ExpectEq(t, "other", schema.Namespace())
+}
+
+func TestCache_Miss(t *testing.T) {
+	dir := t.TempDir()
+	cache := compiler.NewCache(dir)
+	src := "namespace \"test\"\nmessage M {}\n"
+	cacheCompile(t, cache, src, nil)
+	cacheCompile(t, cache, src+"message N {}\n", nil)
+	cacheCompile(t, cache, src, nil, compiler.WithoutDocComments())
+	cacheCompile(t, cache, src, nil, compiler.WithSourcePath([]string{"a", "bc"}))
+	cacheCompile(t, cache, src, nil, compiler.WithSourcePath([]string{"ab", "c"}))
+	cacheCompile(t, cache, src, nil, compiler.WithSourcePath([]string{"a", "bc"}))
+	testutil.ExpectEq(t, 5, len(cacheEntries(t, dir)))
+}
+
+func TestCache_DependencyChanged(t *testing.T) {
+	dir := t.TempDir()
+	cache := compiler.NewCache(dir)
+	depV1 := cacheCompile(t, cache, "namespace \"dep\"\nmessage D {}\n", nil)
+	depV2 := cacheCompile(t, cache, "namespace \"dep\"\nmessage D {\n\ta @1 : u8\n}\n", nil)
+	src := "namespace \"test\"\nimport \"dep\" { D }\nmessage M {\n\td @1 : D\n}\n"
+
+	cacheCompile(t, cache, src, []schema_idl.Schema{depV1})
+	testutil.ExpectEq(t, 3, len(cacheEntries(t, dir)))
+	cacheCompile(t, cache, src, []schema_idl.Schema{depV1})
+	testutil.ExpectEq(t, 3, len(cacheEntries(t, dir)))
+	cacheCompile(t, cache, src, []schema_idl.Schema{depV2})
+	testutil.ExpectEq(t, 4, len(cacheEntries(t, dir)))
+}
+
+func TestCache_CorruptEntry(t *testing.T) {
+	src := "namespace \"test\"\nmessage M {}\n"
+	// A digest of eight zero bytes, which match the digest but aren't a
+	// valid message header.
+	badHeader := sha256.Sum256(make([]byte, 8))
+	tests := []struct {
+		name    string
+		corrupt func(entry []byte) []byte
+	}{
+		{"garbage", func([]byte) []byte { return []byte("corrupt") }},
+		{"empty", func([]byte) []byte { return nil }},
+		{"truncated", func(entry []byte) []byte { return entry[:len(entry)-8] }},
+		{"bit flip", func(entry []byte) []byte {
+			entry[len(entry)-1] ^= 0x01
+			return entry
+		}},
+		{"bad header", func([]byte) []byte {
+			return append(badHeader[:], make([]byte, 8)...)
+		}},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		cache := compiler.NewCache(dir)
+		cacheCompile(t, cache, src, nil)
+		entries := cacheEntries(t, dir)
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 cache entry, got %q", entries)
+		}
+		valid, err := os.ReadFile(entries[0])
+		testutil.AssertNoError(t, err)
+		corrupt := test.corrupt(bytes.Clone(valid))
+		testutil.AssertNoError(t, os.WriteFile(entries[0], corrupt, 0o644))
+
+		// A corrupt entry is treated as a miss, and replaced.
+		schema := cacheCompile(t, cache, src, nil)
+		testutil.ExpectEq(t, "test", schema.Namespace())
+		buf, err := os.ReadFile(entries[0])
+		testutil.AssertNoError(t, err)
+		if !bytes.Equal(buf, valid) {
+			t.Errorf("%s: corrupt cache entry wasn't replaced", test.name)
+		}
+	}
+}
+
+func TestCache_WarningsNotCached(t *testing.T) {
+	dir := t.TempDir()
+	cache := compiler.NewCache(dir)
+	src := "namespace \"test\"\nmessage bytes {}\n"
+	for range 2 {
+		result, err := cache.Compile([]byte(src), nil)
+		testutil.AssertNoError(t, err)
+		testutil.ExpectEq(t, 1, len(result.Warnings))
+	}
+	testutil.ExpectEq(t, 0, len(cacheEntries(t, dir)))
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"fmt"
+	"testing"
+
+	"go.idol-lang.org/idol/internal/testutil"
+)
+
+func TestDefaults(t *testing.T) {
+	tests := []struct {
+		type_ string
+		value string
+		bytes []uint8
+	}{
+		{"bool", ".true", []uint8{1}},
+		{"u8", "0xFF", []uint8{0xFF}},
+		{"u16", "0x1234", []uint8{0x34, 0x12}},
+		{"u32", "1", []uint8{1, 0, 0, 0}},
+		{"u64", "2", []uint8{2, 0, 0, 0, 0, 0, 0, 0}},
+		{"i8", "-1", []uint8{0xFF}},
+		{"i16", "-2", []uint8{0xFE, 0xFF}},
+		{"i32", "-3", []uint8{0xFD, 0xFF, 0xFF, 0xFF}},
+		{"i64", "-4", []uint8{0xFC, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{"f32", "1", []uint8{0x00, 0x00, 0x80, 0x3F}},
+		{"f64", "-2", []uint8{0, 0, 0, 0, 0, 0, 0x00, 0xC0}},
+		{"text", `"abc"`, []uint8("abc")},
+		{"E", "E.B", []uint8{2}},
+		{"u32", "C", []uint8{7, 0, 0, 0}},
+	}
+	for _, test := range tests {
+		src := fmt.Sprintf(`namespace "test"
+const C: u32 = 7
+enum E : u8 {
+	A = 1
+	B = 2
+}
+message M {
+	@{default = %s}
+	a @1 : %s
+}
+`, test.value, test.type_)
+		errs, result := compileErrors(t, src)
+		if len(errs) > 0 {
+			t.Errorf("%s = %s: unexpected errors %q", test.type_, test.value, errs)
+			continue
+		}
+		schema, err := result.Schema()
+		testutil.AssertNoError(t, err)
+		msg, _ := schema.Messages().Get(0)
+		field, _ := msg.Fields().Get(0)
+		testutil.ExpectSliceEq(t, test.bytes, field.Options().Default().Collect())
+	}
+}
+
+func TestDefaults_Errors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{
+			"message M {\n\t@{default = 1}\n\ta @1 : u8[]\n}\n",
+			"E3048: Field 'a' of type 'u8[]' can't have a default value",
+		},
+		{
+			"message M {\n\t@{default = 1}\n\ta @1 : bytes\n}\n",
+			"E3048: Field 'a' of type 'bytes' can't have a default value",
+		},
+		{
+			"message N {}\nmessage M {\n\t@{default = 1}\n\ta @1 : N\n}\n",
+			"E3048: Field 'a' of type 'N' can't have a default value",
+		},
+		{
+			"message M {\n\t@{default}\n\ta @1 : u8\n}\n",
+			"E3049: Option 'default' requires a value",
+		},
+		{
+			"message M {\n\t@{default = 256}\n\ta @1 : u8\n}\n",
+			"E3016: Value 256 out of range [0, 255] for type 'u8'",
+		},
+	}
+	for _, test := range tests {
+		errs, result := compileErrors(t, "namespace \"test\"\n"+test.src)
+		if len(errs) != 1 {
+			t.Errorf("%q: expected 1 error, got %q", test.src, errs)
+			continue
+		}
+		testutil.ExpectEq(t, test.err, errs[0])
+		span := result.Errors[0].Span()
+		if span.Len() == 0 {
+			t.Errorf("%q: error has no span", test.src)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+func TestDeprecated_Options(t *testing.T) {
+	errs, result := compileErrors(t, `namespace "test"
+@{deprecated}
+message M {
+	@{deprecated}
+	a @1 : u8
+	b @2 : u8
+}
+enum E : u8 {
+	@{deprecated}
+	A = 1
+	B = 2
+}
+protocol P {
+	@{deprecated}
+	rpc Get(M) : M
+	@{deprecated}
+	event Changed : M
+}
+`)
+	testutil.ExpectSliceEq(t, nil, errs)
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+
+	msg, _ := schema.Messages().Get(0)
+	testutil.ExpectEq(t, true, msg.Options().Deprecated())
+	a, _ := msg.Fields().Get(0)
+	testutil.ExpectEq(t, true, a.Options().Deprecated())
+	b, _ := msg.Fields().Get(1)
+	testutil.ExpectEq(t, false, b.Options().Deprecated())
+
+	enum, _ := schema.Enums().Get(0)
+	itemA, _ := enum.Items().Get(0)
+	testutil.ExpectEq(t, true, itemA.Options().Deprecated())
+	itemB, _ := enum.Items().Get(1)
+	testutil.ExpectEq(t, false, itemB.Options().Deprecated())
+
+	protocol, _ := schema.Protocols().Get(0)
+	rpc, _ := protocol.Rpcs().Get(0)
+	testutil.ExpectEq(t, true, rpc.Options().Deprecated())
+	event, _ := protocol.Events().Get(0)
+	testutil.ExpectEq(t, true, event.Options().Deprecated())
+}
+
+const deprecatedDep = `namespace "dep"
+@{deprecated}
+message Old {}
+message New {}
+enum E : u8 {
+	A = 1
+	@{deprecated}
+	B = 2
+}
+`
+
+func compileWithDeprecatedDep(
+	t *testing.T,
+	src string,
+	opts ...compiler.CompileOption,
+) (errs []string, warnings []string) {
+	t.Helper()
+	dep := compileDep(t, "dep.idol", deprecatedDep)
+	deps, err := compiler.Merge([]schema_idl.Schema{dep})
+	testutil.AssertNoError(t, err)
+	parsed, err := syntax.Parse([]byte(src))
+	testutil.AssertNoError(t, err)
+	opts = append(opts, compiler.WithDependencies(deps))
+	result := compiler.Compile(parsed, opts...)
+	for _, err := range result.Errors {
+		errs = append(errs, err.Error())
+	}
+	for _, warning := range result.Warnings {
+		warnings = append(warnings, warning.String())
+	}
+	return errs, warnings
+}
+
+func TestDeprecated_References(t *testing.T) {
+	const (
+		warnOld = `W4012: 'Old' in namespace "dep" is deprecated`
+		warnEB  = `W4012: 'E.B' in namespace "dep" is deprecated`
+	)
+	tests := []struct {
+		src      string
+		warnings []string
+	}{
+		{"import \"dep\" { Old }\nmessage M {\n\ta @1 : Old\n}\n", []string{warnOld}},
+		{"import \"dep\" as d\nmessage M {\n\ta @1 : d.Old\n}\n", []string{warnOld}},
+		{"import \"dep\" { New }\nmessage M {\n\ta @1 : New\n}\n", nil},
+		{"import \"dep\" { E }\nconst C : E = .B\n", []string{warnEB}},
+		{"import \"dep\" { E }\nconst C : E = E.B\n", []string{warnEB}},
+		{"import \"dep\" { E }\nconst C : u8 = E.B\n", []string{warnEB}},
+		{"import \"dep\" { E }\nconst C : u8 = E.B + 1\n", []string{warnEB}},
+		{"import \"dep\" { E }\nconst C : E = .A\n", nil},
+		{"import \"dep\" { E }\nconst C : u8 = E.A\n", nil},
+		{"import \"dep\" { E }\nmessage M {\n\t@{default = .B}\n\ta @1 : E\n}\n", []string{warnEB}},
+		{"import \"dep\" { E }\nmessage M {\n\t@{default = E.B}\n\ta @1 : E\n}\n", []string{warnEB}},
+
+		// Deprecated local declarations may be used by the schema that
+		// deprecates them.
+		{"@{deprecated}\nmessage L {}\nmessage M {\n\ta @1 : L\n}\n", nil},
+		{"enum L : u8 {\n\t@{deprecated}\n\tB = 1\n}\nconst C : u8 = L.B\n", nil},
+	}
+	for _, test := range tests {
+		errs, warnings := compileWithDeprecatedDep(t, "namespace \"test\"\n"+test.src)
+		testutil.ExpectSliceEq(t, nil, errs)
+		testutil.ExpectSliceEq(t, test.warnings, warnings)
+	}
+}
+
+func TestDeprecated_Suppressed(t *testing.T) {
+	src := `namespace "test"
+import "dep" { E }
+import "dep" { Old }
+@{suppress_warnings = "W4012"}
+const C : u8 = E.B
+message M {
+	a @1 : Old
+}
+`
+	errs, warnings := compileWithDeprecatedDep(t, src)
+	testutil.ExpectSliceEq(t, nil, errs)
+	testutil.ExpectSliceEq(t, []string{
+		`W4012: 'Old' in namespace "dep" is deprecated`,
+	}, warnings)
+
+	errs, warnings = compileWithDeprecatedDep(t, src, compiler.WithSuppressedWarning(4012))
+	testutil.ExpectSliceEq(t, nil, errs)
+	testutil.ExpectSliceEq(t, nil, warnings)
+}
@@ -16,15 +16,144 @@
 package compiler
 
 import (
+	"fmt"
+	"iter"
 	"maps"
 	"slices"
+	"strings"
 
 	"go.idol-lang.org/idol/schema_idl"
 	"go.idol-lang.org/idol/syntax"
 )
 
 type SchemaSet struct {
-	decls map[string] /*namespace*/ map[string] /* decl name */ *mergedDecl
+	decls     map[string] /*namespace*/ map[string] /* decl name */ *mergedDecl
+	conflicts []*MergeConflict
+}
+
+// Conflicts returns the conflicting declarations found when the set was
+// merged, in the order they were found.
+func (s *SchemaSet) Conflicts() []*MergeConflict {
+	return s.conflicts
+}
+
+// A Decl is a top-level declaration in a SchemaSet.
+type Decl struct {
+	namespace string
+	name      string
+	decl      *mergedDecl
+}
+
+func (d *Decl) Namespace() string {
+	return d.namespace
+}
+
+func (d *Decl) Name() string {
+	return d.name
+}
+
+// TypeName returns the declaration's fully-qualified name, in the same format
+// as the TypeName() of a field with an imported type.
+func (d *Decl) TypeName() string {
+	return d.namespace + "\x1F" + d.name
+}
+
+func (d *Decl) Type() schema_idl.ExportType {
+	switch d.decl.type_ {
+	case declType_CONST:
+		return schema_idl.ExportType_CONST
+	case declType_ENUM:
+		return schema_idl.ExportType_ENUM
+	case declType_STRUCT:
+		return schema_idl.ExportType_STRUCT
+	case declType_MESSAGE:
+		return schema_idl.ExportType_MESSAGE
+	case declType_UNION:
+		return schema_idl.ExportType_UNION
+	case declType_PROTOCOL:
+		return schema_idl.ExportType_PROTOCOL
+	}
+	return schema_idl.ExportType_UNKNOWN
+}
+
+// Schema returns the schema containing the declaration. If identical
+// declarations were merged from several schemas, the first is returned.
+func (d *Decl) Schema() schema_idl.Schema {
+	return d.decl.source.schema
+}
+
+func (d *Decl) Const() (schema_idl.Const, bool) {
+	v, ok := d.decl.value.(schema_idl.Const)
+	return v, ok
+}
+
+func (d *Decl) Enum() (schema_idl.Enum, bool) {
+	v, ok := d.decl.value.(schema_idl.Enum)
+	return v, ok
+}
+
+func (d *Decl) Struct() (schema_idl.Struct, bool) {
+	v, ok := d.decl.value.(schema_idl.Struct)
+	return v, ok
+}
+
+func (d *Decl) Message() (schema_idl.Message, bool) {
+	v, ok := d.decl.value.(schema_idl.Message)
+	return v, ok
+}
+
+func (d *Decl) Union() (schema_idl.Union, bool) {
+	v, ok := d.decl.value.(schema_idl.Union)
+	return v, ok
+}
+
+func (d *Decl) Protocol() (schema_idl.Protocol, bool) {
+	v, ok := d.decl.value.(schema_idl.Protocol)
+	return v, ok
+}
+
+// Namespaces returns the namespaces of the merged schemas, in sorted order.
+func (s *SchemaSet) Namespaces() iter.Seq[string] {
+	return slices.Values(slices.Sorted(maps.Keys(s.decls)))
+}
+
+// Decls returns the declarations in a namespace, sorted by name. Names with
+// conflicting declarations are skipped.
+func (s *SchemaSet) Decls(namespace string) iter.Seq[*Decl] {
+	return func(yield func(*Decl) bool) {
+		decls := s.decls[namespace]
+		for _, name := range slices.Sorted(maps.Keys(decls)) {
+			decl := decls[name]
+			if decl.conflict {
+				continue
+			}
+			if !yield(&Decl{namespace: namespace, name: name, decl: decl}) {
+				return
+			}
+		}
+	}
+}
+
+// Lookup returns the declaration with the given namespace and name. It
+// returns false if there is no such declaration, or if the merged schemas
+// contain conflicting declarations of that name.
+func (s *SchemaSet) Lookup(namespace, name string) (*Decl, bool) {
+	decl, ok := s.decls[namespace][name]
+	if !ok || decl.conflict {
+		return nil, false
+	}
+	return &Decl{namespace: namespace, name: name, decl: decl}, true
+}
+
+// ResolveTypeName resolves the TypeName() of a field or other type reference
+// within a schema of the given namespace. Local type names are resolved
+// relative to that namespace, and imported type names ("<ns>\x1F<name>")
+// to their own namespace.
+func (s *SchemaSet) ResolveTypeName(namespace, typeName string) (*Decl, bool) {
+	if ns, name, ok := strings.Cut(typeName, "\x1F"); ok {
+		return s.Lookup(ns, name)
+	}
+	return s.Lookup(namespace, typeName)
 }
 
 func (s *SchemaSet) resolveExport(
@@ -164,11 +293,74 @@ func (s *SchemaSet) resolveConst(
 	return zero, errImportedNameNotConst(gotType, namespace, name, useSpan)
 }
 
+func (s *SchemaSet) structEdges(typeName string) []*structEdge {
+	namespace, name, _ := strings.Cut(typeName, "\x1F")
+	decl, ok := s.decls[namespace][name]
+	if !ok || decl.type_ != declType_STRUCT {
+		return nil
+	}
+	var edges []*structEdge
+	for _, field := range decl.value.(schema_idl.Struct).Fields().Iter() {
+		if field.Type() != schema_idl.Type_STRUCT {
+			continue
+		}
+		fieldTypeName := field.TypeName()
+		if !strings.Contains(fieldTypeName, "\x1F") {
+			fieldTypeName = namespace + "\x1F" + fieldTypeName
+		}
+		edges = append(edges, &structEdge{
+			structName: typeName,
+			fieldName:  field.Name(),
+			typeName:   fieldTypeName,
+		})
+	}
+	return edges
+}
+
 type mergedDecl struct {
 	type_    declType
 	enumType schema_idl.Type
 	value    interface{}
 	conflict bool
+	source   *mergeSource
+}
+
+type mergeSource struct {
+	schema schema_idl.Schema
+	name   string
+}
+
+func newMergeSource(schema schema_idl.Schema, idx int) *mergeSource {
+	var parts []string
+	for _, part := range schema.SourcePath().Iter() {
+		parts = append(parts, part)
+	}
+	name := strings.Join(parts, "/")
+	if name == "" {
+		name = fmt.Sprintf("schemas[%d]", idx)
+	}
+	return &mergeSource{
+		schema: schema,
+		name:   name,
+	}
+}
+
+func (t declType) String() string {
+	switch t {
+	case declType_CONST:
+		return "const"
+	case declType_ENUM:
+		return "enum"
+	case declType_STRUCT:
+		return "struct"
+	case declType_MESSAGE:
+		return "message"
+	case declType_UNION:
+		return "union"
+	case declType_PROTOCOL:
+		return "protocol"
+	}
+	return "unknown"
 }
 
 func canUnifyMergedDecls(a, b *mergedDecl) bool {
@@ -201,12 +393,27 @@ func canUnifyMergedDecls(a, b *mergedDecl) bool {
 	}
 }
 
+// Merge combines compiled schemas into a SchemaSet. Schemas with the same
+// namespace are merged, and identical declarations are deduplicated.
+//
+// If two schemas contain different declarations with the same name, the
+// name can't be resolved in the SchemaSet, and compiling a schema that
+// imports it is an error. Conflicts are available from Conflicts().
 func Merge(schemas []schema_idl.Schema) (*SchemaSet, error) {
-	set := func(decls map[string]*mergedDecl, k string, v *mergedDecl) {
+	var conflicts []*MergeConflict
+	set := func(ns string, decls map[string]*mergedDecl, k string, v *mergedDecl) {
 		if prev, conflict := decls[k]; conflict {
 			if !canUnifyMergedDecls(v, prev) {
+				conflicts = append(conflicts, &MergeConflict{
+					namespace: ns,
+					name:      k,
+					first:     prev,
+					second:    v,
+				})
 				decls[k] = &mergedDecl{
+					type_:    prev.type_,
 					conflict: true,
+					source:   prev.source,
 				}
 			}
 			return
@@ -215,58 +422,57 @@ func Merge(schemas []schema_idl.Schema) (*SchemaSet, error) {
 	}
 
 	declsByNs := make(map[string]map[string]*mergedDecl)
-	for _, schema := range schemas {
+	for idx, schema := range schemas {
+		ns := schema.Namespace()
+		source := newMergeSource(schema, idx)
 		decls := make(map[string]*mergedDecl)
 		for _, const_ := range schema.Consts().Iter() {
-			set(decls, const_.Name(), &mergedDecl{
-				type_: declType_CONST,
-				value: const_,
+			set(ns, decls, const_.Name(), &mergedDecl{
+				type_:  declType_CONST,
+				value:  const_,
+				source: source,
 			})
 		}
 		for _, enum := range schema.Enums().Iter() {
-			set(decls, enum.Name(), &mergedDecl{
+			set(ns, decls, enum.Name(), &mergedDecl{
 				type_:    declType_ENUM,
 				enumType: enum.Type(),
 				value:    enum,
+				source:   source,
 			})
 		}
 		for _, struct_ := range schema.Structs().Iter() {
-			set(decls, struct_.Name(), &mergedDecl{
-				type_: declType_STRUCT,
-				value: struct_,
+			set(ns, decls, struct_.Name(), &mergedDecl{
+				type_:  declType_STRUCT,
+				value:  struct_,
+				source: source,
 			})
 		}
 		for _, message := range schema.Messages().Iter() {
-			set(decls, message.Name(), &mergedDecl{
-				type_: declType_MESSAGE,
-				value: message,
+			set(ns, decls, message.Name(), &mergedDecl{
+				type_:  declType_MESSAGE,
+				value:  message,
+				source: source,
 			})
 		}
 		for _, union := range schema.Unions().Iter() {
-			set(decls, union.Name(), &mergedDecl{
-				type_: declType_UNION,
-				value: union,
+			set(ns, decls, union.Name(), &mergedDecl{
+				type_:  declType_UNION,
+				value:  union,
+				source: source,
 			})
 		}
 		for _, protocol := range schema.Protocols().Iter() {
-			set(decls, protocol.Name(), &mergedDecl{
-				type_: declType_PROTOCOL,
-				value: protocol,
+			set(ns, decls, protocol.Name(), &mergedDecl{
+				type_:  declType_PROTOCOL,
+				value:  protocol,
+				source: source,
 			})
 		}
 
-		ns := schema.Namespace()
 		if prevDecls, ok := declsByNs[ns]; ok {
 			for _, name := range slices.Sorted(maps.Keys(decls)) {
-				if prev, conflict := prevDecls[name]; conflict {
-					if !canUnifyMergedDecls(decls[name], prev) {
-						prevDecls[name] = &mergedDecl{
-							conflict: true,
-						}
-					}
-				} else {
-					prevDecls[name] = decls[name]
-				}
+				set(ns, prevDecls, name, decls[name])
 			}
 		} else {
 			declsByNs[ns] = decls
@@ -274,6 +480,44 @@ func Merge(schemas []schema_idl.Schema) (*SchemaSet, error) {
 	}
 
 	return &SchemaSet{
-		decls: declsByNs,
+		decls:     declsByNs,
+		conflicts: conflicts,
 	}, nil
 }
+
+// A MergeConflict describes two different declarations of the same name
+// within a namespace.
+type MergeConflict struct {
+	namespace string
+	name      string
+	first     *mergedDecl
+	second    *mergedDecl
+}
+
+func (c *MergeConflict) String() string {
+	return fmt.Sprintf(
+		"Conflicting definitions of '%s' in namespace \"%s\": %s in %s, %s in %s",
+		c.name, c.namespace,
+		c.first.type_, c.first.source.name,
+		c.second.type_, c.second.source.name,
+	)
+}
+
+func (c *MergeConflict) Namespace() string {
+	return c.namespace
+}
+
+func (c *MergeConflict) Name() string {
+	return c.name
+}
+
+// Sources returns the names of the two conflicting schemas, taken from their
+// source paths.
+func (c *MergeConflict) Sources() (string, string) {
+	return c.first.source.name, c.second.source.name
+}
+
+// Schemas returns the two conflicting schemas.
+func (c *MergeConflict) Schemas() (schema_idl.Schema, schema_idl.Schema) {
+	return c.first.source.schema, c.second.source.schema
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"slices"
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+func compileDep(
+	t *testing.T,
+	path, src string,
+	deps ...schema_idl.Schema,
+) schema_idl.Schema {
+	t.Helper()
+	parsed, err := syntax.Parse([]byte(src))
+	testutil.AssertNoError(t, err)
+	opts := []compiler.CompileOption{compiler.WithSourcePath([]string{path})}
+	if len(deps) > 0 {
+		mergedDeps, err := compiler.Merge(deps)
+		testutil.AssertNoError(t, err)
+		opts = append(opts, compiler.WithDependencies(mergedDeps))
+	}
+	result := compiler.Compile(parsed, opts...)
+	for _, err := range result.Errors {
+		t.Fatal(err)
+	}
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+	return schema
+}
+
+func TestSchemaSet_Query(t *testing.T) {
+	a := compileDep(t, "a.idol", `namespace "test/a"
+enum E : u8 {
+	X = 1
+}
+message M {
+	e @1 : E
+}
+`)
+	b := compileDep(t, "b.idol", `namespace "test/b"
+import "test/a" { M }
+message N {
+	m @1 : M
+}
+`, a)
+	set, err := compiler.Merge([]schema_idl.Schema{a, b})
+	testutil.AssertNoError(t, err)
+
+	testutil.ExpectSliceEq(t, []string{"test/a", "test/b"}, slices.Collect(set.Namespaces()))
+
+	var names []string
+	for decl := range set.Decls("test/a") {
+		names = append(names, decl.Name())
+	}
+	testutil.ExpectSliceEq(t, []string{"E", "M"}, names)
+
+	n, ok := set.Lookup("test/b", "N")
+	if !ok {
+		t.Fatal("Lookup(\"test/b\", \"N\") failed")
+	}
+	msg, ok := n.Message()
+	if !ok {
+		t.Fatalf("decl %q is not a message", n.Name())
+	}
+	field, _ := msg.Fields().Get(0)
+	m, ok := set.ResolveTypeName(n.Namespace(), field.TypeName())
+	if !ok {
+		t.Fatalf("ResolveTypeName(%q) failed", field.TypeName())
+	}
+	testutil.ExpectEq(t, "test/a", m.Namespace())
+	testutil.ExpectEq(t, "M", m.Name())
+
+	msg, _ = m.Message()
+	field, _ = msg.Fields().Get(0)
+	e, ok := set.ResolveTypeName(m.Namespace(), field.TypeName())
+	if !ok {
+		t.Fatalf("ResolveTypeName(%q) failed", field.TypeName())
+	}
+	testutil.ExpectEq(t, schema_idl.ExportType_ENUM, e.Type())
+
+	if _, ok := set.Lookup("test/a", "Missing"); ok {
+		t.Error("Lookup of missing declaration succeeded")
+	}
+}
+
+func TestMerge_Conflict(t *testing.T) {
+	prev := compileDep(t, "prev.idol", `namespace "test"
+message M {
+	a @1 : u32
+}
+message Same {
+	a @1 : u32
+}
+`)
+	next := compileDep(t, "next.idol", `namespace "test"
+enum M : u8 {
+	A = 1
+}
+message Same {
+	a @1 : u32
+}
+`)
+	set, err := compiler.Merge([]schema_idl.Schema{prev, next})
+	testutil.AssertNoError(t, err)
+	conflicts := set.Conflicts()
+	testutil.ExpectEq(t, 1, len(conflicts))
+	testutil.ExpectEq(t,
+		`Conflicting definitions of 'M' in namespace "test": message in prev.idol, enum in next.idol`,
+		conflicts[0].String(),
+	)
+	first, second := conflicts[0].Sources()
+	testutil.ExpectEq(t, "prev.idol", first)
+	testutil.ExpectEq(t, "next.idol", second)
+
+	if _, ok := set.Lookup("test", "M"); ok {
+		t.Error("Lookup of conflicting declaration succeeded")
+	}
+	if _, ok := set.Lookup("test", "Same"); !ok {
+		t.Error("Lookup of identical declaration failed")
+	}
+}
+
+func TestCompile_ConflictingDep(t *testing.T) {
+	depA := compileDep(t, "a.idol", `namespace "dep"
+message X {
+	a @1 : u32
+}
+message Y {
+	a @1 : u32
+}
+`)
+	depB := compileDep(t, "b.idol", `namespace "dep"
+enum X : u8 {
+	A = 1
+}
+`)
+	deps, err := compiler.Merge([]schema_idl.Schema{depA, depB})
+	testutil.AssertNoError(t, err)
+	testutil.ExpectEq(t, 1, len(deps.Conflicts()))
+
+	compile := func(src string) [][]string {
+		t.Helper()
+		parsed, err := syntax.Parse([]byte(src))
+		testutil.AssertNoError(t, err)
+		result := compiler.Compile(parsed, compiler.WithDependencies(deps))
+		var errs [][]string
+		for _, err := range result.Errors {
+			errs = append(errs, fmtDiagnostic(src, err))
+		}
+		return errs
+	}
+
+	// A conflicting name that isn't imported doesn't prevent compilation.
+	errs := compile(`namespace "test"
+import "dep" { Y }
+message M {
+	y @1 : Y
+}
+`)
+	testutil.ExpectEq(t, 0, len(errs))
+
+	// Importing a conflicting name is reported at the import.
+	errs = compile(`namespace "test"
+import "dep" { X }
+message M {
+	x @1 : X
+}
+`)
+	testutil.ExpectEq(t, 1, len(errs))
+	testutil.ExpectSliceEq(t, []string{
+		`E3006: Name 'X' imported from namespace "dep" has conflicting definitions [2:16 X]`,
+	}, errs[0])
+}
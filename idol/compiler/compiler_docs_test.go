@@ -0,0 +1,95 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"testing"
+
+	"go.idol-lang.org/idol/internal/testutil"
+)
+
+func TestDocComments(t *testing.T) {
+	schema := compileDep(t, "docs.idol", `namespace "test"
+## Doc for First.
+## Second line.
+message First {
+	a @1 : u8 ## Trailing comment on a.
+	b @2 : u8
+	## Doc for c.
+	c @3 : u8
+}
+
+## Detached by a blank line.
+
+message Detached {
+	## Detached by a blank line.
+
+	a @1 : u8
+}
+
+# Not a doc comment.
+## Doc for S.
+struct S {
+	## Doc for S.a.
+	a : u8
+}
+
+## Doc for U.
+union U {
+	## Doc for U.a.
+	a @1 : u8
+}
+
+## Doc for P.
+protocol P {
+	## Doc for P.Get.
+	rpc Get(First) : First
+	## Doc for P.Changed.
+	event Changed : First
+}
+`)
+
+	first, _ := schema.Messages().Get(0)
+	testutil.ExpectEq(t, "Doc for First.\nSecond line.", first.Doc())
+	a, _ := first.Fields().Get(0)
+	testutil.ExpectEq(t, "", a.Doc())
+	b, _ := first.Fields().Get(1)
+	testutil.ExpectEq(t, "", b.Doc())
+	c, _ := first.Fields().Get(2)
+	testutil.ExpectEq(t, "Doc for c.", c.Doc())
+
+	detached, _ := schema.Messages().Get(1)
+	testutil.ExpectEq(t, "", detached.Doc())
+	a, _ = detached.Fields().Get(0)
+	testutil.ExpectEq(t, "", a.Doc())
+
+	s, _ := schema.Structs().Get(0)
+	testutil.ExpectEq(t, "Doc for S.", s.Doc())
+	sField, _ := s.Fields().Get(0)
+	testutil.ExpectEq(t, "Doc for S.a.", sField.Doc())
+
+	u, _ := schema.Unions().Get(0)
+	testutil.ExpectEq(t, "Doc for U.", u.Doc())
+	uField, _ := u.Fields().Get(0)
+	testutil.ExpectEq(t, "Doc for U.a.", uField.Doc())
+
+	p, _ := schema.Protocols().Get(0)
+	testutil.ExpectEq(t, "Doc for P.", p.Doc())
+	rpc, _ := p.Rpcs().Get(0)
+	testutil.ExpectEq(t, "Doc for P.Get.", rpc.Doc())
+	event, _ := p.Events().Get(0)
+	testutil.ExpectEq(t, "Doc for P.Changed.", event.Doc())
+}
@@ -19,6 +19,7 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"math/big"
 	"strings"
 
 	"go.idol-lang.org/idol/schema_idl"
@@ -29,6 +30,7 @@ type Error struct {
 	code    uint32
 	message string
 	span    syntax.Span
+	notes   []*ErrorNote
 }
 
 var _ error = (*Error)(nil)
@@ -49,6 +51,23 @@ func (err *Error) Span() syntax.Span {
 	return err.span
 }
 
+func (err *Error) Notes() []*ErrorNote {
+	return err.notes
+}
+
+type ErrorNote struct {
+	message string
+	span    syntax.Span
+}
+
+func (note *ErrorNote) Message() string {
+	return note.message
+}
+
+func (note *ErrorNote) Span() syntax.Span {
+	return note.span
+}
+
 func errInvalidNamespace(namespace string, span syntax.Span) error {
 	return &Error{
 		code:    3000,
@@ -307,7 +326,7 @@ func errValueOutOfRange(type_ schema_idl.Type, node *syntax.IntLit) error {
 	return errValueOutOfRange2(type_, value, node.Span())
 }
 
-func errValueOutOfRange2[V int64 | uint64](
+func errValueOutOfRange2[V int64 | uint64 | *big.Int](
 	type_ schema_idl.Type,
 	value V,
 	valueSpan syntax.Span,
@@ -562,6 +581,8 @@ func errFieldTagOutOfRange(field syntax.Node, tagNode *syntax.Tag) error {
 		typeName = "message"
 	case *syntax.UnionField:
 		typeName = "union"
+	case *syntax.Reserved:
+		typeName = "reserved"
 	default:
 		panic("unreachable")
 	}
@@ -677,6 +698,154 @@ func errProtocolTagOutOfRange(tagNode *syntax.Tag) error {
 	}
 }
 
+func errStructRecursive(cycle []*structEdge, entry *structEdge) error {
+	fmtName := func(typeName string) string {
+		if namespace, local, ok := strings.Cut(typeName, "\x1F"); ok {
+			return fmt.Sprintf("%q.%s", namespace, local)
+		}
+		return typeName
+	}
+
+	var path strings.Builder
+	path.WriteString(fmtName(cycle[0].structName))
+	var notes []*ErrorNote
+	for _, edge := range cycle {
+		fmt.Fprintf(&path, ".%s -> %s", edge.fieldName, fmtName(edge.typeName))
+		if edge.node == nil {
+			continue
+		}
+		notes = append(notes, &ErrorNote{
+			message: fmt.Sprintf(
+				"Field '%s.%s' contains struct '%s'",
+				edge.structName, edge.fieldName, fmtName(edge.typeName),
+			),
+			span: edge.node.FieldType().Span(),
+		})
+	}
+
+	if len(notes) == 0 && entry != nil {
+		notes = append(notes, &ErrorNote{
+			message: fmt.Sprintf(
+				"Field '%s.%s' contains recursive struct '%s'",
+				entry.structName, entry.fieldName, fmtName(entry.typeName),
+			),
+			span: entry.node.FieldType().Span(),
+		})
+	}
+
+	var span syntax.Span
+	if len(notes) > 0 {
+		span = notes[0].span
+	}
+	return &Error{
+		code: 3033,
+		message: fmt.Sprintf(
+			"Struct '%s' is recursive (%s)",
+			fmtName(cycle[0].structName), path.String(),
+		),
+		span:  span,
+		notes: notes,
+	}
+}
+
+func errDeclCycle(cycle []*declInfo) error {
+	var path strings.Builder
+	var notes []*ErrorNote
+	for ii, decl := range cycle {
+		name := decl.node.Name()
+		if ii > 0 {
+			path.WriteString(" -> ")
+			notes = append(notes, &ErrorNote{
+				message: fmt.Sprintf(
+					"'%s' depends on '%s'",
+					cycle[ii-1].node.Name().Get(), name.Get(),
+				),
+				span: name.Span(),
+			})
+		}
+		path.WriteString(name.Get())
+	}
+	first := cycle[0].node.Name()
+	fmt.Fprintf(&path, " -> %s", first.Get())
+	return &Error{
+		code: 3034,
+		message: fmt.Sprintf(
+			"Declaration '%s' depends on itself (%s)",
+			first.Get(), path.String(),
+		),
+		span:  first.Span(),
+		notes: notes,
+	}
+}
+
+func errExprOperandNotInt(node syntax.Node) error {
+	return &Error{
+		code:    3035,
+		message: "Operand of constant expression must be an integer",
+		span:    node.Span(),
+	}
+}
+
+func errExprDivideByZero(node *syntax.BinaryExpr) error {
+	return &Error{
+		code:    3036,
+		message: "Division by zero in constant expression",
+		span:    node.Span(),
+	}
+}
+
+func errExprShiftOutOfRange(node *syntax.BinaryExpr, count *big.Int) error {
+	return &Error{
+		code: 3037,
+		message: fmt.Sprintf(
+			"Shift count %d out of range [0, 64]", count,
+		),
+		span: node.Right().Span(),
+	}
+}
+
+func errExprEnumRefWithoutEnum(node *syntax.EnumRef) error {
+	return &Error{
+		code: 3038,
+		message: fmt.Sprintf(
+			"Enum item reference '.%s' used outside of an enum-typed value",
+			node.Name().Get(),
+		),
+		span: node.Span(),
+	}
+}
+
+func errBytesArray(fieldType *syntax.FieldType) error {
+	return &Error{
+		code:    3039,
+		message: "Type 'bytes' can't be used as an array element type",
+		span:    fieldType.Span(),
+	}
+}
+
+func errImportCycle(cycle []string, span syntax.Span) error {
+	quoted := make([]string, len(cycle))
+	for ii, namespace := range cycle {
+		quoted[ii] = fmt.Sprintf("%q", namespace)
+	}
+	return &Error{
+		code: 3040,
+		message: fmt.Sprintf(
+			"Import cycle detected (%s)", strings.Join(quoted, " -> "),
+		),
+		span: span,
+	}
+}
+
+func errLoadedNamespaceMismatch(want, got string) error {
+	return &Error{
+		code: 3041,
+		message: fmt.Sprintf(
+			"Schema for namespace %q declares namespace %q", want, got,
+		),
+	}
+}
+
 func errOptionsSchemaMustBeMessage(
 	name *syntax.TypeName,
 	gotType schema_idl.Type,
@@ -752,13 +921,6 @@ func errStructFieldUnsizedArray() error {
 	}
 }
 
-func errArrayLenNotU32() error {
-	return &Error{
-		code:    0x30000,
-		message: "errArrayLenNotU32",
-	}
-}
-
 func errArrayLenZero() error {
 	return &Error{
 		code:    0x30000,
@@ -815,9 +977,162 @@ func errImportedConstantCorrupt() error {
 	}
 }
 
-func errEnumRefNotFound() error {
+var errPoisoned = &Error{
+	code:    0x30000,
+	message: "errPoisoned",
+}
+
+func errSuppressWarningsValue(option syntax.Node) error {
 	return &Error{
-		code:    0x30000,
-		message: "errEnumRefNotFound",
+		code:    3042,
+		message: "Option 'suppress_warnings' must be a text listing warning codes",
+		span:    option.Span(),
+	}
+}
+
+func errInvalidWarningCode(code string, node *syntax.TextLit) error {
+	return &Error{
+		code:    3043,
+		message: fmt.Sprintf("Invalid warning code %q", code),
+		span:    node.Span(),
+	}
+}
+
+func errFieldTagReserved(
+	tag uint16,
+	field interface {
+		syntax.Node
+		Name() *syntax.Ident
+		Tag() *syntax.Tag
+	},
+	reserved *syntax.Tag,
+) error {
+	return &Error{
+		code: 3044,
+		message: fmt.Sprintf(
+			"Field '%s' uses reserved tag @%d",
+			field.Name().Get(),
+			tag,
+		),
+		span: field.Tag().Span(),
+		notes: []*ErrorNote{{
+			message: fmt.Sprintf("Tag @%d reserved here", tag),
+			span:    reserved.Span(),
+		}},
+	}
+}
+
+func errFieldNameReserved(
+	recordType string,
+	field interface {
+		syntax.Node
+		Name() *syntax.Ident
+	},
+	reserved *syntax.Ident,
+) error {
+	return &Error{
+		code: 3045,
+		message: fmt.Sprintf(
+			"%s field name '%s' is reserved",
+			recordType,
+			field.Name().Get(),
+		),
+		span: field.Name().Span(),
+		notes: []*ErrorNote{{
+			message: fmt.Sprintf("Name '%s' reserved here", reserved.Get()),
+			span:    reserved.Span(),
+		}},
+	}
+}
+
+func errEnumItemValueReserved(
+	enumType schema_idl.Type,
+	value uint64,
+	name string,
+	valueNode syntax.Node,
+	reserved *syntax.IntLit,
+) error {
+	valueStr := fmt.Sprintf("%d", value)
+	switch enumType {
+	case schema_idl.Type_I8:
+		valueStr = fmt.Sprintf("%d", int8(value))
+	case schema_idl.Type_I16:
+		valueStr = fmt.Sprintf("%d", int16(value))
+	case schema_idl.Type_I32:
+		valueStr = fmt.Sprintf("%d", int32(value))
+	case schema_idl.Type_I64:
+		valueStr = fmt.Sprintf("%d", int64(value))
+	}
+	return &Error{
+		code: 3046,
+		message: fmt.Sprintf(
+			"Enum item '%s' uses reserved value %s",
+			name, valueStr,
+		),
+		span: valueNode.Span(),
+		notes: []*ErrorNote{{
+			message: fmt.Sprintf("Value %s reserved here", valueStr),
+			span:    reserved.Span(),
+		}},
+	}
+}
+
+func errEnumItemNameReserved(name, reserved *syntax.Ident) error {
+	return &Error{
+		code:    3047,
+		message: fmt.Sprintf("Enum item name '%s' is reserved", name.Get()),
+		span:    name.Span(),
+		notes: []*ErrorNote{{
+			message: fmt.Sprintf("Name '%s' reserved here", reserved.Get()),
+			span:    reserved.Span(),
+		}},
+	}
+}
+
+func errDefaultValueNotSupported(
+	option syntax.Node,
+	field *syntax.MessageField,
+) error {
+	var typeBuf bytes.Buffer
+	field.FieldType().UnparseTo(&typeBuf)
+	return &Error{
+		code: 3048,
+		message: fmt.Sprintf(
+			"Field '%s' of type '%s' can't have a default value",
+			field.Name().Get(), typeBuf.String(),
+		),
+		span: option.Span(),
+	}
+}
+
+func errOptionValueRequired(name string, option syntax.Node) error {
+	return &Error{
+		code:    3049,
+		message: fmt.Sprintf("Option '%s' requires a value", name),
+		span:    option.Span(),
+	}
+}
+
+func errEnumItemNotFound(enumName, itemName string, span syntax.Span) error {
+	return &Error{
+		code:    3050,
+		message: fmt.Sprintf("Enum '%s' has no item '%s'", enumName, itemName),
+		span:    span,
+	}
+}
+
+func errArrayLenOutOfRange(node syntax.Node) error {
+	return &Error{
+		code:    3051,
+		message: "Array length must be a u32",
+		span:    node.Span(),
+	}
+}
+
+func errStructFieldBytes(fieldType *syntax.FieldType) error {
+	return &Error{
+		code:    3052,
+		message: "Type 'bytes' can't be used in a struct field (struct fields must have a fixed size)",
+		span:    fieldType.Span(),
 	}
 }
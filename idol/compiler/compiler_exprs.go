@@ -0,0 +1,429 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"strings"
+
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+// exprScope is the context in which a constant expression is evaluated.
+type exprScope struct {
+	// valueType is the integer type of the expression's result.
+	valueType schema_idl.Type
+
+	// enumName and enumValues are set when the result is an enum value, and
+	// are used to resolve `.NAME` enum item references.
+	enumName   string
+	enumValues map[string]uint64
+}
+
+func isConstExpr(node syntax.Node) bool {
+	switch node.(type) {
+	case *syntax.BinaryExpr, *syntax.UnaryExpr, *syntax.ParenExpr:
+		return true
+	}
+	return false
+}
+
+func isSignedType(type_ schema_idl.Type) bool {
+	switch type_ {
+	case schema_idl.Type_I8:
+	case schema_idl.Type_I16:
+	case schema_idl.Type_I32:
+	case schema_idl.Type_I64:
+	default:
+		return false
+	}
+	return true
+}
+
+// unsignedTypeMask returns a mask of the bits in an unsigned integer type.
+func unsignedTypeMask(type_ schema_idl.Type) (*big.Int, bool) {
+	var width uint
+	switch type_ {
+	case schema_idl.Type_U8:
+		width = 8
+	case schema_idl.Type_U16:
+		width = 16
+	case schema_idl.Type_U32:
+		width = 32
+	case schema_idl.Type_U64:
+		width = 64
+	default:
+		return nil, false
+	}
+	mask := new(big.Int).Lsh(big.NewInt(1), width)
+	return mask.Sub(mask, big.NewInt(1)), true
+}
+
+func intTypeBounds(type_ schema_idl.Type) (*big.Int, *big.Int, bool) {
+	switch type_ {
+	case schema_idl.Type_U8:
+		return big.NewInt(0), big.NewInt(math.MaxUint8), true
+	case schema_idl.Type_I8:
+		return big.NewInt(math.MinInt8), big.NewInt(math.MaxInt8), true
+	case schema_idl.Type_U16:
+		return big.NewInt(0), big.NewInt(math.MaxUint16), true
+	case schema_idl.Type_I16:
+		return big.NewInt(math.MinInt16), big.NewInt(math.MaxInt16), true
+	case schema_idl.Type_U32:
+		return big.NewInt(0), big.NewInt(math.MaxUint32), true
+	case schema_idl.Type_I32:
+		return big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32), true
+	case schema_idl.Type_U64:
+		return big.NewInt(0), new(big.Int).SetUint64(math.MaxUint64), true
+	case schema_idl.Type_I64:
+		return big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64), true
+	case schema_idl.Type_F32:
+		return big.NewInt(-maxFloat32), big.NewInt(maxFloat32), true
+	case schema_idl.Type_F64:
+		return big.NewInt(-maxFloat64), big.NewInt(maxFloat64), true
+	}
+	return nil, nil, false
+}
+
+func bigFromBits(type_ schema_idl.Type, bits uint64) *big.Int {
+	if isSignedType(type_) {
+		switch type_ {
+		case schema_idl.Type_I8:
+			return big.NewInt(int64(int8(bits)))
+		case schema_idl.Type_I16:
+			return big.NewInt(int64(int16(bits)))
+		case schema_idl.Type_I32:
+			return big.NewInt(int64(int32(bits)))
+		}
+		return big.NewInt(int64(bits))
+	}
+	return new(big.Int).SetUint64(bits)
+}
+
+// evalConstExprBits evaluates an integer constant expression and checks it
+// against the range of its value type. The result is the value's
+// two's-complement bit pattern truncated to the width of the value type, as
+// stored in enum items.
+func (c *compiler) evalConstExprBits(
+	node syntax.Node,
+	scope *exprScope,
+) (uint64, error) {
+	value, err := c.evalConstExpr(node, scope)
+	if err != nil {
+		return 0, err
+	}
+	type_ := scope.valueType
+	valueMin, valueMax, ok := intTypeBounds(type_)
+	if !ok {
+		panic("unreachable")
+	}
+	if value.Cmp(valueMin) < 0 || value.Cmp(valueMax) > 0 {
+		return 0, errValueOutOfRange2(type_, value, node.Span())
+	}
+	bits := value.Uint64()
+	if value.Sign() < 0 {
+		bits = uint64(value.Int64())
+	}
+	switch type_ {
+	case schema_idl.Type_I8:
+		bits = uint64(uint8(bits))
+	case schema_idl.Type_I16:
+		bits = uint64(uint16(bits))
+	case schema_idl.Type_I32:
+		bits = uint64(uint32(bits))
+	}
+	return bits, nil
+}
+
+func (c *compiler) compileConstExprValue(
+	dst syntax.Node,
+	valueType *typeInfo,
+	valueNode syntax.Node,
+) ([]byte, error) {
+	scope := &exprScope{valueType: valueType.type_}
+	if valueType.isEnum() {
+		_, enumName, ok := strings.Cut(valueType.typeName, "\x1F")
+		if !ok {
+			enumName = valueType.typeName
+		}
+		scope.enumName = enumName
+		scope.enumValues = c.enumValues(valueType)
+	}
+
+	switch valueType.type_ {
+	case schema_idl.Type_U8, schema_idl.Type_I8:
+		value, err := c.evalConstExprBits(valueNode, scope)
+		if err != nil {
+			return nil, err
+		}
+		return []uint8{uint8(value)}, nil
+	case schema_idl.Type_U16, schema_idl.Type_I16:
+		value, err := c.evalConstExprBits(valueNode, scope)
+		if err != nil {
+			return nil, err
+		}
+		tmp := make([]uint8, 2)
+		binary.LittleEndian.PutUint16(tmp, uint16(value))
+		return tmp, nil
+	case schema_idl.Type_U32, schema_idl.Type_I32:
+		value, err := c.evalConstExprBits(valueNode, scope)
+		if err != nil {
+			return nil, err
+		}
+		tmp := make([]uint8, 4)
+		binary.LittleEndian.PutUint32(tmp, uint32(value))
+		return tmp, nil
+	case schema_idl.Type_U64, schema_idl.Type_I64:
+		value, err := c.evalConstExprBits(valueNode, scope)
+		if err != nil {
+			return nil, err
+		}
+		tmp := make([]uint8, 8)
+		binary.LittleEndian.PutUint64(tmp, value)
+		return tmp, nil
+	case schema_idl.Type_F32:
+		value, err := c.evalConstExprBits(valueNode, scope)
+		if err != nil {
+			return nil, err
+		}
+		tmp := make([]uint8, 4)
+		binary.LittleEndian.PutUint32(tmp, math.Float32bits(float32(int64(value))))
+		return tmp, nil
+	case schema_idl.Type_F64:
+		value, err := c.evalConstExprBits(valueNode, scope)
+		if err != nil {
+			return nil, err
+		}
+		tmp := make([]uint8, 8)
+		binary.LittleEndian.PutUint64(tmp, math.Float64bits(float64(int64(value))))
+		return tmp, nil
+	default:
+		return nil, errValueTypeMismatch(dst, valueType, valueNode)
+	}
+}
+
+func (c *compiler) evalConstExpr(
+	node syntax.Node,
+	scope *exprScope,
+) (*big.Int, error) {
+	switch node := node.(type) {
+	case *syntax.IntLit:
+		if value, ok := node.GetUint64(); ok {
+			return new(big.Int).SetUint64(value), nil
+		}
+		value, _ := node.GetInt64()
+		return big.NewInt(value), nil
+	case *syntax.ParenExpr:
+		return c.evalConstExpr(node.Inner(), scope)
+	case *syntax.UnaryExpr:
+		operand, err := c.evalConstExpr(node.Operand(), scope)
+		if err != nil {
+			return nil, err
+		}
+		switch node.Operator().Get() {
+		case "-":
+			return operand.Neg(operand), nil
+		case "~":
+			// Constant expressions are evaluated with arbitrary precision,
+			// so the complement of an unsigned value is truncated to the
+			// width of its type (`~0` is 0xFF for a u8).
+			result := operand.Not(operand)
+			if mask, ok := unsignedTypeMask(scope.valueType); ok {
+				result.And(result, mask)
+			}
+			return result, nil
+		}
+		panic("unreachable")
+	case *syntax.BinaryExpr:
+		return c.evalBinaryExpr(node, scope)
+	case *syntax.EnumRef:
+		name := node.Name().Get()
+		if scope.enumValues == nil {
+			return nil, errExprEnumRefWithoutEnum(node)
+		}
+		value, ok := scope.enumValues[name]
+		if !ok {
+			return nil, errEnumItemNotFound(scope.enumName, name, node.Span())
+		}
+		return bigFromBits(scope.valueType, value), nil
+	case *syntax.ValueName:
+		if enumType, ok := c.resolveEnumItemScope(node); ok {
+			values := c.enumValues(enumType)
+			if values == nil {
+				return nil, errPoisoned
+			}
+			value, ok := values[node.Name().Get()]
+			if !ok {
+				return nil, errEnumItemNotFound(
+					node.Scope().Get(),
+					node.Name().Get(),
+					node.Span(),
+				)
+			}
+			c.checkDeprecatedEnumItem(enumType, node.Name().Get(), node)
+			return bigFromBits(enumType.type_, value), nil
+		}
+		const_, err := c.resolveConst(node)
+		if err != nil {
+			return nil, err
+		}
+		return constIntValue(const_, node)
+	default:
+		return nil, errExprOperandNotInt(node)
+	}
+}
+
+func (c *compiler) evalBinaryExpr(
+	node *syntax.BinaryExpr,
+	scope *exprScope,
+) (*big.Int, error) {
+	left, err := c.evalConstExpr(node.Left(), scope)
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.evalConstExpr(node.Right(), scope)
+	if err != nil {
+		return nil, err
+	}
+	switch node.Operator().Get() {
+	case "+":
+		return left.Add(left, right), nil
+	case "-":
+		return left.Sub(left, right), nil
+	case "*":
+		return left.Mul(left, right), nil
+	case "/":
+		if right.Sign() == 0 {
+			return nil, errExprDivideByZero(node)
+		}
+		return left.Quo(left, right), nil
+	case "%":
+		if right.Sign() == 0 {
+			return nil, errExprDivideByZero(node)
+		}
+		return left.Rem(left, right), nil
+	case "<<", ">>":
+		if !right.IsUint64() || right.Uint64() > 64 {
+			return nil, errExprShiftOutOfRange(node, right)
+		}
+		if node.Operator().Get() == "<<" {
+			return left.Lsh(left, uint(right.Uint64())), nil
+		}
+		return left.Rsh(left, uint(right.Uint64())), nil
+	case "|":
+		return left.Or(left, right), nil
+	case "&":
+		return left.And(left, right), nil
+	}
+	panic("unreachable")
+}
+
+func constIntValue(const_ *constInfo, node syntax.Node) (*big.Int, error) {
+	v := const_.value
+	var bits uint64
+	switch const_.type_ {
+	case schema_idl.Type_U8, schema_idl.Type_I8:
+		if len(v) != 1 {
+			return nil, errImportedConstantCorrupt()
+		}
+		bits = uint64(v[0])
+	case schema_idl.Type_U16, schema_idl.Type_I16:
+		if len(v) != 2 {
+			return nil, errImportedConstantCorrupt()
+		}
+		bits = uint64(binary.LittleEndian.Uint16(v))
+	case schema_idl.Type_U32, schema_idl.Type_I32:
+		if len(v) != 4 {
+			return nil, errImportedConstantCorrupt()
+		}
+		bits = uint64(binary.LittleEndian.Uint32(v))
+	case schema_idl.Type_U64, schema_idl.Type_I64:
+		if len(v) != 8 {
+			return nil, errImportedConstantCorrupt()
+		}
+		bits = binary.LittleEndian.Uint64(v)
+	default:
+		return nil, errExprOperandNotInt(node)
+	}
+	return bigFromBits(const_.type_, bits), nil
+}
+
+// resolveEnumItemScope reports whether the scope of a value name refers to
+// an enum (as in `Color.RED`) rather than an import alias.
+func (c *compiler) resolveEnumItemScope(
+	node *syntax.ValueName,
+) (*typeInfo, bool) {
+	scope := node.Scope()
+	if scope == nil {
+		return nil, false
+	}
+	if _, isAlias := c.importsByAlias[scope.Get()]; isAlias {
+		return nil, false
+	}
+	if decl, ok := c.declsByName[scope.Get()]; ok {
+		if _, isEnum := decl.node.(*syntax.Enum); isEnum {
+			return &typeInfo{
+				type_:    decl.enumType,
+				typeName: scope.Get(),
+				decl:     decl,
+			}, true
+		}
+		return nil, false
+	}
+	if importedName, ok := c.importedNames[scope.Get()]; ok {
+		ictx := importedName.ictx
+		resolved, err := c.opts.deps.resolveType(
+			ictx.namespace,
+			scope.Get(),
+			importedName.node.Span(),
+			scope.Span(),
+		)
+		if err != nil {
+			return nil, false
+		}
+		if _, isEnum := resolved.imported.(schema_idl.Enum); !isEnum {
+			return nil, false
+		}
+		importedName.used = true
+		ictx.usedNames[scope.Get()] = struct{}{}
+		resolved.typeName = ictx.namespace + "\x1F" + scope.Get()
+		return resolved, true
+	}
+	return nil, false
+}
+
+// enumValues returns the item values of an enum type, compiling it first if
+// it's a local declaration. An enum that is currently being compiled has
+// only the values of items preceding the reference.
+func (c *compiler) enumValues(enumType *typeInfo) map[string]uint64 {
+	if enumType.imported != nil {
+		values := make(map[string]uint64)
+		for _, item := range enumType.imported.(schema_idl.Enum).Items().Iter() {
+			values[item.Name()] = item.Value()
+		}
+		return values
+	}
+	if enumType.decl == nil {
+		return nil
+	}
+	if !enumType.decl.compiling {
+		c.ensureCompiled(enumType.decl)
+	}
+	return enumType.decl.enumValues
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/syntax"
+)
+
+// compileErrors compiles a schema source, returning the formatted errors
+// (if any) and the compile result.
+func compileErrors(t *testing.T, src string) ([]string, compiler.CompileResult) {
+	t.Helper()
+	parsed, err := syntax.Parse([]byte(src))
+	testutil.AssertNoError(t, err)
+	result := compiler.Compile(parsed)
+	var errs []string
+	for _, err := range result.Errors {
+		errs = append(errs, err.Error())
+	}
+	return errs, result
+}
+
+func TestConstExprs(t *testing.T) {
+	tests := []struct {
+		type_ string
+		expr  string
+		value int64
+	}{
+		{"u32", "1 + 2", 3},
+		{"u32", "7 - 2", 5},
+		{"u32", "6 * 7", 42},
+		{"u32", "7 / 2", 3},
+		{"u32", "7 % 4", 3},
+		{"u32", "1 << 4", 16},
+		{"u32", "256 >> 4", 16},
+		{"u32", "0x0F | 0xF0", 0xFF},
+		{"u32", "0x3C & 0x0F", 0x0C},
+		{"i32", "-(3)", -3},
+		{"i32", "-7 / 2", -3},
+		{"i32", "-7 % 2", -1},
+		{"u8", "~0", 0xFF},
+		{"u16", "~0", 0xFFFF},
+		{"u32", "~1", 0xFFFFFFFE},
+		{"u8", "~0 >> 4", 0x0F},
+		{"i8", "~0", -1},
+		{"i8", "~0x7F", -0x80},
+		{"u32", "1 + 2 * 3", 7},
+		{"u32", "(1 + 2) * 3", 9},
+		{"u32", "1 | 2 << 2", 9},
+		{"u32", "10 - 4 - 3", 3},
+		{"u32", "A -1", 9},
+		{"u32", "A-1", 9},
+		{"u32", "A - 1", 9},
+		{"i32", "A * -1", -10},
+		{"u32", "B", 10},
+		{"u32", "C + 1", 11},
+		{"u8", "255 + 1 - 1", 0xFF},
+	}
+	for _, test := range tests {
+		src := fmt.Sprintf(`namespace "test"
+const A : u32 = 10
+const B : u32 = C
+const C : u32 = A
+const X : %s = %s
+`, test.type_, test.expr)
+		errs, result := compileErrors(t, src)
+		if len(errs) > 0 {
+			t.Errorf("%s = %s: %v", test.type_, test.expr, errs)
+			continue
+		}
+		schema, err := result.Schema()
+		testutil.AssertNoError(t, err)
+		var got int64
+		for _, const_ := range schema.Consts().Iter() {
+			if const_.Name() != "X" {
+				continue
+			}
+			value := const_.Value().Collect()
+			switch len(value) {
+			case 1:
+				got = int64(value[0])
+				if test.type_ == "i8" {
+					got = int64(int8(value[0]))
+				}
+			case 2:
+				got = int64(binary.LittleEndian.Uint16(value))
+			case 4:
+				got = int64(binary.LittleEndian.Uint32(value))
+				if test.type_ == "i32" {
+					got = int64(int32(binary.LittleEndian.Uint32(value)))
+				}
+			}
+		}
+		if got != test.value {
+			t.Errorf("%s = %s: expected %d, got %d", test.type_, test.expr, test.value, got)
+		}
+	}
+}
+
+func TestConstExprs_Errors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{
+			"const X : u8 = 255 + 1\n",
+			"E3016: Value 256 out of range [0, 255] for type 'u8'",
+		},
+		{
+			"const X : i8 = -128 - 1\n",
+			"E3016: Value -129 out of range [-128, 127] for type 'i8'",
+		},
+		{
+			"const X : u8 = 0 - 1\n",
+			"E3016: Value -1 out of range [0, 255] for type 'u8'",
+		},
+		{
+			"const X : u32 = 1 / 0\n",
+			"E3036: Division by zero in constant expression",
+		},
+		{
+			"const X : u32 = 1 % (2 - 2)\n",
+			"E3036: Division by zero in constant expression",
+		},
+		{
+			"const X : u64 = 1 << 65\n",
+			"E3037: Shift count 65 out of range [0, 64]",
+		},
+		{
+			"const X : u32 = 1 + .A\n",
+			"E3038: Enum item reference '.A' used outside of an enum-typed value",
+		},
+		{
+			"const T : text = \"a\"\nconst X : u32 = T + 1\n",
+			"E3035: Operand of constant expression must be an integer",
+		},
+		{
+			"const A : u32 = B\nconst B : u32 = A\n",
+			"E3034: Declaration 'A' depends on itself (A -> B -> A)",
+		},
+		{
+			"const A : u32 = A + 1\n",
+			"E3034: Declaration 'A' depends on itself (A -> A)",
+		},
+		{
+			"enum E : u8 {\n\tA = 1\n\tB = .A | .C\n}\n",
+			"E3050: Enum 'E' has no item 'C'",
+		},
+		{
+			"enum E : u8 {\n\tA = 1\n}\nconst X : u8 = E.B + 1\n",
+			"E3050: Enum 'E' has no item 'B'",
+		},
+		{
+			"enum E : u8 {\n\tA = 1\n}\nconst X : E = .B\n",
+			"E3050: Enum 'E' has no item 'B'",
+		},
+		{
+			"message M {\n\ta @1 : u8[1 << 32]\n}\n",
+			"E3051: Array length must be a u32",
+		},
+		{
+			"message M {\n\ta @1 : u8[0 - 1]\n}\n",
+			"E3051: Array length must be a u32",
+		},
+	}
+	for _, test := range tests {
+		errs, result := compileErrors(t, "namespace \"test\"\n"+test.src)
+		if len(errs) != 1 {
+			t.Errorf("%q: expected 1 error, got %q", test.src, errs)
+			continue
+		}
+		if errs[0] != test.err {
+			t.Errorf("%q: expected error %q, got %q", test.src, test.err, errs[0])
+		}
+		if span := result.Errors[0].Span(); span.Len() == 0 {
+			t.Errorf("%q: error %q has no span", test.src, errs[0])
+		}
+	}
+}
+
+func TestEnumReserved_Negative(t *testing.T) {
+	errs, result := compileErrors(t, `namespace "test"
+enum E : i8 {
+	reserved -1 -2 3
+	A = -3
+}
+`)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+	enum, _ := schema.Enums().Get(0)
+	testutil.ExpectSliceEq(t,
+		[]uint64{0xFF, 0xFE, 3},
+		enum.ReservedValues().Collect(),
+	)
+}
@@ -0,0 +1,367 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+// A Loader compiles imported schemas from source on demand, by searching
+// for the file "<ns>.idol" in each directory of a search path.
+type Loader struct {
+	searchPath []string
+	opts       []CompileOption
+	cache      *Cache
+
+	loaded map[string]*loadedSchema
+	order  []*loadedSchema
+	stack  []string
+}
+
+type loadedSchema struct {
+	namespace string
+	path      string
+	schema    schema_idl.Schema
+	deps      []*loadedSchema
+}
+
+func NewLoader(searchPath []string, opts ...CompileOption) *Loader {
+	return &Loader{
+		searchPath: searchPath,
+		opts:       opts,
+		loaded:     make(map[string]*loadedSchema),
+	}
+}
+
+// SetCache enables reuse of previously compiled schemas.
+func (l *Loader) SetCache(cache *Cache) {
+	l.cache = cache
+}
+
+// AddSchema registers a precompiled schema, which will be used to satisfy
+// imports of its namespace instead of searching for a source file.
+func (l *Loader) AddSchema(schema schema_idl.Schema) {
+	namespace := schema.Namespace()
+	if _, ok := l.loaded[namespace]; ok {
+		return
+	}
+	loaded := &loadedSchema{
+		namespace: namespace,
+		schema:    schema,
+	}
+	l.loaded[namespace] = loaded
+	l.order = append(l.order, loaded)
+}
+
+// Load returns the compiled schema for a namespace, compiling it (and its
+// imports) if it hasn't already been loaded.
+func (l *Loader) Load(namespace string) (schema_idl.Schema, error) {
+	loaded, err := l.load(namespace, nil)
+	if err != nil {
+		return schema_idl.Schema{}, err
+	}
+	return loaded.schema, nil
+}
+
+// Dependencies loads the imports of a parsed schema, returning a SchemaSet
+// suitable for use with WithDependencies.
+func (l *Loader) Dependencies(parsedSchema *syntax.Schema) (*SchemaSet, error) {
+	schemas, err := l.Imports(parsedSchema)
+	if err != nil {
+		return nil, err
+	}
+	return Merge(schemas)
+}
+
+// Imports loads the imports of a parsed schema, returning them and their
+// transitive dependencies in dependency order.
+func (l *Loader) Imports(parsedSchema *syntax.Schema) ([]schema_idl.Schema, error) {
+	var schemas []schema_idl.Schema
+	var errs []error
+	seen := make(map[*loadedSchema]struct{})
+	for _, node := range newSchemaNodes(parsedSchema).imports {
+		namespace, err := checkNamespace(node.Namespace())
+		if err != nil {
+			continue
+		}
+		loaded, err := l.load(namespace, node)
+		if err != nil {
+			setImporter(err, "", node)
+			errs = append(errs, err)
+			continue
+		}
+		schemas = loaded.appendClosure(schemas, seen)
+	}
+	if len(errs) > 0 {
+		return nil, joinErrors(errs)
+	}
+	return schemas, nil
+}
+
+// Schemas returns all loaded schemas, with each schema's dependencies
+// preceding it.
+func (l *Loader) Schemas() []schema_idl.Schema {
+	schemas := make([]schema_idl.Schema, 0, len(l.order))
+	for _, loaded := range l.order {
+		schemas = append(schemas, loaded.schema)
+	}
+	return schemas
+}
+
+// SourcePaths returns the paths of schema sources read by the loader, in
+// the order they were compiled. Schemas added with AddSchema aren't included.
+func (l *Loader) SourcePaths() []string {
+	var paths []string
+	for _, loaded := range l.order {
+		if loaded.path != "" {
+			paths = append(paths, loaded.path)
+		}
+	}
+	return paths
+}
+
+func (s *loadedSchema) appendClosure(
+	out []schema_idl.Schema,
+	seen map[*loadedSchema]struct{},
+) []schema_idl.Schema {
+	if _, ok := seen[s]; ok {
+		return out
+	}
+	seen[s] = struct{}{}
+	for _, dep := range s.deps {
+		out = dep.appendClosure(out, seen)
+	}
+	return append(out, s.schema)
+}
+
+func (l *Loader) findSource(namespace string) (string, bool) {
+	parts := strings.Split(namespace, "/")
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." || strings.ContainsAny(part, "\\\x00") {
+			return "", false
+		}
+	}
+	relPath := filepath.Join(parts...) + ".idol"
+	for _, dir := range l.searchPath {
+		path := filepath.Join(dir, relPath)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func (l *Loader) load(
+	namespace string,
+	importNode *syntax.Import,
+) (*loadedSchema, error) {
+	if loaded, ok := l.loaded[namespace]; ok {
+		return loaded, nil
+	}
+
+	var importSpan syntax.Span
+	if importNode != nil {
+		importSpan = importNode.Namespace().Span()
+	}
+	if idx := slices.Index(l.stack, namespace); idx >= 0 {
+		cycle := append(slices.Clone(l.stack[idx:]), namespace)
+		return nil, errImportCycle(cycle, importSpan)
+	}
+	path, ok := l.findSource(namespace)
+	if !ok {
+		return nil, errImportNamespaceNotFound(namespace, importSpan)
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &LoadError{namespace: namespace, path: path, err: err}
+	}
+	parsed, err := syntax.Parse(src)
+	if err != nil {
+		return nil, &LoadError{namespace: namespace, path: path, err: err}
+	}
+
+	l.stack = append(l.stack, namespace)
+	defer func() {
+		l.stack = l.stack[:len(l.stack)-1]
+	}()
+
+	loaded := &loadedSchema{
+		namespace: namespace,
+		path:      path,
+	}
+	var depSchemas []schema_idl.Schema
+	var errs []error
+	var importErrs []*Error
+	seen := make(map[*loadedSchema]struct{})
+	for _, node := range newSchemaNodes(parsed).imports {
+		depNamespace, err := checkNamespace(node.Namespace())
+		if err != nil {
+			continue
+		}
+		dep, err := l.load(depNamespace, node)
+		if err != nil {
+			// Errors at an import statement are located in this schema,
+			// others are in the imported schema.
+			if importErr, ok := err.(*Error); ok {
+				importErrs = append(importErrs, importErr)
+			} else {
+				setImporter(err, path, node)
+				errs = append(errs, err)
+			}
+			continue
+		}
+		loaded.deps = append(loaded.deps, dep)
+		depSchemas = dep.appendClosure(depSchemas, seen)
+	}
+	if len(importErrs) > 0 {
+		errs = append(errs, &LoadError{
+			namespace: namespace,
+			path:      path,
+			errors:    importErrs,
+		})
+	}
+	if len(errs) > 0 {
+		return nil, joinErrors(errs)
+	}
+
+	opts := NewCompileOptions(append(slices.Clone(l.opts),
+		WithSourcePath(strings.Split(namespace+".idol", "/")),
+	)...)
+	var result CompileResult
+	if l.cache != nil {
+		result, err = l.cache.compile(src, parsed, depSchemas, opts)
+	} else {
+		opts.deps, err = Merge(depSchemas)
+		if err == nil {
+			result = opts.Compile(parsed)
+		}
+	}
+	if err != nil {
+		return nil, &LoadError{namespace: namespace, path: path, err: err}
+	}
+	if len(result.Errors) > 0 {
+		return nil, &LoadError{
+			namespace: namespace,
+			path:      path,
+			errors:    result.Errors,
+		}
+	}
+	schema, err := result.Schema()
+	if err != nil {
+		return nil, &LoadError{namespace: namespace, path: path, err: err}
+	}
+	if schema.Namespace() != namespace {
+		return nil, &LoadError{
+			namespace: namespace,
+			path:      path,
+			errors: []*Error{
+				errLoadedNamespaceMismatch(namespace, schema.Namespace()).(*Error),
+			},
+		}
+	}
+
+	loaded.schema = schema
+	l.loaded[namespace] = loaded
+	l.order = append(l.order, loaded)
+	return loaded, nil
+}
+
+// joinErrors combines errors from loading several imports. A single error
+// is returned as-is, so that callers may check its type.
+func joinErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// setImporter records the import statement that caused a schema to be
+// loaded, for load errors that don't already have one.
+func setImporter(err error, importerPath string, node *syntax.Import) {
+	switch err := err.(type) {
+	case *LoadError:
+		if err.importNode == nil {
+			err.importerPath = importerPath
+			err.importNode = node
+		}
+	case interface{ Unwrap() []error }:
+		for _, err := range err.Unwrap() {
+			setImporter(err, importerPath, node)
+		}
+	}
+}
+
+// LoadError is returned by a Loader when an imported schema can't be read,
+// parsed, or compiled. When several imports fail, the Loader returns their
+// LoadErrors combined with errors.Join.
+type LoadError struct {
+	namespace string
+	path      string
+	err       error
+	errors    []*Error
+
+	importerPath string
+	importNode   *syntax.Import
+}
+
+func (err *LoadError) Error() string {
+	if err.err != nil {
+		return fmt.Sprintf("%s: %v", err.path, err.err)
+	}
+	var buf strings.Builder
+	for ii, compileErr := range err.errors {
+		if ii > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "%s: %v", err.path, compileErr)
+	}
+	return buf.String()
+}
+
+func (err *LoadError) Unwrap() error {
+	return err.err
+}
+
+func (err *LoadError) Namespace() string {
+	return err.namespace
+}
+
+func (err *LoadError) Path() string {
+	return err.path
+}
+
+func (err *LoadError) Errors() []*Error {
+	return err.errors
+}
+
+// ImportedAt returns the path of the schema that imported the failed
+// schema, and the span of the imported namespace within it. The path is
+// empty if the importer is the schema passed to Imports or Dependencies.
+func (err *LoadError) ImportedAt() (string, syntax.Span, bool) {
+	if err.importNode == nil {
+		return "", syntax.Span{}, false
+	}
+	return err.importerPath, err.importNode.Namespace().Span(), true
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/syntax"
+)
+
+// writeSources writes schema sources to a temporary directory, returning
+// its path.
+func writeSources(t *testing.T, sources map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, src := range sources {
+		fullPath := filepath.Join(dir, path)
+		testutil.AssertNoError(t, os.MkdirAll(filepath.Dir(fullPath), 0o755))
+		testutil.AssertNoError(t, os.WriteFile(fullPath, []byte(src), 0o644))
+	}
+	return dir
+}
+
+func loadImports(t *testing.T, dir, src string) ([]string, error) {
+	t.Helper()
+	parsed, err := syntax.Parse([]byte(src))
+	testutil.AssertNoError(t, err)
+	loader := compiler.NewLoader([]string{dir})
+	schemas, err := loader.Imports(parsed)
+	var namespaces []string
+	for _, schema := range schemas {
+		namespaces = append(namespaces, schema.Namespace())
+	}
+	return namespaces, err
+}
+
+// loadErrors flattens the LoadErrors returned by a Loader.
+func loadErrors(err error) []*compiler.LoadError {
+	switch err := err.(type) {
+	case *compiler.LoadError:
+		return []*compiler.LoadError{err}
+	case interface{ Unwrap() []error }:
+		var out []*compiler.LoadError
+		for _, err := range err.Unwrap() {
+			out = append(out, loadErrors(err)...)
+		}
+		return out
+	}
+	return nil
+}
+
+func TestLoader(t *testing.T) {
+	dir := writeSources(t, map[string]string{
+		"a.idol":   "namespace \"a\"\nimport \"b/c\" as c\nmessage A {\n\tc @1 : c.C\n}\n",
+		"b/c.idol": "namespace \"b/c\"\nmessage C {}\n",
+	})
+	namespaces, err := loadImports(t, dir, "namespace \"main\"\nimport \"a\" as a\n")
+	testutil.AssertNoError(t, err)
+	testutil.ExpectSliceEq(t, []string{"b/c", "a"}, namespaces)
+}
+
+func TestLoader_ImportErrors(t *testing.T) {
+	dir := writeSources(t, map[string]string{
+		"a.idol": "namespace \"a\"\nimport \"missing1\" as m1\nimport \"missing2\" as m2\n",
+	})
+	_, err := loadImports(t, dir, "namespace \"main\"\nimport \"a\" as a\n")
+	loadErrs := loadErrors(err)
+	if len(loadErrs) != 1 {
+		t.Fatalf("expected 1 LoadError, got %v", err)
+	}
+	loadErr := loadErrs[0]
+	testutil.ExpectEq(t, "a", loadErr.Namespace())
+	testutil.ExpectEq(t, filepath.Join(dir, "a.idol"), loadErr.Path())
+
+	// Every failed import is reported, not only the first.
+	var messages []string
+	for _, err := range loadErr.Errors() {
+		messages = append(messages, err.Error())
+	}
+	testutil.ExpectSliceEq(t, []string{
+		`E3001: Namespace "missing1" not found in dependencies`,
+		`E3001: Namespace "missing2" not found in dependencies`,
+	}, messages)
+
+	importerPath, span, ok := loadErr.ImportedAt()
+	testutil.ExpectEq(t, true, ok)
+	testutil.ExpectEq(t, "", importerPath)
+	testutil.ExpectEq(t, uint32(24), span.Start())
+}
+
+func TestLoader_NestedErrors(t *testing.T) {
+	dir := writeSources(t, map[string]string{
+		"a.idol": "namespace \"a\"\nimport \"b\" as b\nimport \"c\" as c\n",
+		"b.idol": "namespace \"b\"\nmessage B {\n\tx @1 : Missing\n}\n",
+		"c.idol": "namespace \"c\"\nmessage C {\n",
+	})
+	_, err := loadImports(t, dir, "namespace \"main\"\nimport \"a\" as a\n")
+	loadErrs := loadErrors(err)
+	if len(loadErrs) != 2 {
+		t.Fatalf("expected 2 LoadErrors, got %v", err)
+	}
+	for ii, namespace := range []string{"b", "c"} {
+		loadErr := loadErrs[ii]
+		testutil.ExpectEq(t, namespace, loadErr.Namespace())
+		importerPath, span, ok := loadErr.ImportedAt()
+		testutil.ExpectEq(t, true, ok)
+		testutil.ExpectEq(t, filepath.Join(dir, "a.idol"), importerPath)
+		if span.Start() == 0 {
+			t.Errorf("%s: import span not set", namespace)
+		}
+	}
+	testutil.ExpectEq(t, 1, len(loadErrs[0].Errors()))
+	if loadErrs[1].Unwrap() == nil {
+		t.Errorf("expected a parse error, got %v", loadErrs[1])
+	}
+}
+
+func TestLoader_ImportCycle(t *testing.T) {
+	dir := writeSources(t, map[string]string{
+		"a.idol": "namespace \"a\"\nimport \"b\" as b\n",
+		"b.idol": "namespace \"b\"\nimport \"a\" as a\n",
+	})
+	_, err := loadImports(t, dir, "namespace \"main\"\nimport \"a\" as a\n")
+	loadErrs := loadErrors(err)
+	if len(loadErrs) != 1 || len(loadErrs[0].Errors()) != 1 {
+		t.Fatalf("expected 1 LoadError with 1 error, got %v", err)
+	}
+	testutil.ExpectEq(t, "b", loadErrs[0].Namespace())
+	testutil.ExpectEq(
+		t,
+		`E3040: Import cycle detected ("a" -> "b" -> "a")`,
+		loadErrs[0].Errors()[0].Error(),
+	)
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+const sourceInfoSrc = `namespace "test"
+
+const C : u32 = 1
+
+enum E : u8 {
+	A = 1
+	  B = 2
+}
+
+struct S {
+	x : u32
+}
+
+message M {
+	a @1 : u32
+	b @2 : E
+}
+
+union U {
+	a @1 : u8
+}
+
+protocol P {
+	rpc Get(M) : M
+	event Changed : M
+}
+`
+
+// fmtSpan formats a source span as "line:column+len", with lines and
+// columns numbered from 1.
+func fmtSpan(src []uint8, span schema_idl.SourceSpan) string {
+	before := src[:span.Start()]
+	line := bytes.Count(before, []uint8("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("%d:%d+%d", line, column, span.Len())
+}
+
+func compileSourceInfo(
+	t *testing.T,
+	opts ...compiler.CompileOption,
+) (schema_idl.Schema, string) {
+	t.Helper()
+	parsed, err := syntax.Parse([]uint8(sourceInfoSrc))
+	testutil.AssertNoError(t, err)
+	result := compiler.Compile(parsed, opts...)
+	testutil.ExpectSliceEq(t, nil, result.Errors)
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+	encoded, err := result.EncodedSchema()
+	testutil.AssertNoError(t, err)
+	return schema, encoded
+}
+
+// collectSpans returns the formatted source spans of each declaration in
+// the schema, and of their fields, items, RPCs and events.
+func collectSpans(schema schema_idl.Schema) []string {
+	src := []uint8(sourceInfoSrc)
+	var spans []string
+	add := func(name string, span schema_idl.SourceSpan) {
+		spans = append(spans, name+" "+fmtSpan(src, span))
+	}
+	for _, c := range schema.Consts().Iter() {
+		add("const "+c.Name(), c.SourceSpan())
+	}
+	for _, e := range schema.Enums().Iter() {
+		add("enum "+e.Name(), e.SourceSpan())
+		for _, item := range e.Items().Iter() {
+			add("item "+item.Name(), item.SourceSpan())
+		}
+	}
+	for _, s := range schema.Structs().Iter() {
+		add("struct "+s.Name(), s.SourceSpan())
+		for _, field := range s.Fields().Iter() {
+			add("field "+field.Name(), field.SourceSpan())
+		}
+	}
+	for _, m := range schema.Messages().Iter() {
+		add("message "+m.Name(), m.SourceSpan())
+		for _, field := range m.Fields().Iter() {
+			add("field "+field.Name(), field.SourceSpan())
+		}
+	}
+	for _, u := range schema.Unions().Iter() {
+		add("union "+u.Name(), u.SourceSpan())
+		for _, field := range u.Fields().Iter() {
+			add("field "+field.Name(), field.SourceSpan())
+		}
+	}
+	for _, p := range schema.Protocols().Iter() {
+		add("protocol "+p.Name(), p.SourceSpan())
+		for _, rpc := range p.Rpcs().Iter() {
+			add("rpc "+rpc.Name(), rpc.SourceSpan())
+		}
+		for _, event := range p.Events().Iter() {
+			add("event "+event.Name(), event.SourceSpan())
+		}
+	}
+	return spans
+}
+
+func TestSourceInfo(t *testing.T) {
+	schema, _ := compileSourceInfo(t, compiler.WithSourceInfo(true))
+	testutil.ExpectSliceEq(t, []string{
+		"const C 3:1+17",
+		"enum E 5:1+31",
+		"item A 6:2+5",
+		"item B 7:4+5",
+		"struct S 10:1+21",
+		"field x 11:2+7",
+		"message M 14:1+35",
+		"field a 15:2+10",
+		"field b 16:2+8",
+		"union U 19:1+22",
+		"field a 20:2+9",
+		"protocol P 23:1+49",
+		"rpc Get 24:2+14",
+		"event Changed 25:2+17",
+	}, collectSpans(schema))
+}
+
+func TestSourceInfo_Default(t *testing.T) {
+	// Without WithSourceInfo, no spans are recorded.
+	schema, encoded := compileSourceInfo(t)
+	testutil.ExpectSliceEq(t, []string{
+		"const C 1:1+0",
+		"enum E 1:1+0",
+		"item A 1:1+0",
+		"item B 1:1+0",
+		"struct S 1:1+0",
+		"field x 1:1+0",
+		"message M 1:1+0",
+		"field a 1:1+0",
+		"field b 1:1+0",
+		"union U 1:1+0",
+		"field a 1:1+0",
+		"protocol P 1:1+0",
+		"rpc Get 1:1+0",
+		"event Changed 1:1+0",
+	}, collectSpans(schema))
+
+	// Spans are omitted from the encoded schema, not recorded as empty.
+	_, withSpans := compileSourceInfo(t, compiler.WithSourceInfo(true))
+	testutil.ExpectTrue(t, len(encoded) < len(withSpans))
+}
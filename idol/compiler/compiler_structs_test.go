@@ -0,0 +1,127 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/syntax"
+)
+
+// fmtDiagnostic formats an error and its notes, each followed by the line,
+// column, and source text of its span.
+func fmtDiagnostic(src string, err *compiler.Error) []string {
+	spanText := func(span syntax.Span) string {
+		before := src[:span.Start()]
+		line := strings.Count(before, "\n") + 1
+		column := len(before) - strings.LastIndexByte(before, '\n')
+		return fmt.Sprintf("%d:%d %s", line, column, src[span.Start():span.End()])
+	}
+	out := []string{fmt.Sprintf("%s [%s]", err, spanText(err.Span()))}
+	for _, note := range err.Notes() {
+		out = append(out, fmt.Sprintf("note: %s [%s]", note.Message(), spanText(note.Span())))
+	}
+	return out
+}
+
+func structErrors(t *testing.T, src string) [][]string {
+	t.Helper()
+	src = "namespace \"test\"\n" + src
+	parsed, err := syntax.Parse([]uint8(src))
+	testutil.AssertNoError(t, err)
+	var errs [][]string
+	for _, err := range compiler.Compile(parsed).Errors {
+		errs = append(errs, fmtDiagnostic(src, err))
+	}
+	return errs
+}
+
+func TestStructRecursive(t *testing.T) {
+	tests := []struct {
+		src  string
+		errs [][]string
+	}{
+		{
+			"struct S {\n\ta : u8\n\tb : S\n}\n",
+			[][]string{{
+				"E3033: Struct 'S' is recursive (S.b -> S) [4:6 S]",
+				"note: Field 'S.b' contains struct 'S' [4:6 S]",
+			}},
+		},
+		{
+			"struct A {\n\tb : B\n}\nstruct B {\n\ta : A\n}\n",
+			[][]string{{
+				"E3033: Struct 'A' is recursive (A.b -> B.a -> A) [3:6 B]",
+				"note: Field 'A.b' contains struct 'B' [3:6 B]",
+				"note: Field 'B.a' contains struct 'A' [6:6 A]",
+			}},
+		},
+		{
+			"struct S {\n\ta : S[2]\n}\n",
+			[][]string{{
+				"E3033: Struct 'S' is recursive (S.a -> S) [3:6 S[2]]",
+				"note: Field 'S.a' contains struct 'S' [3:6 S[2]]",
+			}},
+		},
+		{
+			"struct A {\n\tb : B\n}\nstruct B {\n\tc : C[4]\n}\nstruct C {\n\ta : A\n}\n",
+			[][]string{{
+				"E3033: Struct 'A' is recursive (A.b -> B.c -> C.a -> A) [3:6 B]",
+				"note: Field 'A.b' contains struct 'B' [3:6 B]",
+				"note: Field 'B.c' contains struct 'C' [6:6 C[4]]",
+				"note: Field 'C.a' contains struct 'A' [9:6 A]",
+			}},
+		},
+	}
+	for _, test := range tests {
+		errs := structErrors(t, test.src)
+		if len(errs) != len(test.errs) {
+			t.Errorf("%q: expected %q, got %q", test.src, test.errs, errs)
+			continue
+		}
+		for ii := range errs {
+			testutil.ExpectSliceEq(t, test.errs[ii], errs[ii])
+		}
+	}
+}
+
+func TestStructRecursive_Diamond(t *testing.T) {
+	// A struct may contain another struct through several paths without
+	// being recursive.
+	errs := structErrors(t, `struct A {
+	b : B
+	c : C
+	d : D[2]
+}
+struct B {
+	d : D
+}
+struct C {
+	d : D
+	b : B
+}
+struct D {
+	x : u32
+}
+`)
+	if len(errs) > 0 {
+		t.Errorf("unexpected errors %q", errs)
+	}
+}
@@ -17,6 +17,9 @@ package compiler
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"go.idol-lang.org/idol/syntax"
 )
@@ -43,6 +46,74 @@ func (w *Warning) Span() syntax.Span {
 	return w.span
 }
 
+// ParseWarningCode parses a warning code, with or without its "W" prefix.
+func ParseWarningCode(code string) (uint32, error) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(code, "W"), 10, 32)
+	if err != nil || value < 4000 || value > 4999 {
+		return 0, fmt.Errorf("invalid warning code %q", code)
+	}
+	return uint32(value), nil
+}
+
+func (w *Warning) asError() *Error {
+	return &Error{
+		code:    w.code,
+		message: w.message,
+		span:    w.span,
+	}
+}
+
+type warningSuppression struct {
+	start, end uint32
+	codes      []uint32
+}
+
+func (c *compiler) suppressWarnings(node interface {
+	syntax.Node
+	Decorators() []*syntax.Decorator
+}, codes []uint32) {
+	span := node.Span()
+	start := span.Start()
+	for _, decorator := range node.Decorators() {
+		decoratorSpan := decorator.Span()
+		start = min(start, decoratorSpan.Start())
+	}
+	c.suppressions = append(c.suppressions, &warningSuppression{
+		start: start,
+		end:   span.End(),
+		codes: codes,
+	})
+}
+
+func (c *compiler) isWarningSuppressed(w *Warning) bool {
+	if slices.Contains(c.opts.suppressedWarnings, w.code) {
+		return true
+	}
+	for _, s := range c.suppressions {
+		if w.span.Start() >= s.start && w.span.End() <= s.end {
+			if slices.Contains(s.codes, w.code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *compiler) applyWarningOptions() {
+	var warnings []*Warning
+	for _, w := range c.warnings {
+		if c.isWarningSuppressed(w) {
+			continue
+		}
+		if c.opts.warningsAsErrors || slices.Contains(c.opts.warningErrorCodes, w.code) {
+			c.errors = append(c.errors, w.asError())
+			continue
+		}
+		warnings = append(warnings, w)
+	}
+	c.warnings = warnings
+}
+
 func warnEmptyImport(ns string, span syntax.Span) *Warning {
 	return &Warning{
 		code:    4000,
@@ -145,6 +216,14 @@ func warnOptionNameNotFound(name string, node syntax.Node) *Warning {
 	}
 }
 
+func warnDeprecatedReference(ns, name string, node syntax.Node) *Warning {
+	return &Warning{
+		code:    4012,
+		message: fmt.Sprintf("'%s' in namespace \"%s\" is deprecated", name, ns),
+		span:    node.Span(),
+	}
+}
+
 func fmtTypeName(node interface {
 	Scope() *syntax.Ident
 	Name() *syntax.Ident
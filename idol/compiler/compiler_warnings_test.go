@@ -0,0 +1,170 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package compiler_test
+
+import (
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/syntax"
+)
+
+func compileWarnings(
+	t *testing.T,
+	src string,
+	opts ...compiler.CompileOption,
+) (errs []string, warnings []string) {
+	t.Helper()
+	parsed, err := syntax.Parse([]byte(src))
+	testutil.AssertNoError(t, err)
+	result := compiler.Compile(parsed, opts...)
+	for _, err := range result.Errors {
+		errs = append(errs, err.Error())
+	}
+	for _, warning := range result.Warnings {
+		warnings = append(warnings, warning.String())
+	}
+	return errs, warnings
+}
+
+func TestParseWarningCode(t *testing.T) {
+	tests := []struct {
+		code  string
+		value uint32
+		ok    bool
+	}{
+		{"W4009", 4009, true},
+		{"4009", 4009, true},
+		{"W4000", 4000, true},
+		{"W4999", 4999, true},
+		{"W3999", 0, false},
+		{"W5000", 0, false},
+		{"E3001", 0, false},
+		{"WW4009", 0, false},
+		{"x", 0, false},
+		{"", 0, false},
+	}
+	for _, test := range tests {
+		value, err := compiler.ParseWarningCode(test.code)
+		if test.ok {
+			testutil.AssertNoError(t, err)
+		} else if err == nil {
+			t.Errorf("ParseWarningCode(%q): expected error", test.code)
+		}
+		testutil.ExpectEq(t, test.value, value)
+	}
+}
+
+const shadowsBuiltin = "W4009: Local declaration 'bytes' shadows builtin"
+
+func TestSuppressWarnings(t *testing.T) {
+	tests := []struct {
+		src      string
+		warnings []string
+	}{
+		{
+			"namespace \"test\"\nmessage bytes {}\n",
+			[]string{shadowsBuiltin},
+		},
+		{
+			"namespace \"test\"\n@{suppress_warnings = \"W4009\"}\nmessage bytes {}\n",
+			nil,
+		},
+		{
+			"namespace \"test\"\n@{suppress_warnings = \"W4000, 4009\"}\nmessage bytes {}\n",
+			nil,
+		},
+		{
+			"namespace \"test\"\n@{suppress_warnings = \"W4000\"}\nmessage bytes {}\n",
+			[]string{shadowsBuiltin},
+		},
+		{
+			"namespace \"test\"\noptions { suppress_warnings = \"W4009\" }\nmessage bytes {}\n",
+			nil,
+		},
+		{
+			// Suppression only covers the decorated declaration.
+			"namespace \"test\"\n@{suppress_warnings = \"W4009\"}\nmessage M {}\nmessage bytes {}\n",
+			[]string{shadowsBuiltin},
+		},
+	}
+	for _, test := range tests {
+		errs, warnings := compileWarnings(t, test.src)
+		testutil.ExpectSliceEq(t, nil, errs)
+		testutil.ExpectSliceEq(t, test.warnings, warnings)
+	}
+}
+
+func TestSuppressWarnings_Errors(t *testing.T) {
+	tests := []struct {
+		value string
+		err   string
+	}{
+		{`"W5000"`, `E3043: Invalid warning code "W5000"`},
+		{`"W4009 x"`, `E3043: Invalid warning code "x"`},
+		{"4009", "E3042: Option 'suppress_warnings' must be a text listing warning codes"},
+	}
+	for _, test := range tests {
+		src := "namespace \"test\"\n@{suppress_warnings = " + test.value + "}\nmessage M {}\n"
+		errs, _ := compileWarnings(t, src)
+		testutil.ExpectSliceEq(t, []string{test.err}, errs)
+	}
+}
+
+func TestWarningOptions(t *testing.T) {
+	src := "namespace \"test\"\nmessage bytes {}\n"
+	tests := []struct {
+		opts     []compiler.CompileOption
+		errs     []string
+		warnings []string
+	}{
+		{nil, nil, []string{shadowsBuiltin}},
+		{
+			[]compiler.CompileOption{compiler.WithSuppressedWarning(4009)},
+			nil,
+			nil,
+		},
+		{
+			[]compiler.CompileOption{compiler.WithSuppressedWarning(4000)},
+			nil,
+			[]string{shadowsBuiltin},
+		},
+		{
+			[]compiler.CompileOption{compiler.WithWarningAsError(4009)},
+			[]string{"E4009: Local declaration 'bytes' shadows builtin"},
+			nil,
+		},
+		{
+			[]compiler.CompileOption{compiler.WithWarningsAsErrors()},
+			[]string{"E4009: Local declaration 'bytes' shadows builtin"},
+			nil,
+		},
+		{
+			[]compiler.CompileOption{
+				compiler.WithWarningsAsErrors(),
+				compiler.WithSuppressedWarning(4009),
+			},
+			nil,
+			nil,
+		},
+	}
+	for _, test := range tests {
+		errs, warnings := compileWarnings(t, src, test.opts...)
+		testutil.ExpectSliceEq(t, test.errs, errs)
+		testutil.ExpectSliceEq(t, test.warnings, warnings)
+	}
+}
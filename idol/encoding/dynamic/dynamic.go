@@ -0,0 +1,148 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+// Package dynamic decodes Idol messages using schemas loaded at runtime, for
+// tools that don't have generated code for the message types.
+package dynamic
+
+import (
+	"fmt"
+	"iter"
+)
+
+// A Message is a decoded message, with field names resolved from its schema.
+//
+// Fields with tags not present in the schema are kept with a name of the
+// form "@TAG", and their undecoded value (uint32 or []uint8).
+type Message struct {
+	namespace string
+	name      string
+	fields    []*messageField
+}
+
+type messageField struct {
+	tag   uint16
+	name  string
+	value any
+}
+
+func (m *Message) Namespace() string {
+	return m.namespace
+}
+
+func (m *Message) MessageName() string {
+	return m.name
+}
+
+func (m *Message) field(tag uint16) *messageField {
+	for _, field := range m.fields {
+		if field.tag == tag {
+			return field
+		}
+	}
+	return nil
+}
+
+func (m *Message) Name(tag uint16) string {
+	if field := m.field(tag); field != nil {
+		return field.name
+	}
+	return ""
+}
+
+func (m *Message) Has(tag uint16) bool {
+	return m.field(tag) != nil
+}
+
+// Get returns the value of the field with the given tag.
+//
+// Values have the Go type closest to their schema type: bool, the sized
+// integer and float types, string for text, *Message for messages, Enum
+// for enums, and slices of these for arrays.
+func (m *Message) Get(tag uint16) (any, bool) {
+	if field := m.field(tag); field != nil {
+		return field.value, true
+	}
+	return nil, false
+}
+
+func (m *Message) Values() iter.Seq2[uint16, any] {
+	return func(yield func(uint16, any) bool) {
+		for _, field := range m.fields {
+			if !yield(field.tag, field.value) {
+				return
+			}
+		}
+	}
+}
+
+// An Enum is a decoded enum value. Values that don't match any item of the
+// enum are kept, with an empty item name.
+type Enum struct {
+	typeName string
+	item     string
+	value    any
+}
+
+func (e Enum) TypeName() string {
+	return e.typeName
+}
+
+func (e Enum) Item() (string, bool) {
+	return e.item, e.item != ""
+}
+
+// Value returns the enum value as its underlying integer type.
+func (e Enum) Value() any {
+	return e.value
+}
+
+func (e Enum) String() string {
+	if e.item != "" {
+		return e.item
+	}
+	return fmt.Sprint(e.value)
+}
+
+func (e Enum) MarshalIdolText() string {
+	if e.item != "" {
+		return "." + e.item
+	}
+	return fmt.Sprint(e.value)
+}
+
+// A DecodeError reports malformed input, with the byte offset at which
+// it was found and the path of the field being decoded.
+type DecodeError struct {
+	offset  uint32
+	path    string
+	message string
+}
+
+func (err *DecodeError) Error() string {
+	return fmt.Sprintf("offset 0x%X (%s): %s", err.offset, err.path, err.message)
+}
+
+func (err *DecodeError) Offset() uint32 {
+	return err.offset
+}
+
+func (err *DecodeError) Path() string {
+	return err.path
+}
+
+func (err *DecodeError) Message() string {
+	return err.message
+}
@@ -0,0 +1,621 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package dynamic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/schema_idl"
+)
+
+// Decode decodes buf as an encoded message of the named type.
+//
+// Unlike generated decoders, which may assume their input has been
+// produced by a conforming encoder, Decode validates the entire message
+// and reports malformed input as a *DecodeError.
+func Decode(
+	schemas *compiler.SchemaSet,
+	namespace string,
+	name string,
+	buf []uint8,
+) (*Message, error) {
+	decl, ok := schemas.Lookup(namespace, name)
+	if !ok {
+		return nil, fmt.Errorf("Message '%s' not found in namespace %q", name, namespace)
+	}
+	if _, ok := decl.Message(); !ok {
+		return nil, fmt.Errorf("'%s' in namespace %q is not a message", name, namespace)
+	}
+	if uint64(len(buf)) > uint64(idol.MaxMessageSize) {
+		return nil, &DecodeError{
+			path: name,
+			message: fmt.Sprintf(
+				"input size %d exceeds maximum message size %d",
+				len(buf), idol.MaxMessageSize,
+			),
+		}
+	}
+	d := &decoder{schemas: schemas, buf: buf}
+	return d.message(decl, 0, uint32(len(buf)), name)
+}
+
+type decoder struct {
+	schemas *compiler.SchemaSet
+	buf     []uint8
+}
+
+func (d *decoder) errorf(offset uint32, path string, format string, args ...any) error {
+	return &DecodeError{
+		offset:  offset,
+		path:    path,
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+type thunk struct {
+	tag      uint16
+	offset   uint32
+	indirect bool
+
+	// The scalar value, or the size of the indirect value.
+	value uint32
+
+	// The offset of the indirect value.
+	dataOffset uint32
+}
+
+func (d *decoder) message(
+	decl *compiler.Decl,
+	offset uint32,
+	size uint32,
+	path string,
+) (*Message, error) {
+	schema, _ := decl.Message()
+	msg := &Message{namespace: decl.Namespace(), name: decl.Name()}
+	if size == 0 {
+		return msg, nil
+	}
+	if size < 8 {
+		return nil, d.errorf(offset, path, "message size %d is smaller than the message header", size)
+	}
+	if size%8 != 0 {
+		return nil, d.errorf(offset, path, "message size %d is not a multiple of 8", size)
+	}
+	buf := d.buf[offset : offset+size]
+	if headerSize := binary.LittleEndian.Uint32(buf[0:4]); headerSize != size {
+		return nil, d.errorf(offset, path, "message header has size %d, expected %d", headerSize, size)
+	}
+	if flags := binary.LittleEndian.Uint16(buf[4:6]); flags != 0x0000 {
+		return nil, d.errorf(offset+4, path, "unsupported message flags 0x%04X", flags)
+	}
+	thunkCount := uint32(binary.LittleEndian.Uint16(buf[6:8]))
+	dataOff := 8 + thunkCount*8
+	if dataOff > size {
+		return nil, d.errorf(offset+6, path, "thunk count %d exceeds message size %d", thunkCount, size)
+	}
+
+	fields := make(map[uint16]schema_idl.MessageField)
+	for _, field := range schema.Fields().Iter() {
+		fields[field.Tag()] = field
+	}
+	fieldPath := func(tag uint16) string {
+		if field, ok := fields[tag]; ok {
+			return path + "." + field.Name()
+		}
+		return fmt.Sprintf("%s.@%d", path, tag)
+	}
+
+	var thunks []thunk
+	valueOff := dataOff
+	for tag := uint32(1); tag <= thunkCount; tag++ {
+		thunkOff := tag * 8
+		thunkBuf := buf[thunkOff : thunkOff+8]
+		flags := binary.LittleEndian.Uint16(thunkBuf[2:4])
+		if flags&0x8000 == 0x0000 {
+			if binary.LittleEndian.Uint64(thunkBuf) != 0 {
+				return nil, d.errorf(offset+thunkOff, fieldPath(uint16(tag)), "thunk of absent field is not zero")
+			}
+			continue
+		}
+		if flags&0x3FFF != 0x0000 {
+			return nil, d.errorf(offset+thunkOff+2, fieldPath(uint16(tag)), "invalid thunk flags 0x%04X", flags)
+		}
+		if handles := binary.LittleEndian.Uint16(thunkBuf[0:2]); handles != 0 {
+			return nil, d.errorf(offset+thunkOff, fieldPath(uint16(tag)), "decoding handles is not supported")
+		}
+		value := binary.LittleEndian.Uint32(thunkBuf[4:8])
+		if flags&0x4000 == 0x0000 {
+			thunks = append(thunks, thunk{
+				tag:    uint16(tag),
+				offset: offset + thunkOff,
+				value:  value,
+			})
+			continue
+		}
+		paddedSize := (uint64(value) + 0b111) &^ 0b111
+		if uint64(valueOff)+paddedSize > uint64(size) {
+			return nil, d.errorf(
+				offset+thunkOff+4, fieldPath(uint16(tag)),
+				"value size %d exceeds message size %d", value, size,
+			)
+		}
+		for ii := valueOff + value; ii < valueOff+uint32(paddedSize); ii++ {
+			if buf[ii] != 0x00 {
+				return nil, d.errorf(offset+ii, fieldPath(uint16(tag)), "nonzero padding byte 0x%02X", buf[ii])
+			}
+		}
+		thunks = append(thunks, thunk{
+			tag:        uint16(tag),
+			offset:     offset + thunkOff,
+			indirect:   true,
+			value:      value,
+			dataOffset: offset + valueOff,
+		})
+		valueOff += uint32(paddedSize)
+	}
+	if valueOff != size {
+		return nil, d.errorf(offset+valueOff, path, "%d bytes of unused data after field values", size-valueOff)
+	}
+
+	for _, thunk := range thunks {
+		field, ok := fields[thunk.tag]
+		if !ok {
+			var value any = thunk.value
+			if thunk.indirect {
+				value = d.bytes(thunk.dataOffset, thunk.value)
+			}
+			msg.fields = append(msg.fields, &messageField{
+				tag:   thunk.tag,
+				name:  fmt.Sprintf("@%d", thunk.tag),
+				value: value,
+			})
+			continue
+		}
+		value, err := d.field(decl.Namespace(), field, thunk, fieldPath(thunk.tag))
+		if err != nil {
+			return nil, err
+		}
+		msg.fields = append(msg.fields, &messageField{
+			tag:   thunk.tag,
+			name:  field.Name(),
+			value: value,
+		})
+	}
+	return msg, nil
+}
+
+func (d *decoder) bytes(offset, size uint32) []uint8 {
+	return append([]uint8(nil), d.buf[offset:offset+size]...)
+}
+
+func (d *decoder) field(
+	namespace string,
+	field schema_idl.MessageField,
+	thunk thunk,
+	path string,
+) (any, error) {
+	var enum *compiler.Decl
+	var message *compiler.Decl
+	type_ := field.Type()
+	if typeName := field.TypeName(); typeName != "" {
+		decl, ok := d.schemas.ResolveTypeName(namespace, typeName)
+		if !ok {
+			return nil, d.errorf(thunk.offset, path, "type '%s' not found in schema", typeName)
+		}
+		switch decl.Type() {
+		case schema_idl.ExportType_ENUM:
+			enum = decl
+		case schema_idl.ExportType_MESSAGE:
+			message = decl
+		}
+	}
+
+	switch type_ {
+	case schema_idl.Type_HANDLE:
+		return nil, d.errorf(thunk.offset, path, "decoding handles is not supported")
+	case schema_idl.Type_STRUCT, schema_idl.Type_UNION:
+		return nil, d.errorf(thunk.offset, path, "decoding %s fields is not supported", fmtType(type_))
+	}
+
+	if field.ArrayLen() > 0 {
+		if !thunk.indirect {
+			return nil, d.errorf(thunk.offset, path, "expected indirect value for array field")
+		}
+		values, err := d.array(type_, enum, message, thunk.dataOffset, thunk.value, path)
+		if err != nil {
+			return nil, err
+		}
+		arrayLen := field.ArrayLen()
+		if count := arrayCount(values); arrayLen != math.MaxUint32 && count > arrayLen {
+			return nil, d.errorf(
+				thunk.dataOffset, path,
+				"array has %d elements, maximum is %d", count, arrayLen,
+			)
+		}
+		return values, nil
+	}
+
+	switch type_ {
+	case schema_idl.Type_U64, schema_idl.Type_I64, schema_idl.Type_F64:
+		if !thunk.indirect {
+			return nil, d.errorf(thunk.offset, path, "expected indirect value for %s field", fmtType(type_))
+		}
+		if thunk.value != 8 {
+			return nil, d.errorf(thunk.offset+4, path, "%s value has size %d, expected 8", fmtType(type_), thunk.value)
+		}
+		return d.scalar(type_, enum, binary.LittleEndian.Uint64(d.buf[thunk.dataOffset:]), thunk.dataOffset, path)
+	case schema_idl.Type_TEXT, schema_idl.Type_ASCIZ:
+		if !thunk.indirect {
+			return nil, d.errorf(thunk.offset, path, "expected indirect value for %s field", fmtType(type_))
+		}
+		return d.text(type_, thunk.dataOffset, thunk.value, path)
+	case schema_idl.Type_MESSAGE:
+		if !thunk.indirect {
+			return nil, d.errorf(thunk.offset, path, "expected indirect value for message field")
+		}
+		if message == nil {
+			return nil, d.errorf(thunk.offset, path, "message type '%s' not found in schema", field.TypeName())
+		}
+		return d.message(message, thunk.dataOffset, thunk.value, path)
+	}
+
+	if thunk.indirect {
+		return nil, d.errorf(thunk.offset, path, "expected scalar value for %s field", fmtType(type_))
+	}
+	return d.scalar(type_, enum, uint64(thunk.value), thunk.offset+4, path)
+}
+
+func (d *decoder) scalar(
+	type_ schema_idl.Type,
+	enum *compiler.Decl,
+	raw uint64,
+	offset uint32,
+	path string,
+) (any, error) {
+	var value any
+	switch type_ {
+	case schema_idl.Type_BOOL:
+		if raw > 1 {
+			return nil, d.errorf(offset, path, "invalid bool value %d", raw)
+		}
+		value = raw == 1
+	case schema_idl.Type_U8:
+		if raw > math.MaxUint8 {
+			return nil, d.errorf(offset, path, "value %d out of range for u8", raw)
+		}
+		value = uint8(raw)
+	case schema_idl.Type_U16:
+		if raw > math.MaxUint16 {
+			return nil, d.errorf(offset, path, "value %d out of range for u16", raw)
+		}
+		value = uint16(raw)
+	case schema_idl.Type_U32:
+		value = uint32(raw)
+	case schema_idl.Type_U64:
+		value = raw
+	case schema_idl.Type_I8:
+		if int32(raw) < math.MinInt8 || int32(raw) > math.MaxInt8 {
+			return nil, d.errorf(offset, path, "value %d out of range for i8", int32(raw))
+		}
+		value = int8(raw)
+	case schema_idl.Type_I16:
+		if int32(raw) < math.MinInt16 || int32(raw) > math.MaxInt16 {
+			return nil, d.errorf(offset, path, "value %d out of range for i16", int32(raw))
+		}
+		value = int16(raw)
+	case schema_idl.Type_I32:
+		value = int32(raw)
+	case schema_idl.Type_I64:
+		value = int64(raw)
+	case schema_idl.Type_F32:
+		value = math.Float32frombits(uint32(raw))
+	case schema_idl.Type_F64:
+		value = math.Float64frombits(raw)
+	default:
+		return nil, d.errorf(offset, path, "unsupported field type %s", fmtType(type_))
+	}
+	if enum != nil {
+		return enumValue(enum, type_, raw), nil
+	}
+	return value, nil
+}
+
+func enumValue(decl *compiler.Decl, type_ schema_idl.Type, raw uint64) Enum {
+	enum, _ := decl.Enum()
+	value := Enum{typeName: decl.Name()}
+
+	// Enum item values of signed enums are stored sign-extended.
+	switch type_ {
+	case schema_idl.Type_U8:
+		value.value = uint8(raw)
+	case schema_idl.Type_U16:
+		value.value = uint16(raw)
+	case schema_idl.Type_U32:
+		value.value = uint32(raw)
+	case schema_idl.Type_U64:
+		value.value = raw
+	case schema_idl.Type_I8:
+		value.value = int8(raw)
+		raw = uint64(int64(int8(raw)))
+	case schema_idl.Type_I16:
+		value.value = int16(raw)
+		raw = uint64(int64(int16(raw)))
+	case schema_idl.Type_I32:
+		value.value = int32(raw)
+		raw = uint64(int64(int32(raw)))
+	case schema_idl.Type_I64:
+		value.value = int64(raw)
+	}
+	for _, item := range enum.Items().Iter() {
+		if item.Value() == raw && !item.IsAlias() {
+			value.item = item.Name()
+			break
+		}
+	}
+	return value
+}
+
+func (d *decoder) text(
+	type_ schema_idl.Type,
+	offset uint32,
+	size uint32,
+	path string,
+) (string, error) {
+	buf := d.buf[offset : offset+size]
+	if size == 0 || buf[size-1] != 0x00 {
+		return "", d.errorf(offset, path, "%s value is not NUL-terminated", fmtType(type_))
+	}
+	buf = buf[:size-1]
+	for ii, b := range buf {
+		if b == 0x00 {
+			return "", d.errorf(offset+uint32(ii), path, "%s value contains NUL byte", fmtType(type_))
+		}
+		if type_ == schema_idl.Type_ASCIZ && b >= 0x80 {
+			return "", d.errorf(offset+uint32(ii), path, "asciz value contains non-ASCII byte 0x%02X", b)
+		}
+	}
+	if !utf8.Valid(buf) {
+		return "", d.errorf(offset, path, "text value is not valid UTF-8")
+	}
+	return string(buf), nil
+}
+
+func arrayCount(values any) uint32 {
+	switch values := values.(type) {
+	case []bool:
+		return uint32(len(values))
+	case []uint8:
+		return uint32(len(values))
+	case []int8:
+		return uint32(len(values))
+	case []uint16:
+		return uint32(len(values))
+	case []int16:
+		return uint32(len(values))
+	case []uint32:
+		return uint32(len(values))
+	case []int32:
+		return uint32(len(values))
+	case []uint64:
+		return uint32(len(values))
+	case []int64:
+		return uint32(len(values))
+	case []float32:
+		return uint32(len(values))
+	case []float64:
+		return uint32(len(values))
+	case []string:
+		return uint32(len(values))
+	case []Enum:
+		return uint32(len(values))
+	case []*Message:
+		return uint32(len(values))
+	}
+	return 0
+}
+
+func (d *decoder) array(
+	type_ schema_idl.Type,
+	enum *compiler.Decl,
+	message *compiler.Decl,
+	offset uint32,
+	size uint32,
+	path string,
+) (any, error) {
+	switch type_ {
+	case schema_idl.Type_TEXT, schema_idl.Type_ASCIZ:
+		items, err := d.dynArray(offset, size, false, path)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, 0, len(items))
+		for ii, item := range items {
+			value, err := d.text(type_, item.offset, item.size, fmt.Sprintf("%s[%d]", path, ii))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case schema_idl.Type_MESSAGE:
+		if message == nil {
+			return nil, d.errorf(offset, path, "message type not found in schema")
+		}
+		items, err := d.dynArray(offset, size, true, path)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]*Message, 0, len(items))
+		for ii, item := range items {
+			value, err := d.message(message, item.offset, item.size, fmt.Sprintf("%s[%d]", path, ii))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	var width uint32
+	switch type_ {
+	case schema_idl.Type_BOOL, schema_idl.Type_U8, schema_idl.Type_I8:
+		width = 1
+	case schema_idl.Type_U16, schema_idl.Type_I16:
+		width = 2
+	case schema_idl.Type_U32, schema_idl.Type_I32, schema_idl.Type_F32:
+		width = 4
+	case schema_idl.Type_U64, schema_idl.Type_I64, schema_idl.Type_F64:
+		width = 8
+	default:
+		return nil, d.errorf(offset, path, "unsupported array element type %s", fmtType(type_))
+	}
+	if size%width != 0 {
+		return nil, d.errorf(offset, path, "%s array has size %d, not a multiple of %d", fmtType(type_), size, width)
+	}
+	if type_ == schema_idl.Type_U8 && enum == nil {
+		return d.bytes(offset, size), nil
+	}
+
+	var enums []Enum
+	var scalars []any
+	for ii := uint32(0); ii < size/width; ii++ {
+		itemOff := offset + ii*width
+		var raw uint64
+		switch width {
+		case 1:
+			raw = uint64(d.buf[itemOff])
+		case 2:
+			raw = uint64(binary.LittleEndian.Uint16(d.buf[itemOff:]))
+		case 4:
+			raw = uint64(binary.LittleEndian.Uint32(d.buf[itemOff:]))
+		case 8:
+			raw = binary.LittleEndian.Uint64(d.buf[itemOff:])
+		}
+		// Signed array elements are stored at their own width, so widen
+		// them to the sign-extended form used for scalar values.
+		switch type_ {
+		case schema_idl.Type_I8:
+			raw = uint64(uint32(int8(raw)))
+		case schema_idl.Type_I16:
+			raw = uint64(uint32(int16(raw)))
+		}
+		value, err := d.scalar(type_, enum, raw, itemOff, fmt.Sprintf("%s[%d]", path, ii))
+		if err != nil {
+			return nil, err
+		}
+		if enum != nil {
+			enums = append(enums, value.(Enum))
+		} else {
+			scalars = append(scalars, value)
+		}
+	}
+	if enum != nil {
+		return enums, nil
+	}
+	switch type_ {
+	case schema_idl.Type_BOOL:
+		return collect[bool](scalars), nil
+	case schema_idl.Type_I8:
+		return collect[int8](scalars), nil
+	case schema_idl.Type_U16:
+		return collect[uint16](scalars), nil
+	case schema_idl.Type_I16:
+		return collect[int16](scalars), nil
+	case schema_idl.Type_U32:
+		return collect[uint32](scalars), nil
+	case schema_idl.Type_I32:
+		return collect[int32](scalars), nil
+	case schema_idl.Type_U64:
+		return collect[uint64](scalars), nil
+	case schema_idl.Type_I64:
+		return collect[int64](scalars), nil
+	case schema_idl.Type_F32:
+		return collect[float32](scalars), nil
+	case schema_idl.Type_F64:
+		return collect[float64](scalars), nil
+	}
+	panic("unreachable")
+}
+
+func collect[T any](values []any) []T {
+	out := make([]T, 0, len(values))
+	for _, value := range values {
+		out = append(out, value.(T))
+	}
+	return out
+}
+
+type arrayItem struct {
+	offset uint32
+	size   uint32
+}
+
+// dynArray splits an array of variable-size values into its items. Items of
+// message arrays are 8-byte aligned.
+func (d *decoder) dynArray(offset, size uint32, align bool, path string) ([]arrayItem, error) {
+	if size == 0 {
+		return nil, nil
+	}
+	if size < 4 {
+		return nil, d.errorf(offset, path, "array size %d is smaller than the array header", size)
+	}
+	count := binary.LittleEndian.Uint32(d.buf[offset:])
+	valueOff := 4 + uint64(count)*4
+	if align && count&0x01 == 0x00 {
+		valueOff += 4
+	}
+	if valueOff > uint64(size) {
+		return nil, d.errorf(offset, path, "array length %d exceeds array size %d", count, size)
+	}
+	items := make([]arrayItem, 0, count)
+	for ii := uint32(0); ii < count; ii++ {
+		sizeOff := offset + 4 + ii*4
+		itemSize := binary.LittleEndian.Uint32(d.buf[sizeOff:])
+		if align && itemSize%8 != 0 {
+			return nil, d.errorf(sizeOff, fmt.Sprintf("%s[%d]", path, ii), "message size %d is not a multiple of 8", itemSize)
+		}
+		if valueOff+uint64(itemSize) > uint64(size) {
+			return nil, d.errorf(sizeOff, fmt.Sprintf("%s[%d]", path, ii), "item size %d exceeds array size %d", itemSize, size)
+		}
+		items = append(items, arrayItem{
+			offset: offset + uint32(valueOff),
+			size:   itemSize,
+		})
+		valueOff += uint64(itemSize)
+	}
+	// The array size may include padding to a multiple of 8 bytes.
+	if unused := uint64(size) - valueOff; unused > 0 {
+		if unused >= 8 {
+			return nil, d.errorf(offset+uint32(valueOff), path, "%d bytes of unused data after array items", unused)
+		}
+		for ii := offset + uint32(valueOff); ii < offset+size; ii++ {
+			if d.buf[ii] != 0x00 {
+				return nil, d.errorf(ii, path, "nonzero padding byte 0x%02X", d.buf[ii])
+			}
+		}
+	}
+	return items, nil
+}
+
+func fmtType(type_ schema_idl.Type) string {
+	return strings.ToLower(type_.String())
+}
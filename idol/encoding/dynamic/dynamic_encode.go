@@ -0,0 +1,253 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package dynamic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"go.idol-lang.org/idol"
+)
+
+// Encode encodes a message in the binary encoding.
+//
+// As with generated message builders, fields with zero values are omitted,
+// except for fields containing a message.
+func Encode(msg *Message) ([]uint8, error) {
+	return encodeMessage(msg)
+}
+
+type encodedField struct {
+	tag      uint16
+	indirect bool
+	scalar   uint32
+	data     []uint8
+}
+
+func encodeMessage(msg *Message) ([]uint8, error) {
+	var fields []encodedField
+	dataSize := 0
+	for _, field := range msg.fields {
+		encoded, ok, err := encodeValue(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", msg.name, field.name, err)
+		}
+		if !ok {
+			continue
+		}
+		encoded.tag = field.tag
+		fields = append(fields, encoded)
+		dataSize += (len(encoded.data) + 0b111) &^ 0b111
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	thunkCount := fields[len(fields)-1].tag
+	size := 8 + 8*int(thunkCount) + dataSize
+	if uint64(size) > uint64(idol.MaxMessageSize) {
+		return nil, fmt.Errorf(
+			"%s: encoded message size %d exceeds maximum message size %d",
+			msg.name, size, idol.MaxMessageSize,
+		)
+	}
+	buf := make([]uint8, size)
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(size))
+	binary.LittleEndian.PutUint16(buf[6:8], thunkCount)
+	dataOff := 8 + 8*int(thunkCount)
+	for _, field := range fields {
+		thunk := buf[int(field.tag)*8 : int(field.tag)*8+8]
+		if !field.indirect {
+			binary.LittleEndian.PutUint16(thunk[2:4], 0x8000)
+			binary.LittleEndian.PutUint32(thunk[4:8], field.scalar)
+			continue
+		}
+		binary.LittleEndian.PutUint16(thunk[2:4], 0xC000)
+		binary.LittleEndian.PutUint32(thunk[4:8], uint32(len(field.data)))
+		copy(buf[dataOff:], field.data)
+		dataOff += (len(field.data) + 0b111) &^ 0b111
+	}
+	return buf, nil
+}
+
+func scalarField(value uint32) (encodedField, bool, error) {
+	return encodedField{scalar: value}, value != 0, nil
+}
+
+func indirectField(data []uint8) (encodedField, bool, error) {
+	return encodedField{indirect: true, data: data}, len(data) > 0, nil
+}
+
+func encodeValue(value any) (encodedField, bool, error) {
+	switch value := value.(type) {
+	case bool:
+		if value {
+			return scalarField(1)
+		}
+		return scalarField(0)
+	case uint8:
+		return scalarField(uint32(value))
+	case uint16:
+		return scalarField(uint32(value))
+	case uint32:
+		return scalarField(value)
+	case int8:
+		return scalarField(uint32(int32(value)))
+	case int16:
+		return scalarField(uint32(int32(value)))
+	case int32:
+		return scalarField(uint32(value))
+	case float32:
+		return scalarField(math.Float32bits(value))
+	case uint64:
+		if value == 0 {
+			return indirectField(nil)
+		}
+		return indirectField(binary.LittleEndian.AppendUint64(nil, value))
+	case int64:
+		return encodeValue(uint64(value))
+	case float64:
+		return encodeValue(math.Float64bits(value))
+	case string:
+		if value == "" {
+			return indirectField(nil)
+		}
+		return indirectField(append([]uint8(value), 0x00))
+	case Enum:
+		return encodeValue(value.value)
+	case *Message:
+		data, err := encodeMessage(value)
+		if err != nil {
+			return encodedField{}, false, err
+		}
+		return encodedField{indirect: true, data: data}, true, nil
+	case []uint8:
+		return indirectField(value)
+	case []bool:
+		data := make([]uint8, len(value))
+		for ii, item := range value {
+			if item {
+				data[ii] = 1
+			}
+		}
+		return indirectField(data)
+	case []int8:
+		return indirectField(appendArray(value, func(buf []uint8, item int8) []uint8 {
+			return append(buf, uint8(item))
+		}))
+	case []uint16:
+		return indirectField(appendArray(value, binary.LittleEndian.AppendUint16))
+	case []int16:
+		return indirectField(appendArray(value, func(buf []uint8, item int16) []uint8 {
+			return binary.LittleEndian.AppendUint16(buf, uint16(item))
+		}))
+	case []uint32:
+		return indirectField(appendArray(value, binary.LittleEndian.AppendUint32))
+	case []int32:
+		return indirectField(appendArray(value, func(buf []uint8, item int32) []uint8 {
+			return binary.LittleEndian.AppendUint32(buf, uint32(item))
+		}))
+	case []float32:
+		return indirectField(appendArray(value, func(buf []uint8, item float32) []uint8 {
+			return binary.LittleEndian.AppendUint32(buf, math.Float32bits(item))
+		}))
+	case []uint64:
+		return indirectField(appendArray(value, binary.LittleEndian.AppendUint64))
+	case []int64:
+		return indirectField(appendArray(value, func(buf []uint8, item int64) []uint8 {
+			return binary.LittleEndian.AppendUint64(buf, uint64(item))
+		}))
+	case []float64:
+		return indirectField(appendArray(value, func(buf []uint8, item float64) []uint8 {
+			return binary.LittleEndian.AppendUint64(buf, math.Float64bits(item))
+		}))
+	case []Enum:
+		var data []uint8
+		for _, item := range value {
+			switch item := item.value.(type) {
+			case uint8:
+				data = append(data, item)
+			case int8:
+				data = append(data, uint8(item))
+			case uint16:
+				data = binary.LittleEndian.AppendUint16(data, item)
+			case int16:
+				data = binary.LittleEndian.AppendUint16(data, uint16(item))
+			case uint32:
+				data = binary.LittleEndian.AppendUint32(data, item)
+			case int32:
+				data = binary.LittleEndian.AppendUint32(data, uint32(item))
+			case uint64:
+				data = binary.LittleEndian.AppendUint64(data, item)
+			case int64:
+				data = binary.LittleEndian.AppendUint64(data, uint64(item))
+			default:
+				return encodedField{}, false, fmt.Errorf("unsupported enum value type %T", item)
+			}
+		}
+		return indirectField(data)
+	case []string:
+		if len(value) == 0 {
+			return indirectField(nil)
+		}
+		data := binary.LittleEndian.AppendUint32(nil, uint32(len(value)))
+		for _, item := range value {
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(item))+1)
+		}
+		for _, item := range value {
+			data = append(data, item...)
+			data = append(data, 0x00)
+		}
+		// The size of a text array includes its padding.
+		for len(data)%8 != 0 {
+			data = append(data, 0x00)
+		}
+		return indirectField(data)
+	case []*Message:
+		if len(value) == 0 {
+			return indirectField(nil)
+		}
+		items := make([][]uint8, 0, len(value))
+		for _, item := range value {
+			encoded, err := encodeMessage(item)
+			if err != nil {
+				return encodedField{}, false, err
+			}
+			items = append(items, encoded)
+		}
+		data := binary.LittleEndian.AppendUint32(nil, uint32(len(items)))
+		for _, item := range items {
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(item)))
+		}
+		if len(items)&0x01 == 0x00 {
+			data = append(data, 0, 0, 0, 0)
+		}
+		for _, item := range items {
+			data = append(data, item...)
+		}
+		return indirectField(data)
+	}
+	return encodedField{}, false, fmt.Errorf("unsupported value type %T", value)
+}
+
+func appendArray[T any](values []T, appendItem func([]uint8, T) []uint8) []uint8 {
+	var data []uint8
+	for _, value := range values {
+		data = appendItem(data, value)
+	}
+	return data
+}
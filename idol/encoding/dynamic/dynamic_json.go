@@ -0,0 +1,39 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package dynamic
+
+import (
+	"encoding/json"
+
+	"go.idol-lang.org/idol/encoding/idoljson"
+)
+
+// MarshalJSON encodes the message as a JSON object keyed by field name, in
+// tag order.
+//
+// Enum values are encoded as their item name, or as a number if the value
+// has no item. Non-finite floats are encoded as the strings "NaN",
+// "Infinity", and "-Infinity".
+func (m *Message) MarshalJSON() ([]byte, error) {
+	return idoljson.EncodeFields(m)
+}
+
+func (e Enum) MarshalJSON() ([]byte, error) {
+	if e.item != "" {
+		return json.Marshal(e.item)
+	}
+	return json.Marshal(e.value)
+}
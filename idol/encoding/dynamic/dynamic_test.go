@@ -0,0 +1,215 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package dynamic_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/encoding/dynamic"
+	"go.idol-lang.org/idol/encoding/idoltext"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+const testSchema = `namespace "test"
+enum Color : u8 {
+	RED = 1
+	GREEN = 2
+}
+message Inner {
+	name @1 : text
+}
+message M {
+	flag @1 : bool
+	count @2 : u32
+	color @3 : Color
+	big @4 : u64
+	inner @5 : Inner
+	tags @6 : text[]
+	delta @7 : i16
+}
+`
+
+func testSchemas(t *testing.T) *compiler.SchemaSet {
+	t.Helper()
+	parsed, err := syntax.Parse([]byte(testSchema))
+	testutil.AssertNoError(t, err)
+	result := compiler.Compile(parsed)
+	for _, err := range result.Errors {
+		t.Fatal(err)
+	}
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+	set, err := compiler.Merge([]schema_idl.Schema{schema})
+	testutil.AssertNoError(t, err)
+	return set
+}
+
+func testMessage() []uint8 {
+	return []uint8{
+		96, 0, 0, 0, 0, 0, 7, 0,
+
+		0, 0, 0, 0x80, 1, 0, 0, 0,
+		0, 0, 0, 0x80, 7, 0, 0, 0,
+		0, 0, 0, 0x80, 2, 0, 0, 0,
+		0, 0, 0, 0xC0, 8, 0, 0, 0,
+		0, 0, 0, 0xC0, 24, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0x80, 0xFE, 0xFF, 0xFF, 0xFF,
+
+		0, 0, 0, 0, 0, 1, 0, 0,
+
+		24, 0, 0, 0, 0, 0, 1, 0,
+		0, 0, 0, 0xC0, 3, 0, 0, 0,
+		'h', 'i', 0, 0, 0, 0, 0, 0,
+	}
+}
+
+func TestDecode(t *testing.T) {
+	msg, err := dynamic.Decode(testSchemas(t), "test", "M", testMessage())
+	testutil.AssertNoError(t, err)
+
+	testutil.ExpectEq(t, `flag = .true
+count = 7
+color = .GREEN
+big = 1099511627776
+inner = {
+	name = "hi"
+}
+delta = -2
+`, idoltext.EncodeFields(msg))
+
+	encoded, err := json.Marshal(msg)
+	testutil.AssertNoError(t, err)
+	testutil.ExpectEq(t,
+		`{"flag":true,"count":7,"color":"GREEN","big":1099511627776,"inner":{"name":"hi"},"delta":-2}`,
+		string(encoded),
+	)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch func(buf []uint8) []uint8
+		err   string
+	}{
+		{
+			name: "truncated",
+			patch: func(buf []uint8) []uint8 {
+				return buf[:88]
+			},
+			err: "offset 0x0 (M): message header has size 96, expected 88",
+		},
+		{
+			name: "invalid bool",
+			patch: func(buf []uint8) []uint8 {
+				buf[12] = 2
+				return buf
+			},
+			err: "offset 0xC (M.flag): invalid bool value 2",
+		},
+		{
+			name: "scalar as indirect",
+			patch: func(buf []uint8) []uint8 {
+				buf[27] = 0xC0
+				return buf
+			},
+			err: "offset 0x45 (M.color): nonzero padding byte 0x01",
+		},
+		{
+			name: "nested padding",
+			patch: func(buf []uint8) []uint8 {
+				buf[92] = 1
+				return buf
+			},
+			err: "offset 0x5C (M.inner.name): nonzero padding byte 0x01",
+		},
+		{
+			name: "i16 out of range",
+			patch: func(buf []uint8) []uint8 {
+				buf[62] = 0x00
+				return buf
+			},
+			err: "offset 0x3C (M.delta): value -16711682 out of range for i16",
+		},
+	}
+	set := testSchemas(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := dynamic.Decode(set, "test", "M", test.patch(testMessage()))
+			var decodeErr *dynamic.DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expected *DecodeError, got %v", err)
+			}
+			testutil.ExpectEq(t, test.err, decodeErr.Error())
+		})
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	set := testSchemas(t)
+	text := `# comment
+flag = .true
+count = 7
+color = .GREEN
+big = 1099511627776
+inner {
+	name = "hi"
+}
+delta = -2
+`
+	msg, err := dynamic.ParseText(set, "test", "M", []uint8(text))
+	testutil.AssertNoError(t, err)
+	encoded, err := dynamic.Encode(msg)
+	testutil.AssertNoError(t, err)
+	testutil.ExpectSliceEq(t, testMessage(), encoded)
+
+	decoded, err := dynamic.Decode(set, "test", "M", encoded)
+	testutil.AssertNoError(t, err)
+	msg, err = dynamic.ParseText(set, "test", "M", []uint8(idoltext.EncodeFields(decoded)))
+	testutil.AssertNoError(t, err)
+	reencoded, err := dynamic.Encode(msg)
+	testutil.AssertNoError(t, err)
+	testutil.ExpectSliceEq(t, encoded, reencoded)
+}
+
+func TestParseText_Errors(t *testing.T) {
+	tests := []struct {
+		text string
+		err  string
+	}{
+		{"count = \"7\"\n", "1:9: Type mismatch for field 'count': expected u32, found text"},
+		{"flag = .true\nmissing = 1\n", "2:1: Message 'M' has no field 'missing'"},
+		{"color = .BLUE\n", "1:9: Enum 'Color' has no item 'BLUE'"},
+		{"delta = 40000\n", "1:9: Value 40000 out of range for i16"},
+		{"tags = [\"a\", 1]\n", "1:14: Type mismatch for field 'tags': expected text[], found integer"},
+		{"count = 1\ncount = 2\n", "2:1: Duplicate field 'count'"},
+		{"inner = {\n\tname = \"hi\n}\n", "2:9: Unterminated text"},
+	}
+	set := testSchemas(t)
+	for _, test := range tests {
+		_, err := dynamic.ParseText(set, "test", "M", []uint8(test.text))
+		if err == nil {
+			t.Errorf("ParseText(%q) succeeded, expected error %q", test.text, test.err)
+			continue
+		}
+		testutil.ExpectEq(t, test.err, err.Error())
+	}
+}
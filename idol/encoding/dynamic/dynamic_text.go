@@ -0,0 +1,421 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package dynamic
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/encoding/idoltext"
+	"go.idol-lang.org/idol/schema_idl"
+)
+
+// A TextError reports idoltext input that doesn't match the message schema,
+// with the position of the offending field or value.
+type TextError struct {
+	pos     idoltext.Position
+	message string
+}
+
+func (err *TextError) Error() string {
+	return fmt.Sprintf("%s: %s", err.pos, err.message)
+}
+
+func (err *TextError) Position() idoltext.Position {
+	return err.pos
+}
+
+func (err *TextError) Message() string {
+	return err.message
+}
+
+// ParseText parses src as a message of the named type in the text encoding.
+//
+// Syntax errors are reported as an *idoltext.ParseError, and values that
+// don't match the schema as a *TextError.
+func ParseText(
+	schemas *compiler.SchemaSet,
+	namespace string,
+	name string,
+	src []uint8,
+) (*Message, error) {
+	decl, ok := schemas.Lookup(namespace, name)
+	if !ok {
+		return nil, fmt.Errorf("Message '%s' not found in namespace %q", name, namespace)
+	}
+	if _, ok := decl.Message(); !ok {
+		return nil, fmt.Errorf("'%s' in namespace %q is not a message", name, namespace)
+	}
+	fields, err := idoltext.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &textParser{schemas: schemas}
+	return p.message(decl, fields)
+}
+
+type textParser struct {
+	schemas *compiler.SchemaSet
+}
+
+func (p *textParser) errorf(pos idoltext.Position, format string, args ...any) error {
+	return &TextError{
+		pos:     pos,
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *textParser) message(decl *compiler.Decl, textFields []*idoltext.Field) (*Message, error) {
+	schema, _ := decl.Message()
+	msg := &Message{namespace: decl.Namespace(), name: decl.Name()}
+
+	fields := make(map[string]schema_idl.MessageField)
+	for _, field := range schema.Fields().Iter() {
+		fields[field.Name()] = field
+	}
+
+	for _, textField := range textFields {
+		field, ok := fields[textField.Name()]
+		if !ok {
+			return nil, p.errorf(
+				textField.Position(),
+				"Message '%s' has no field '%s'", decl.Name(), textField.Name(),
+			)
+		}
+		existing := msg.field(field.Tag())
+
+		// Items of message arrays may be written as repeated blocks.
+		if field.ArrayLen() > 0 && field.Type() == schema_idl.Type_MESSAGE && textField.IsBlock() {
+			item, err := p.messageValue(decl.Namespace(), field, textField.Value())
+			if err != nil {
+				return nil, err
+			}
+			if existing == nil {
+				existing = &messageField{tag: field.Tag(), name: field.Name(), value: []*Message(nil)}
+				msg.fields = append(msg.fields, existing)
+			}
+			items := append(existing.value.([]*Message), item)
+			if arrayLen := field.ArrayLen(); arrayLen != math.MaxUint32 && uint64(len(items)) > uint64(arrayLen) {
+				return nil, p.errorf(
+					textField.Position(),
+					"Field '%s' has %d items, maximum is %d", field.Name(), len(items), arrayLen,
+				)
+			}
+			existing.value = items
+			continue
+		}
+
+		if existing != nil {
+			return nil, p.errorf(textField.Position(), "Duplicate field '%s'", field.Name())
+		}
+		value, err := p.field(decl.Namespace(), field, textField)
+		if err != nil {
+			return nil, err
+		}
+		msg.fields = append(msg.fields, &messageField{
+			tag:   field.Tag(),
+			name:  field.Name(),
+			value: value,
+		})
+	}
+
+	slices.SortFunc(msg.fields, func(a, b *messageField) int {
+		return cmp.Compare(a.tag, b.tag)
+	})
+	return msg, nil
+}
+
+func (p *textParser) field(
+	namespace string,
+	field schema_idl.MessageField,
+	textField *idoltext.Field,
+) (any, error) {
+	value := textField.Value()
+	if textField.IsBlock() && field.Type() != schema_idl.Type_MESSAGE {
+		return nil, p.errorf(
+			textField.Position(),
+			"Type mismatch for field '%s': expected %s, found message",
+			field.Name(), fmtFieldType(field),
+		)
+	}
+	switch field.Type() {
+	case schema_idl.Type_HANDLE:
+		return nil, p.errorf(textField.Position(), "Encoding handles is not supported")
+	case schema_idl.Type_STRUCT, schema_idl.Type_UNION:
+		return nil, p.errorf(textField.Position(), "Encoding %s fields is not supported", fmtType(field.Type()))
+	}
+
+	if field.ArrayLen() == 0 {
+		return p.value(namespace, field, value)
+	}
+	if value.Kind() != idoltext.ValueList {
+		return nil, p.mismatch(field, value)
+	}
+	items := value.Items()
+	if arrayLen := field.ArrayLen(); arrayLen != math.MaxUint32 && uint64(len(items)) > uint64(arrayLen) {
+		return nil, p.errorf(
+			value.Position(),
+			"Field '%s' has %d items, maximum is %d", field.Name(), len(items), arrayLen,
+		)
+	}
+	var values []any
+	for _, item := range items {
+		itemValue, err := p.value(namespace, field, item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, itemValue)
+	}
+
+	if field.TypeName() != "" && field.Type() != schema_idl.Type_MESSAGE {
+		return collect[Enum](values), nil
+	}
+	switch field.Type() {
+	case schema_idl.Type_BOOL:
+		return collect[bool](values), nil
+	case schema_idl.Type_U8:
+		return collect[uint8](values), nil
+	case schema_idl.Type_I8:
+		return collect[int8](values), nil
+	case schema_idl.Type_U16:
+		return collect[uint16](values), nil
+	case schema_idl.Type_I16:
+		return collect[int16](values), nil
+	case schema_idl.Type_U32:
+		return collect[uint32](values), nil
+	case schema_idl.Type_I32:
+		return collect[int32](values), nil
+	case schema_idl.Type_U64:
+		return collect[uint64](values), nil
+	case schema_idl.Type_I64:
+		return collect[int64](values), nil
+	case schema_idl.Type_F32:
+		return collect[float32](values), nil
+	case schema_idl.Type_F64:
+		return collect[float64](values), nil
+	case schema_idl.Type_TEXT, schema_idl.Type_ASCIZ:
+		return collect[string](values), nil
+	case schema_idl.Type_MESSAGE:
+		return collect[*Message](values), nil
+	}
+	panic("unreachable")
+}
+
+func (p *textParser) mismatch(field schema_idl.MessageField, value *idoltext.Value) error {
+	return p.errorf(
+		value.Position(),
+		"Type mismatch for field '%s': expected %s, found %s",
+		field.Name(), fmtFieldType(field), value.Kind(),
+	)
+}
+
+func (p *textParser) messageValue(
+	namespace string,
+	field schema_idl.MessageField,
+	value *idoltext.Value,
+) (*Message, error) {
+	if value.Kind() != idoltext.ValueMessage {
+		return nil, p.mismatch(field, value)
+	}
+	decl, ok := p.schemas.ResolveTypeName(namespace, field.TypeName())
+	if !ok {
+		return nil, p.errorf(value.Position(), "Type '%s' not found in schema", field.TypeName())
+	}
+	return p.message(decl, value.Fields())
+}
+
+// value parses a single value of the field's type, or an item of its
+// array type.
+func (p *textParser) value(
+	namespace string,
+	field schema_idl.MessageField,
+	value *idoltext.Value,
+) (any, error) {
+	type_ := field.Type()
+	if type_ == schema_idl.Type_MESSAGE {
+		return p.messageValue(namespace, field, value)
+	}
+
+	if typeName := field.TypeName(); typeName != "" {
+		decl, ok := p.schemas.ResolveTypeName(namespace, typeName)
+		if !ok {
+			return nil, p.errorf(value.Position(), "Type '%s' not found in schema", typeName)
+		}
+		enum, _ := decl.Enum()
+		switch value.Kind() {
+		case idoltext.ValueEnumRef:
+			for _, item := range enum.Items().Iter() {
+				if item.Name() == value.Text() {
+					return enumValue(decl, type_, item.Value()), nil
+				}
+			}
+			return nil, p.errorf(
+				value.Position(),
+				"Enum '%s' has no item '%s'", decl.Name(), value.Text(),
+			)
+		case idoltext.ValueInt:
+			raw, err := p.integer(field, value)
+			if err != nil {
+				return nil, err
+			}
+			return enumValue(decl, type_, raw), nil
+		}
+		return nil, p.mismatch(field, value)
+	}
+
+	switch type_ {
+	case schema_idl.Type_BOOL:
+		if value.Kind() == idoltext.ValueEnumRef {
+			switch value.Text() {
+			case "true":
+				return true, nil
+			case "false":
+				return false, nil
+			}
+			return nil, p.errorf(value.Position(), "Invalid bool value '.%s'", value.Text())
+		}
+	case schema_idl.Type_U8, schema_idl.Type_U16, schema_idl.Type_U32, schema_idl.Type_U64,
+		schema_idl.Type_I8, schema_idl.Type_I16, schema_idl.Type_I32, schema_idl.Type_I64:
+		if value.Kind() == idoltext.ValueInt {
+			raw, err := p.integer(field, value)
+			if err != nil {
+				return nil, err
+			}
+			switch type_ {
+			case schema_idl.Type_U8:
+				return uint8(raw), nil
+			case schema_idl.Type_U16:
+				return uint16(raw), nil
+			case schema_idl.Type_U32:
+				return uint32(raw), nil
+			case schema_idl.Type_U64:
+				return raw, nil
+			case schema_idl.Type_I8:
+				return int8(raw), nil
+			case schema_idl.Type_I16:
+				return int16(raw), nil
+			case schema_idl.Type_I32:
+				return int32(raw), nil
+			case schema_idl.Type_I64:
+				return int64(raw), nil
+			}
+		}
+	case schema_idl.Type_F32, schema_idl.Type_F64:
+		if value.Kind() == idoltext.ValueInt || value.Kind() == idoltext.ValueFloat {
+			bitSize := 64
+			if type_ == schema_idl.Type_F32 {
+				bitSize = 32
+			}
+			parsed, err := strconv.ParseFloat(value.Text(), bitSize)
+			if err != nil {
+				return nil, p.errorf(
+					value.Position(),
+					"Invalid %s value '%s'", fmtType(type_), value.Text(),
+				)
+			}
+			if bitSize == 32 {
+				return float32(parsed), nil
+			}
+			return parsed, nil
+		}
+	case schema_idl.Type_TEXT, schema_idl.Type_ASCIZ:
+		if value.Kind() == idoltext.ValueText {
+			text := value.Text()
+			if strings.IndexByte(text, 0x00) >= 0 {
+				return nil, p.errorf(value.Position(), "Text contains NUL byte")
+			}
+			if type_ == schema_idl.Type_ASCIZ {
+				for ii := 0; ii < len(text); ii++ {
+					if text[ii] >= 0x80 {
+						return nil, p.errorf(value.Position(), "Asciz text contains non-ASCII character")
+					}
+				}
+			}
+			return text, nil
+		}
+	}
+	return nil, p.mismatch(field, value)
+}
+
+// integer parses an integer literal, checking that it's in range of the
+// field's type. Signed values are returned sign-extended.
+func (p *textParser) integer(field schema_idl.MessageField, value *idoltext.Value) (uint64, error) {
+	type_ := field.Type()
+	var bitSize int
+	signed := false
+	switch type_ {
+	case schema_idl.Type_U8:
+		bitSize = 8
+	case schema_idl.Type_U16:
+		bitSize = 16
+	case schema_idl.Type_U32:
+		bitSize = 32
+	case schema_idl.Type_U64:
+		bitSize = 64
+	case schema_idl.Type_I8:
+		bitSize, signed = 8, true
+	case schema_idl.Type_I16:
+		bitSize, signed = 16, true
+	case schema_idl.Type_I32:
+		bitSize, signed = 32, true
+	case schema_idl.Type_I64:
+		bitSize, signed = 64, true
+	default:
+		return 0, p.mismatch(field, value)
+	}
+	text := value.Text()
+	if signed {
+		parsed, err := strconv.ParseInt(text, 0, bitSize)
+		if err != nil {
+			return 0, p.integerError(type_, value, err)
+		}
+		return uint64(parsed), nil
+	}
+	parsed, err := strconv.ParseUint(strings.TrimPrefix(text, "+"), 0, bitSize)
+	if err != nil {
+		return 0, p.integerError(type_, value, err)
+	}
+	return parsed, nil
+}
+
+func (p *textParser) integerError(type_ schema_idl.Type, value *idoltext.Value, err error) error {
+	if errors.Is(err, strconv.ErrRange) || strings.HasPrefix(value.Text(), "-") {
+		return p.errorf(value.Position(), "Value %s out of range for %s", value.Text(), fmtType(type_))
+	}
+	return p.errorf(value.Position(), "Invalid %s value '%s'", fmtType(type_), value.Text())
+}
+
+func fmtFieldType(field schema_idl.MessageField) string {
+	typeName := fmtType(field.Type())
+	if name := field.TypeName(); name != "" {
+		_, typeName, _ = strings.Cut(name, "\x1F")
+		if typeName == "" {
+			typeName = name
+		}
+	}
+	if arrayLen := field.ArrayLen(); arrayLen == math.MaxUint32 {
+		typeName += "[]"
+	} else if arrayLen > 0 {
+		typeName += fmt.Sprintf("[%d]", arrayLen)
+	}
+	return typeName
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+// Package idoljson encodes Idol messages as JSON.
+//
+// Messages are encoded as objects keyed by field name, in tag order. Enum
+// values are encoded as their item name, or as a number if the value has no
+// item. Arrays, including arrays of u8, are encoded as JSON arrays.
+// Non-finite floats are encoded as the strings "NaN", "Infinity", and
+// "-Infinity".
+package idoljson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.idol-lang.org/idol"
+)
+
+func Encode[T any](message idol.AsMessage[T]) ([]byte, error) {
+	return EncodeFields(message.Idol__Message().Fields())
+}
+
+// EncodeFields encodes message fields that aren't backed by a generated
+// message type, such as messages decoded with a runtime schema.
+func EncodeFields(fields idol.MessageFields) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := appendMessage(&buf, fields); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func appendMessage(buf *bytes.Buffer, fields idol.MessageFields) error {
+	buf.WriteByte('{')
+	first := true
+	for tag, value := range fields.Values() {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		appendString(buf, fields.Name(tag))
+		buf.WriteByte(':')
+		if err := appendValue(buf, value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func appendValue(buf *bytes.Buffer, value any) error {
+	switch value := value.(type) {
+	case idol.MessageFields:
+		return appendMessage(buf, value)
+	case json.Marshaler:
+		encoded, err := value.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+		return nil
+	case bool:
+		buf.WriteString(strconv.FormatBool(value))
+		return nil
+	case uint8, uint16, uint32, uint64, int8, int16, int32, int64:
+		fmt.Fprint(buf, value)
+		return nil
+	case float32:
+		appendFloat(buf, float64(value), 32)
+		return nil
+	case float64:
+		appendFloat(buf, value, 64)
+		return nil
+	case string:
+		appendString(buf, value)
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+
+	// Idol__Message() Message[T]
+	// Fields() MessageFields
+	if asMsg := rv.MethodByName("Idol__Message"); asMsg.IsValid() {
+		fields := asMsg.Call(nil)[0].Interface().(interface {
+			Fields() idol.MessageFields
+		}).Fields()
+		return appendMessage(buf, fields)
+	}
+
+	if messages := rv.MethodByName("IterMessages"); messages.IsValid() {
+		buf.WriteByte('[')
+		first := true
+		for _, item := range messages.Call(nil)[0].Seq2() {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			itemFields := item.MethodByName("Fields").Call(nil)[0]
+			if err := appendMessage(buf, itemFields.Interface().(idol.MessageFields)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	// Arrays of scalars and text have a Collect() method returning a slice.
+	if collect := rv.MethodByName("Collect"); collect.IsValid() {
+		rv = collect.Call(nil)[0]
+	}
+	if rv.Kind() == reflect.Slice {
+		buf.WriteByte('[')
+		for ii := range rv.Len() {
+			if ii > 0 {
+				buf.WriteByte(',')
+			}
+			if err := appendValue(buf, rv.Index(ii).Interface()); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	// Generated enum types have a String() method returning the item name,
+	// or "Type(value)" for values without an item.
+	switch rv.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if name := fmt.Sprint(value); !strings.Contains(name, "(") {
+			appendString(buf, name)
+			return nil
+		}
+		buf.WriteString(strconv.FormatUint(rv.Uint(), 10))
+		return nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if name := fmt.Sprint(value); !strings.Contains(name, "(") {
+			appendString(buf, name)
+			return nil
+		}
+		buf.WriteString(strconv.FormatInt(rv.Int(), 10))
+		return nil
+	}
+
+	return fmt.Errorf("idoljson: can't encode value of type %T", value)
+}
+
+func appendFloat(buf *bytes.Buffer, value float64, bitSize int) {
+	switch {
+	case math.IsNaN(value):
+		buf.WriteString(`"NaN"`)
+	case math.IsInf(value, 1):
+		buf.WriteString(`"Infinity"`)
+	case math.IsInf(value, -1):
+		buf.WriteString(`"-Infinity"`)
+	default:
+		buf.WriteString(strconv.FormatFloat(value, 'g', -1, bitSize))
+	}
+}
+
+func appendString(buf *bytes.Buffer, value string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(value)
+	// Encode() terminates each value with a newline.
+	buf.Truncate(buf.Len() - 1)
+}
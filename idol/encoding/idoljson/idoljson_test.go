@@ -0,0 +1,53 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package idoljson_test
+
+import (
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/encoding/idoljson"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/syntax"
+)
+
+func TestEncode(t *testing.T) {
+	parsed, err := syntax.Parse([]byte(`namespace "test"
+enum Color : u8 {
+	RED = 1
+}
+message M {
+	name @1 : text
+	tags @2 : text[]
+}
+`))
+	testutil.AssertNoError(t, err)
+	result := compiler.Compile(parsed)
+	for _, err := range result.Errors {
+		t.Fatal(err)
+	}
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+
+	encoded, err := idoljson.Encode(schema)
+	testutil.AssertNoError(t, err)
+	testutil.ExpectEq(t, `{"namespace":"test",`+
+		`"enums":[{"name":"Color","type":"U8","items":[{"name":"RED","value":1}]}],`+
+		`"messages":[{"name":"M","fields":[`+
+		`{"name":"name","tag":1,"type":"TEXT"},`+
+		`{"name":"tags","tag":2,"type":"TEXT","array_len":4294967295}`+
+		`]}]}`, string(encoded))
+}
@@ -32,11 +32,28 @@ func Encode[T any](message idol.AsMessage[T]) string {
 }
 
 func EncodeTo[T any](message idol.AsMessage[T], w io.Writer) error {
+	return EncodeFieldsTo(message.Idol__Message().Fields(), w)
+}
+
+// EncodeFields encodes message fields that aren't backed by a generated
+// message type, such as messages decoded with a runtime schema.
+func EncodeFields(fields idol.MessageFields) string {
+	var buf strings.Builder
+	EncodeFieldsTo(fields, &buf)
+	return buf.String()
+}
+
+func EncodeFieldsTo(fields idol.MessageFields, w io.Writer) error {
 	e := encoder{w: w}
-	e.visitMessage(message.Idol__Message().Fields())
+	e.visitMessage(fields)
 	return e.err
 }
 
+// A Marshaler is a value that can encode itself as idoltext.
+type Marshaler interface {
+	MarshalIdolText() string
+}
+
 type encoder struct {
 	w      io.Writer
 	indent int
@@ -81,14 +98,17 @@ func (e *encoder) visitField(name string, value any) {
 	}
 
 	if value, ok := value.(idol.Uint8Array); ok {
-		var buf strings.Builder
-		for ii, b := range value.Iter() {
-			if ii != 0 {
-				buf.WriteString(", ")
-			}
-			fmt.Fprintf(&buf, "0x%02X", b)
-		}
-		e.linef("%s = [%s]", name, buf.String())
+		e.linef("%s = [%s]", name, fmtBytes(value.Collect()))
+		return
+	}
+	if value, ok := value.([]uint8); ok {
+		e.linef("%s = [%s]", name, fmtBytes(value))
+		return
+	}
+
+	switch value := value.(type) {
+	case idol.Uint16Array, idol.Uint64Array:
+		e.linef("%s = %s", name, value)
 		return
 	}
 
@@ -102,6 +122,25 @@ func (e *encoder) visitField(name string, value any) {
 		e.line("]")
 		return
 	}
+	if value, ok := value.([]string); ok {
+		e.linef("%s = [", name)
+		e.indent += 1
+		for _, item := range value {
+			e.line(quote(item))
+		}
+		e.indent -= 1
+		e.line("]")
+		return
+	}
+
+	if fields, ok := value.(idol.MessageFields); ok {
+		e.linef("%s = {", name)
+		e.indent += 1
+		e.visitMessage(fields)
+		e.indent -= 1
+		e.line("}")
+		return
+	}
 
 	// Idol__Message() Message[T]
 	// Fields() MessageFields
@@ -136,6 +175,31 @@ func (e *encoder) visitField(name string, value any) {
 		return
 	}
 
+	if slice := reflect.ValueOf(value); slice.Kind() == reflect.Slice {
+		if slice.Type().Elem().Implements(messageFieldsType) {
+			for ii := range slice.Len() {
+				e.linef("%s {", name)
+				e.indent += 1
+				e.visitMessage(slice.Index(ii).Interface().(idol.MessageFields))
+				e.indent -= 1
+				e.line("}")
+			}
+			return
+		}
+		items := make([]string, 0, slice.Len())
+		for ii := range slice.Len() {
+			item := fmtScalar(slice.Index(ii).Interface())
+			if item == "" {
+				break
+			}
+			items = append(items, item)
+		}
+		if len(items) == slice.Len() {
+			e.linef("%s = [%s]", name, strings.Join(items, ", "))
+			return
+		}
+	}
+
 	panic(fmt.Sprintf("fmtField: unhandled value %s (%T)", value, value))
 }
 
@@ -162,8 +226,14 @@ func fmtScalar(value any) string {
 		return strconv.FormatInt(int64(value), 10)
 	case int64:
 		return strconv.FormatInt(value, 10)
+	case float32:
+		return strconv.FormatFloat(float64(value), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
 	case string:
 		return quote(value)
+	case Marshaler:
+		return value.MarshalIdolText()
 	}
 
 	switch reflect.TypeOf(value).Kind() {
@@ -176,6 +246,19 @@ func fmtScalar(value any) string {
 	return ""
 }
 
+var messageFieldsType = reflect.TypeFor[idol.MessageFields]()
+
+func fmtBytes(value []uint8) string {
+	var buf strings.Builder
+	for ii, b := range value {
+		if ii != 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "0x%02X", b)
+	}
+	return buf.String()
+}
+
 func quote(text string) string {
 	var buf strings.Builder
 	buf.WriteByte('"')
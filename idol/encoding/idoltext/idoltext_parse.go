@@ -0,0 +1,461 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package idoltext
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Position is the 1-based line and column of a token in idoltext input.
+type Position struct {
+	line   int
+	column int
+}
+
+func (pos Position) Line() int {
+	return pos.line
+}
+
+func (pos Position) Column() int {
+	return pos.column
+}
+
+func (pos Position) String() string {
+	return fmt.Sprintf("%d:%d", pos.line, pos.column)
+}
+
+// A Field is a field of a parsed message, either an assignment
+// ("name = value") or a block ("name { ... }"). Blocks are used for the
+// items of message arrays.
+type Field struct {
+	name    string
+	value   *Value
+	isBlock bool
+	pos     Position
+}
+
+func (f *Field) Name() string {
+	return f.name
+}
+
+func (f *Field) Value() *Value {
+	return f.value
+}
+
+func (f *Field) IsBlock() bool {
+	return f.isBlock
+}
+
+func (f *Field) Position() Position {
+	return f.pos
+}
+
+type ValueKind uint8
+
+const (
+	ValueInt ValueKind = iota + 1
+	ValueFloat
+	ValueText
+	ValueEnumRef
+	ValueList
+	ValueMessage
+)
+
+func (k ValueKind) String() string {
+	switch k {
+	case ValueInt:
+		return "integer"
+	case ValueFloat:
+		return "float"
+	case ValueText:
+		return "text"
+	case ValueEnumRef:
+		return "enum item"
+	case ValueList:
+		return "list"
+	case ValueMessage:
+		return "message"
+	}
+	return fmt.Sprintf("ValueKind(%d)", k)
+}
+
+// A Value is a parsed field value. Its schema type isn't known until it's
+// matched against a schema, so numbers are kept in their literal form.
+type Value struct {
+	kind   ValueKind
+	text   string
+	items  []*Value
+	fields []*Field
+	pos    Position
+}
+
+func (v *Value) Kind() ValueKind {
+	return v.kind
+}
+
+// Text returns the literal of an integer or float, the content of a text
+// value, or the item name of an enum reference (without the leading '.').
+func (v *Value) Text() string {
+	return v.text
+}
+
+func (v *Value) Items() []*Value {
+	return v.items
+}
+
+func (v *Value) Fields() []*Field {
+	return v.fields
+}
+
+func (v *Value) Position() Position {
+	return v.pos
+}
+
+type ParseError struct {
+	pos     Position
+	message string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", err.pos, err.message)
+}
+
+func (err *ParseError) Position() Position {
+	return err.pos
+}
+
+func (err *ParseError) Message() string {
+	return err.message
+}
+
+// Parse parses the fields of a message in the text encoding.
+func Parse(src []uint8) ([]*Field, error) {
+	p := &parser{src: string(src), line: 1, column: 1}
+	fields, err := p.fields()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok.pos, "Unexpected %s", tok)
+	}
+	return fields, nil
+}
+
+type tokenKind uint8
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenText
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  Position
+}
+
+func (tok token) isPunct(punct string) bool {
+	return tok.kind == tokenPunct && tok.text == punct
+}
+
+func (tok token) String() string {
+	switch tok.kind {
+	case tokenEOF:
+		return "end of input"
+	case tokenText:
+		return "text"
+	}
+	return fmt.Sprintf("'%s'", tok.text)
+}
+
+type parser struct {
+	src    string
+	off    int
+	line   int
+	column int
+	next   *token
+	err    error
+}
+
+func (p *parser) errorf(pos Position, format string, args ...any) error {
+	return &ParseError{pos: pos, message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) fields() ([]*Field, error) {
+	var fields []*Field
+	for {
+		tok := p.peek()
+		if p.err != nil {
+			return nil, p.err
+		}
+		if tok.kind == tokenEOF || tok.isPunct("}") {
+			return fields, nil
+		}
+		field, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+}
+
+func (p *parser) field() (*Field, error) {
+	name := p.take()
+	if name.kind != tokenIdent {
+		return nil, p.unexpected(name, "field name")
+	}
+	field := &Field{name: name.text, pos: name.pos}
+	switch tok := p.take(); {
+	case tok.isPunct("="):
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		field.value = value
+	case tok.isPunct("{"):
+		value, err := p.message(tok.pos)
+		if err != nil {
+			return nil, err
+		}
+		field.value = value
+		field.isBlock = true
+	default:
+		return nil, p.unexpected(tok, "'=' or '{'")
+	}
+	return field, nil
+}
+
+func (p *parser) message(pos Position) (*Value, error) {
+	fields, err := p.fields()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.take(); !tok.isPunct("}") {
+		return nil, p.unexpected(tok, "'}'")
+	}
+	return &Value{kind: ValueMessage, fields: fields, pos: pos}, nil
+}
+
+func (p *parser) value() (*Value, error) {
+	tok := p.take()
+	if p.err != nil {
+		return nil, p.err
+	}
+	switch tok.kind {
+	case tokenNumber:
+		kind := ValueInt
+		if !strings.HasPrefix(strings.TrimLeft(tok.text, "+-"), "0x") &&
+			strings.ContainsAny(tok.text, ".eE") {
+			kind = ValueFloat
+		}
+		return &Value{kind: kind, text: tok.text, pos: tok.pos}, nil
+	case tokenText:
+		return &Value{kind: ValueText, text: tok.text, pos: tok.pos}, nil
+	}
+	switch {
+	case tok.isPunct("."):
+		name := p.take()
+		if name.kind != tokenIdent {
+			return nil, p.unexpected(name, "enum item name")
+		}
+		return &Value{kind: ValueEnumRef, text: name.text, pos: tok.pos}, nil
+	case tok.isPunct("{"):
+		return p.message(tok.pos)
+	case tok.isPunct("["):
+		list := &Value{kind: ValueList, pos: tok.pos}
+		for {
+			if next := p.peek(); next.isPunct("]") {
+				p.take()
+				return list, nil
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, item)
+			if next := p.peek(); next.isPunct(",") {
+				p.take()
+			}
+		}
+	}
+	return nil, p.unexpected(tok, "value")
+}
+
+func (p *parser) unexpected(tok token, expected string) error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.errorf(tok.pos, "Expected %s, found %s", expected, tok)
+}
+
+func (p *parser) peek() token {
+	if p.next == nil {
+		tok := p.lex()
+		p.next = &tok
+	}
+	return *p.next
+}
+
+func (p *parser) take() token {
+	tok := p.peek()
+	p.next = nil
+	return tok
+}
+
+func (p *parser) advance(n int) {
+	for _, c := range p.src[p.off : p.off+n] {
+		if c == '\n' {
+			p.line += 1
+			p.column = 1
+		} else {
+			p.column += 1
+		}
+	}
+	p.off += n
+}
+
+func (p *parser) lex() token {
+	for p.off < len(p.src) {
+		c := p.src[p.off]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			p.advance(1)
+			continue
+		}
+		if c == '#' {
+			end := strings.IndexByte(p.src[p.off:], '\n')
+			if end < 0 {
+				end = len(p.src) - p.off
+			}
+			p.advance(end)
+			continue
+		}
+		break
+	}
+	pos := Position{p.line, p.column}
+	if p.off == len(p.src) {
+		return token{kind: tokenEOF, pos: pos}
+	}
+
+	c := p.src[p.off]
+	switch {
+	case isIdentStart(c):
+		end := p.off + 1
+		for end < len(p.src) && isIdentContinue(p.src[end]) {
+			end += 1
+		}
+		text := p.src[p.off:end]
+		p.advance(end - p.off)
+		return token{kind: tokenIdent, text: text, pos: pos}
+	case isDigit(c) || ((c == '-' || c == '+') && p.off+1 < len(p.src) && isDigit(p.src[p.off+1])):
+		end := p.off + 1
+		for end < len(p.src) {
+			c := p.src[end]
+			if isIdentContinue(c) || c == '.' {
+				end += 1
+				continue
+			}
+			if (c == '-' || c == '+') && (p.src[end-1] == 'e' || p.src[end-1] == 'E') {
+				end += 1
+				continue
+			}
+			break
+		}
+		text := p.src[p.off:end]
+		p.advance(end - p.off)
+		return token{kind: tokenNumber, text: text, pos: pos}
+	case c == '"':
+		text, n, err := unquote(p.src[p.off:], pos)
+		if err != nil {
+			p.err = err
+			return token{kind: tokenEOF, pos: pos}
+		}
+		p.advance(n)
+		return token{kind: tokenText, text: text, pos: pos}
+	case strings.IndexByte("={}[],.", c) >= 0:
+		p.advance(1)
+		return token{kind: tokenPunct, text: string(c), pos: pos}
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.off:])
+	p.err = p.errorf(pos, "Unexpected character %q", r)
+	return token{kind: tokenEOF, pos: pos}
+}
+
+// unquote decodes a quoted text literal at the start of src, returning its
+// content and the length of the literal.
+func unquote(src string, pos Position) (string, int, error) {
+	var buf strings.Builder
+	for ii := 1; ii < len(src); {
+		c := src[ii]
+		switch c {
+		case '"':
+			if !utf8.ValidString(buf.String()) {
+				return "", 0, &ParseError{pos, "Text is not valid UTF-8"}
+			}
+			return buf.String(), ii + 1, nil
+		case '\n':
+			return "", 0, &ParseError{pos, "Unterminated text"}
+		case '\\':
+			if ii+1 >= len(src) {
+				return "", 0, &ParseError{pos, "Unterminated text"}
+			}
+			switch src[ii+1] {
+			case '\\', '"':
+				buf.WriteByte(src[ii+1])
+				ii += 2
+			case 't':
+				buf.WriteByte('\t')
+				ii += 2
+			case 'n':
+				buf.WriteByte('\n')
+				ii += 2
+			case 'x':
+				if ii+4 > len(src) {
+					return "", 0, &ParseError{pos, "Invalid '\\x' escape in text"}
+				}
+				value, err := strconv.ParseUint(src[ii+2:ii+4], 16, 8)
+				if err != nil {
+					return "", 0, &ParseError{pos, "Invalid '\\x' escape in text"}
+				}
+				buf.WriteByte(byte(value))
+				ii += 4
+			default:
+				return "", 0, &ParseError{pos, fmt.Sprintf("Invalid escape '\\%c' in text", src[ii+1])}
+			}
+		default:
+			buf.WriteByte(c)
+			ii += 1
+		}
+	}
+	return "", 0, &ParseError{pos, "Unterminated text"}
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isIdentContinue(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+// Package fingerprint computes stable hashes of compiled schemas.
+//
+// A fingerprint is computed from the canonical form of a schema or
+// declaration, which excludes doc comments, source information, imports, and
+// options that don't affect the wire format (such as "deprecated"), and lists declarations and their items in a deterministic order. Schemas
+// that differ only in those details have the same fingerprint.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"maps"
+	"slices"
+	"strings"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/schema_idl"
+)
+
+// Bump when the canonical form changes, so that fingerprints computed by
+// different versions never compare equal by accident.
+const fingerprintVersion = "idol-fingerprint-v2"
+
+// Schema returns the fingerprint of a schema's canonical form.
+func Schema(schema schema_idl.Schema) (idol.Fingerprint, error) {
+	encoded, err := idol.Encode(&idol.EncodeCtx{}, Canonical(schema))
+	if err != nil {
+		return idol.Fingerprint{}, err
+	}
+	h := sha256.New()
+	writeField(h, []byte(fingerprintVersion))
+	writeField(h, encoded)
+	return sum(h), nil
+}
+
+// Decl returns the fingerprint of a named declaration in a schema.
+//
+// The fingerprint covers the canonical form of the declaration and of every
+// declaration it references, directly or indirectly, so a change to a nested
+// type also changes the fingerprint of each message containing it. Imported
+// types are covered if their schema is in deps, and are otherwise identified
+// only by their fully-qualified name.
+func Decl(
+	schema schema_idl.Schema,
+	name string,
+	deps ...schema_idl.Schema,
+) (idol.Fingerprint, error) {
+	decls, err := canonicalDecls(schema)
+	if err != nil {
+		return idol.Fingerprint{}, err
+	}
+	if err := addDepDecls(decls, deps); err != nil {
+		return idol.Fingerprint{}, err
+	}
+	key := declKey(schema.Namespace(), name)
+	if _, ok := decls[key]; !ok {
+		return idol.Fingerprint{}, fmt.Errorf(
+			"declaration '%s' not found in namespace \"%s\"",
+			name, schema.Namespace(),
+		)
+	}
+	return declFingerprint(decls, key), nil
+}
+
+// Decls returns the fingerprint of every declaration in a schema. Imported
+// types are handled as in Decl.
+func Decls(
+	schema schema_idl.Schema,
+	deps ...schema_idl.Schema,
+) (map[string]idol.Fingerprint, error) {
+	decls, err := canonicalDecls(schema)
+	if err != nil {
+		return nil, err
+	}
+	localKeys := slices.Collect(maps.Keys(decls))
+	if err := addDepDecls(decls, deps); err != nil {
+		return nil, err
+	}
+	fingerprints := make(map[string]idol.Fingerprint, len(localKeys))
+	for _, key := range localKeys {
+		fingerprints[decls[key].name] = declFingerprint(decls, key)
+	}
+	return fingerprints, nil
+}
+
+// declKey returns the fully-qualified name of a declaration, in the same form
+// as the type names of imported types.
+func declKey(namespace, name string) string {
+	return namespace + "\x1F" + name
+}
+
+func declFingerprint(decls map[string]*canonicalDecl, key string) idol.Fingerprint {
+	closure := make(map[string]struct{})
+	var visit func(key string)
+	visit = func(key string) {
+		decl, ok := decls[key]
+		if !ok {
+			return
+		}
+		if _, seen := closure[key]; seen {
+			return
+		}
+		closure[key] = struct{}{}
+		for _, ref := range decl.refs {
+			visit(ref)
+		}
+	}
+	visit(key)
+
+	h := sha256.New()
+	writeField(h, []byte(fingerprintVersion))
+	writeField(h, []byte(key))
+	for _, closureKey := range slices.Sorted(maps.Keys(closure)) {
+		writeField(h, []byte(closureKey))
+		writeField(h, decls[closureKey].encoded)
+	}
+	return sum(h)
+}
+
+type canonicalDecl struct {
+	name    string
+	encoded []byte
+	refs    []string
+}
+
+// addDepDecls adds the canonical declarations of dependencies to decls. If
+// several schemas declare the same name, the first is used.
+func addDepDecls(decls map[string]*canonicalDecl, deps []schema_idl.Schema) error {
+	for _, dep := range deps {
+		depDecls, err := canonicalDecls(dep)
+		if err != nil {
+			return err
+		}
+		for key, decl := range depDecls {
+			if _, ok := decls[key]; !ok {
+				decls[key] = decl
+			}
+		}
+	}
+	return nil
+}
+
+func canonicalDecls(schema schema_idl.Schema) (map[string]*canonicalDecl, error) {
+	decls := make(map[string]*canonicalDecl)
+	var err error
+	add := func(name string, encoded []byte, encodeErr error, typeNames ...string) {
+		if encodeErr != nil {
+			err = encodeErr
+			return
+		}
+		decl := &canonicalDecl{name: name, encoded: encoded}
+		for _, typeName := range typeNames {
+			switch {
+			case typeName == "":
+			case strings.Contains(typeName, "\x1F"):
+				decl.refs = append(decl.refs, typeName)
+			default:
+				decl.refs = append(decl.refs, declKey(schema.Namespace(), typeName))
+			}
+		}
+		decls[declKey(schema.Namespace(), name)] = decl
+	}
+	ctx := &idol.EncodeCtx{}
+
+	for _, const_ := range schema.Consts().Iter() {
+		encoded, err := idol.Encode(ctx, CanonicalConst(const_))
+		add(const_.Name(), encoded, err, const_.TypeName())
+	}
+	for _, enum := range schema.Enums().Iter() {
+		encoded, err := idol.Encode(ctx, CanonicalEnum(enum))
+		add(enum.Name(), encoded, err)
+	}
+	for _, struct_ := range schema.Structs().Iter() {
+		encoded, err := idol.Encode(ctx, CanonicalStruct(struct_))
+		var refs []string
+		for _, field := range struct_.Fields().Iter() {
+			refs = append(refs, field.TypeName())
+		}
+		add(struct_.Name(), encoded, err, refs...)
+	}
+	for _, msg := range schema.Messages().Iter() {
+		encoded, err := idol.Encode(ctx, CanonicalMessage(msg))
+		var refs []string
+		for _, field := range msg.Fields().Iter() {
+			refs = append(refs, field.TypeName())
+		}
+		add(msg.Name(), encoded, err, refs...)
+	}
+	for _, union := range schema.Unions().Iter() {
+		encoded, err := idol.Encode(ctx, CanonicalUnion(union))
+		var refs []string
+		for _, field := range union.Fields().Iter() {
+			refs = append(refs, field.TypeName())
+		}
+		add(union.Name(), encoded, err, refs...)
+	}
+	for _, protocol := range schema.Protocols().Iter() {
+		encoded, err := idol.Encode(ctx, CanonicalProtocol(protocol))
+		var refs []string
+		for _, rpc := range protocol.Rpcs().Iter() {
+			refs = append(refs, rpc.RequestTypeName(), rpc.ResponseTypeName())
+		}
+		for _, event := range protocol.Events().Iter() {
+			refs = append(refs, event.PayloadTypeName())
+		}
+		add(protocol.Name(), encoded, err, refs...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decls, nil
+}
+
+func writeField(h hash.Hash, value []byte) {
+	var lenBuf [8]byte
+	binary.LittleEndian.PutUint64(lenBuf[:], uint64(len(value)))
+	h.Write(lenBuf[:])
+	h.Write(value)
+}
+
+func sum(h hash.Hash) idol.Fingerprint {
+	var f idol.Fingerprint
+	h.Sum(f[:0])
+	return f
+}
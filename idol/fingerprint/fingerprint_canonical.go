@@ -0,0 +1,239 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package fingerprint
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/schema_idl"
+)
+
+// Canonical returns the canonical form of a schema. Doc comments, source
+// information, imports, and the "deprecated" option are removed, declarations are sorted by name, and
+// exports are sorted by their exported name.
+//
+// Within declarations, message and union fields are sorted by tag, enum
+// items by value, and protocol RPCs and events by tag. Struct fields are
+// kept in declaration order because it determines the struct's layout.
+func Canonical(schema schema_idl.Schema) *schema_idl.Schema__Builder {
+	b := idol.Clone(schema).Self().(*schema_idl.Schema__Builder)
+	b.SourcePath.Set(nil)
+	b.Imports.Clear()
+
+	exports := schema.Exports().Collect()
+	slices.SortFunc(exports, func(x, y schema_idl.Export) int {
+		return cmp.Or(
+			strings.Compare(x.ExportAs(), y.ExportAs()),
+			strings.Compare(x.TypeName(), y.TypeName()),
+		)
+	})
+	b.Exports.Clear()
+	for _, export := range exports {
+		b.Exports.Add(idol.Clone(export).Self())
+	}
+
+	b.Consts.Clear()
+	b.Enums.Clear()
+	b.Structs.Clear()
+	b.Messages.Clear()
+	b.Unions.Clear()
+	b.Protocols.Clear()
+	for _, const_ := range sortedByName(schema.Consts().Collect()) {
+		b.Consts.Add(CanonicalConst(const_))
+	}
+	for _, enum := range sortedByName(schema.Enums().Collect()) {
+		b.Enums.Add(CanonicalEnum(enum))
+	}
+	for _, struct_ := range sortedByName(schema.Structs().Collect()) {
+		b.Structs.Add(CanonicalStruct(struct_))
+	}
+	for _, msg := range sortedByName(schema.Messages().Collect()) {
+		b.Messages.Add(CanonicalMessage(msg))
+	}
+	for _, union := range sortedByName(schema.Unions().Collect()) {
+		b.Unions.Add(CanonicalUnion(union))
+	}
+	for _, protocol := range sortedByName(schema.Protocols().Collect()) {
+		b.Protocols.Add(CanonicalProtocol(protocol))
+	}
+	return b
+}
+
+func CanonicalConst(const_ schema_idl.Const) *schema_idl.Const__Builder {
+	b := idol.Clone(const_).Self().(*schema_idl.Const__Builder)
+	b.Doc.Set("")
+	b.SourceSpan.Clear()
+	return b
+}
+
+func CanonicalEnum(enum schema_idl.Enum) *schema_idl.Enum__Builder {
+	b := idol.Clone(enum).Self().(*schema_idl.Enum__Builder)
+	b.Doc.Set("")
+	b.SourceSpan.Clear()
+
+	items := enum.Items().Collect()
+	slices.SortFunc(items, func(x, y schema_idl.EnumItem) int {
+		return cmp.Or(
+			cmp.Compare(x.Value(), y.Value()),
+			strings.Compare(x.Name(), y.Name()),
+		)
+	})
+	b.Items.Clear()
+	for _, item := range items {
+		itemBuilder := idol.Clone(item).Self().(*schema_idl.EnumItem__Builder)
+		itemBuilder.Doc.Set("")
+		itemBuilder.SourceSpan.Clear()
+		stripDeprecated(&itemBuilder.Options)
+		b.Items.Add(itemBuilder)
+	}
+	b.ReservedValues.Set(slices.Sorted(slices.Values(enum.ReservedValues().Collect())))
+	b.ReservedNames.Set(slices.Sorted(slices.Values(enum.ReservedNames().Collect())))
+	return b
+}
+
+func CanonicalStruct(struct_ schema_idl.Struct) *schema_idl.Struct__Builder {
+	b := idol.Clone(struct_).Self().(*schema_idl.Struct__Builder)
+	b.Doc.Set("")
+	b.SourceSpan.Clear()
+
+	b.Fields.Clear()
+	for _, field := range struct_.Fields().Iter() {
+		fieldBuilder := idol.Clone(field).Self().(*schema_idl.StructField__Builder)
+		fieldBuilder.Doc.Set("")
+		fieldBuilder.SourceSpan.Clear()
+		b.Fields.Add(fieldBuilder)
+	}
+	return b
+}
+
+func CanonicalMessage(msg schema_idl.Message) *schema_idl.Message__Builder {
+	b := idol.Clone(msg).Self().(*schema_idl.Message__Builder)
+	b.Doc.Set("")
+	b.SourceSpan.Clear()
+	stripDeprecated(&b.Options)
+
+	fields := msg.Fields().Collect()
+	slices.SortFunc(fields, func(x, y schema_idl.MessageField) int {
+		return cmp.Compare(x.Tag(), y.Tag())
+	})
+	b.Fields.Clear()
+	for _, field := range fields {
+		fieldBuilder := idol.Clone(field).Self().(*schema_idl.MessageField__Builder)
+		fieldBuilder.Doc.Set("")
+		fieldBuilder.SourceSpan.Clear()
+		stripDeprecated(&fieldBuilder.Options)
+		b.Fields.Add(fieldBuilder)
+	}
+	b.ReservedTags.Set(slices.Sorted(slices.Values(msg.ReservedTags().Collect())))
+	b.ReservedNames.Set(slices.Sorted(slices.Values(msg.ReservedNames().Collect())))
+	return b
+}
+
+func CanonicalUnion(union schema_idl.Union) *schema_idl.Union__Builder {
+	b := idol.Clone(union).Self().(*schema_idl.Union__Builder)
+	b.Doc.Set("")
+	b.SourceSpan.Clear()
+
+	fields := union.Fields().Collect()
+	slices.SortFunc(fields, func(x, y schema_idl.UnionField) int {
+		return cmp.Compare(x.Tag(), y.Tag())
+	})
+	b.Fields.Clear()
+	for _, field := range fields {
+		fieldBuilder := idol.Clone(field).Self().(*schema_idl.UnionField__Builder)
+		fieldBuilder.Doc.Set("")
+		fieldBuilder.SourceSpan.Clear()
+		b.Fields.Add(fieldBuilder)
+	}
+	b.ReservedTags.Set(slices.Sorted(slices.Values(union.ReservedTags().Collect())))
+	b.ReservedNames.Set(slices.Sorted(slices.Values(union.ReservedNames().Collect())))
+	return b
+}
+
+func CanonicalProtocol(protocol schema_idl.Protocol) *schema_idl.Protocol__Builder {
+	b := idol.Clone(protocol).Self().(*schema_idl.Protocol__Builder)
+	b.Doc.Set("")
+	b.SourceSpan.Clear()
+
+	rpcs := protocol.Rpcs().Collect()
+	slices.SortFunc(rpcs, func(x, y schema_idl.ProtocolRpc) int {
+		return compareProtocolTags(x, y)
+	})
+	b.Rpcs.Clear()
+	for _, rpc := range rpcs {
+		rpcBuilder := idol.Clone(rpc).Self().(*schema_idl.ProtocolRpc__Builder)
+		rpcBuilder.Doc.Set("")
+		rpcBuilder.SourceSpan.Clear()
+		stripDeprecated(&rpcBuilder.Options)
+		b.Rpcs.Add(rpcBuilder)
+	}
+
+	events := protocol.Events().Collect()
+	slices.SortFunc(events, func(x, y schema_idl.ProtocolEvent) int {
+		return compareProtocolTags(x, y)
+	})
+	b.Events.Clear()
+	for _, event := range events {
+		eventBuilder := idol.Clone(event).Self().(*schema_idl.ProtocolEvent__Builder)
+		eventBuilder.Doc.Set("")
+		eventBuilder.SourceSpan.Clear()
+		stripDeprecated(&eventBuilder.Options)
+		b.Events.Add(eventBuilder)
+	}
+	return b
+}
+
+// stripDeprecated removes the "deprecated" option, which doesn't affect the
+// wire format. Options left empty are removed, so that deprecating a
+// declaration doesn't change its canonical form.
+func stripDeprecated[T interface{ Idol__Message() idol.Message[T] }](
+	options *idol.MessageFieldBuilder[T],
+) {
+	if !options.IsPresent() {
+		return
+	}
+	switch b := any(options.Get()).(type) {
+	case *schema_idl.MessageOptions__Builder:
+		b.Deprecated.Set(false)
+	case *schema_idl.MessageFieldOptions__Builder:
+		b.Deprecated.Set(false)
+	case *schema_idl.EnumItemOptions__Builder:
+		b.Deprecated.Set(false)
+	case *schema_idl.ProtocolRpcOptions__Builder:
+		b.Deprecated.Set(false)
+	case *schema_idl.ProtocolEventOptions__Builder:
+		b.Deprecated.Set(false)
+	}
+	if options.DataSize() == 0 {
+		options.Clear()
+	}
+}
+
+func sortedByName[T interface{ Name() string }](decls []T) []T {
+	slices.SortFunc(decls, func(x, y T) int {
+		return strings.Compare(x.Name(), y.Name())
+	})
+	return decls
+}
+
+func compareProtocolTags[T interface{ Tag() (uint64, bool) }](x, y T) int {
+	xTag, _ := x.Tag()
+	yTag, _ := y.Tag()
+	return cmp.Compare(xTag, yTag)
+}
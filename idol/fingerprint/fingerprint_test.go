@@ -0,0 +1,238 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package fingerprint_test
+
+import (
+	"testing"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/fingerprint"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+func compileSchema(t *testing.T, src string) schema_idl.Schema {
+	t.Helper()
+	parsed, err := syntax.Parse([]byte(src))
+	testutil.AssertNoError(t, err)
+	result := compiler.Compile(parsed, compiler.WithSourceInfo(true))
+	for _, err := range result.Errors {
+		t.Fatal(err)
+	}
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+	return schema
+}
+
+func fingerprints(t *testing.T, src string) (idol.Fingerprint, map[string]idol.Fingerprint) {
+	t.Helper()
+	schema := compileSchema(t, src)
+	schemaFingerprint, err := fingerprint.Schema(schema)
+	testutil.AssertNoError(t, err)
+	declFingerprints, err := fingerprint.Decls(schema)
+	testutil.AssertNoError(t, err)
+	return schemaFingerprint, declFingerprints
+}
+
+const baseSchema = `namespace "test"
+
+enum E : u8 {
+	A = 1
+	B = 2
+}
+
+message Inner {
+	e @1 : E
+}
+
+message Outer {
+	a @1 : u32
+	inner @2 : Inner
+}
+
+message Other {
+	a @1 : u32
+}
+`
+
+func TestFingerprint_IgnoresDocsAndOrder(t *testing.T) {
+	reordered := `namespace "test"
+
+## Other message.
+message Other {
+	a @1 : u32
+}
+
+## Outer message.
+message Outer {
+	inner @2 : Inner
+	a @1 : u32
+}
+
+message Inner {
+	e @1 : E
+}
+
+enum E : u8 {
+	B = 2
+	A = 1
+}
+`
+	prevSchema, prevDecls := fingerprints(t, baseSchema)
+	nextSchema, nextDecls := fingerprints(t, reordered)
+	testutil.ExpectEq(t, prevSchema, nextSchema)
+	for _, name := range []string{"E", "Inner", "Outer", "Other"} {
+		testutil.ExpectEq(t, prevDecls[name], nextDecls[name])
+	}
+}
+
+func TestFingerprint_NestedChange(t *testing.T) {
+	changed := `namespace "test"
+
+enum E : u8 {
+	A = 1
+	B = 2
+	C = 3
+}
+
+message Inner {
+	e @1 : E
+}
+
+message Outer {
+	a @1 : u32
+	inner @2 : Inner
+}
+
+message Other {
+	a @1 : u32
+}
+`
+	prevSchema, prevDecls := fingerprints(t, baseSchema)
+	nextSchema, nextDecls := fingerprints(t, changed)
+	testutil.ExpectTrue(t, prevSchema != nextSchema)
+	testutil.ExpectTrue(t, prevDecls["E"] != nextDecls["E"])
+	testutil.ExpectTrue(t, prevDecls["Inner"] != nextDecls["Inner"])
+	testutil.ExpectTrue(t, prevDecls["Outer"] != nextDecls["Outer"])
+	testutil.ExpectEq(t, prevDecls["Other"], nextDecls["Other"])
+}
+
+func TestFingerprint_IgnoresDeprecated(t *testing.T) {
+	schema := `namespace "test"
+
+enum E : u8 {
+	A = 1
+	B = 2
+}
+
+message M {
+	a @1 : u32
+	e @2 : E
+}
+
+protocol P {
+	rpc Get @1(M) : M
+	event Changed @2: M
+}
+`
+	deprecated := `namespace "test"
+
+enum E : u8 {
+	A = 1
+	@{deprecated}
+	B = 2
+}
+
+@{deprecated}
+message M {
+	@{deprecated}
+	a @1 : u32
+	e @2 : E
+}
+
+protocol P {
+	@{deprecated}
+	rpc Get @1(M) : M
+	@{deprecated}
+	event Changed @2: M
+}
+`
+	prevSchema, prevDecls := fingerprints(t, schema)
+	nextSchema, nextDecls := fingerprints(t, deprecated)
+	testutil.ExpectEq(t, prevSchema, nextSchema)
+	for _, name := range []string{"E", "M", "P"} {
+		testutil.ExpectEq(t, prevDecls[name], nextDecls[name])
+	}
+}
+
+func TestFingerprint_ImportedTypes(t *testing.T) {
+	depV1 := compileSchema(t, "namespace \"dep\"\nmessage D {\n\ta @1 : u8\n}\n")
+	depV2 := compileSchema(t, "namespace \"dep\"\nmessage D {\n\ta @1 : u16\n}\n")
+	src := `namespace "test"
+import "dep" { D }
+
+message M {
+	d @1 : D
+}
+
+message Other {
+	a @1 : u32
+}
+`
+	parsed, err := syntax.Parse([]byte(src))
+	testutil.AssertNoError(t, err)
+	declFingerprints := func(dep schema_idl.Schema) map[string]idol.Fingerprint {
+		t.Helper()
+		deps, err := compiler.Merge([]schema_idl.Schema{dep})
+		testutil.AssertNoError(t, err)
+		result := compiler.Compile(parsed, compiler.WithDependencies(deps))
+		for _, err := range result.Errors {
+			t.Fatal(err)
+		}
+		schema, err := result.Schema()
+		testutil.AssertNoError(t, err)
+		decls, err := fingerprint.Decls(schema, dep)
+		testutil.AssertNoError(t, err)
+
+		// Without the dependency, only the imported name is covered.
+		withoutDeps, err := fingerprint.Decls(schema)
+		testutil.AssertNoError(t, err)
+		testutil.ExpectTrue(t, decls["M"] != withoutDeps["M"])
+		testutil.ExpectEq(t, decls["Other"], withoutDeps["Other"])
+
+		decl, err := fingerprint.Decl(schema, "M", dep)
+		testutil.AssertNoError(t, err)
+		testutil.ExpectEq(t, decls["M"], decl)
+		return decls
+	}
+	prevDecls := declFingerprints(depV1)
+	nextDecls := declFingerprints(depV2)
+	testutil.ExpectTrue(t, prevDecls["M"] != nextDecls["M"])
+	testutil.ExpectEq(t, prevDecls["Other"], nextDecls["Other"])
+}
+
+func TestParseFingerprint(t *testing.T) {
+	_, decls := fingerprints(t, baseSchema)
+	want := decls["Outer"]
+	got, err := idol.ParseFingerprint(want.String())
+	testutil.AssertNoError(t, err)
+	testutil.ExpectEq(t, want, got)
+
+	_, err = idol.ParseFingerprint("not-a-fingerprint")
+	testutil.AssertError(t, err)
+}
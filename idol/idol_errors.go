@@ -44,3 +44,40 @@ func errTODO() error {
 		message: "idol_error_todo",
 	}
 }
+
+func errFingerprintMismatch(want, got Fingerprint) error {
+	return &Error{
+		code: 1001,
+		message: fmt.Sprintf(
+			"schema fingerprint mismatch: expected %v, message type has %v",
+			want, got,
+		),
+	}
+}
+
+func errValueOutOfRange(tag uint16, typeName string, value int64) error {
+	return &Error{
+		code:    1002,
+		message: fmt.Sprintf("field @%d: value %d out of range for %s", tag, value, typeName),
+	}
+}
+
+func errValueSize(tag uint16, typeName string, size, expect int) error {
+	return &Error{
+		code: 1003,
+		message: fmt.Sprintf(
+			"field @%d: %s value has size %d, expected %d",
+			tag, typeName, size, expect,
+		),
+	}
+}
+
+func errArraySize(tag uint16, typeName string, size, valueSize int) error {
+	return &Error{
+		code: 1004,
+		message: fmt.Sprintf(
+			"field @%d: %s value has size %d, expected a multiple of %d",
+			tag, typeName, size, valueSize,
+		),
+	}
+}
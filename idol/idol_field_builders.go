@@ -19,6 +19,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+	"math"
 	"strings"
 )
 
@@ -199,6 +200,60 @@ func (b *Uint16FieldBuilder) Set(value uint16) {
 
 // }}}
 
+// Uint16ArrayFieldBuilder {{{
+
+type Uint16ArrayFieldBuilder struct {
+	values []uint16
+}
+
+func (b *Uint16ArrayFieldBuilder) IsPresent() bool {
+	return len(b.values) > 0
+}
+
+func (b *Uint16ArrayFieldBuilder) valueSize() uint32 {
+	return 2 * uint32(len(b.values))
+}
+
+func (b *Uint16ArrayFieldBuilder) DataSize() uint32 {
+	return (b.valueSize() + 0b111) & 0xFFFFFFF8
+}
+
+func (b *Uint16ArrayFieldBuilder) PutThunk(thunk []uint8) {
+	if b.IsPresent() {
+		binary.LittleEndian.PutUint16(thunk[2:4], 0xC000)
+		binary.LittleEndian.PutUint32(thunk[4:8], b.valueSize())
+	}
+}
+
+func (b *Uint16ArrayFieldBuilder) EncodeData(w io.Writer) error {
+	if !b.IsPresent() {
+		return nil
+	}
+	buf := make([]uint8, 0, b.DataSize())
+	for _, value := range b.values {
+		buf = binary.LittleEndian.AppendUint16(buf, value)
+	}
+	buf = buf[:cap(buf)]
+	_, err := w.Write(buf)
+	return err
+}
+
+func (b *Uint16ArrayFieldBuilder) Add(value uint16) {
+	b.values = append(b.values, value)
+}
+
+func (b *Uint16ArrayFieldBuilder) Set(values []uint16) {
+	b.values = append([]uint16{}, values...)
+}
+
+func (b *Uint16ArrayFieldBuilder) Extend(values Uint16Array) {
+	for _, value := range values.Iter() {
+		b.values = append(b.values, value)
+	}
+}
+
+// }}}
+
 // Uint32FieldBuilder {{{
 
 type Uint32FieldBuilder struct {
@@ -271,6 +326,189 @@ func (b *Uint64FieldBuilder) Set(value uint64) {
 
 // }}}
 
+// Uint64ArrayFieldBuilder {{{
+
+type Uint64ArrayFieldBuilder struct {
+	values []uint64
+}
+
+func (b *Uint64ArrayFieldBuilder) IsPresent() bool {
+	return len(b.values) > 0
+}
+
+func (b *Uint64ArrayFieldBuilder) valueSize() uint32 {
+	return 8 * uint32(len(b.values))
+}
+
+func (b *Uint64ArrayFieldBuilder) DataSize() uint32 {
+	return (b.valueSize() + 0b111) & 0xFFFFFFF8
+}
+
+func (b *Uint64ArrayFieldBuilder) PutThunk(thunk []uint8) {
+	if b.IsPresent() {
+		binary.LittleEndian.PutUint16(thunk[2:4], 0xC000)
+		binary.LittleEndian.PutUint32(thunk[4:8], b.valueSize())
+	}
+}
+
+func (b *Uint64ArrayFieldBuilder) EncodeData(w io.Writer) error {
+	if !b.IsPresent() {
+		return nil
+	}
+	buf := make([]uint8, 0, b.DataSize())
+	for _, value := range b.values {
+		buf = binary.LittleEndian.AppendUint64(buf, value)
+	}
+	buf = buf[:cap(buf)]
+	_, err := w.Write(buf)
+	return err
+}
+
+func (b *Uint64ArrayFieldBuilder) Add(value uint64) {
+	b.values = append(b.values, value)
+}
+
+func (b *Uint64ArrayFieldBuilder) Set(values []uint64) {
+	b.values = append([]uint64{}, values...)
+}
+
+func (b *Uint64ArrayFieldBuilder) Extend(values Uint64Array) {
+	for _, value := range values.Iter() {
+		b.values = append(b.values, value)
+	}
+}
+
+// }}}
+
+// Int8FieldBuilder {{{
+
+type Int8FieldBuilder struct {
+	value int8
+}
+
+func (b *Int8FieldBuilder) IsPresent() bool {
+	return b.value != 0
+}
+
+func (b *Int8FieldBuilder) PutThunk(thunk []uint8) {
+	if b.IsPresent() {
+		binary.LittleEndian.PutUint16(thunk[2:4], 0x8000)
+		binary.LittleEndian.PutUint32(thunk[4:8], uint32(int32(b.value)))
+	}
+}
+
+func (b *Int8FieldBuilder) Get() int8 {
+	return b.value
+}
+
+func (b *Int8FieldBuilder) Set(value int8) {
+	b.value = value
+}
+
+// }}}
+
+// Int16FieldBuilder {{{
+
+type Int16FieldBuilder struct {
+	value int16
+}
+
+func (b *Int16FieldBuilder) IsPresent() bool {
+	return b.value != 0
+}
+
+func (b *Int16FieldBuilder) PutThunk(thunk []uint8) {
+	if b.IsPresent() {
+		binary.LittleEndian.PutUint16(thunk[2:4], 0x8000)
+		binary.LittleEndian.PutUint32(thunk[4:8], uint32(int32(b.value)))
+	}
+}
+
+func (b *Int16FieldBuilder) Get() int16 {
+	return b.value
+}
+
+func (b *Int16FieldBuilder) Set(value int16) {
+	b.value = value
+}
+
+// }}}
+
+// Int32FieldBuilder {{{
+
+type Int32FieldBuilder struct {
+	value int32
+}
+
+func (b *Int32FieldBuilder) IsPresent() bool {
+	return b.value != 0
+}
+
+func (b *Int32FieldBuilder) PutThunk(thunk []uint8) {
+	if b.IsPresent() {
+		binary.LittleEndian.PutUint16(thunk[2:4], 0x8000)
+		binary.LittleEndian.PutUint32(thunk[4:8], uint32(b.value))
+	}
+}
+
+func (b *Int32FieldBuilder) Get() int32 {
+	return b.value
+}
+
+func (b *Int32FieldBuilder) Set(value int32) {
+	b.value = value
+}
+
+// }}}
+
+// Int64FieldBuilder {{{
+
+type Int64FieldBuilder struct {
+	Uint64FieldBuilder
+}
+
+func (b *Int64FieldBuilder) Get() int64 {
+	return int64(b.value)
+}
+
+func (b *Int64FieldBuilder) Set(value int64) {
+	b.value = uint64(value)
+}
+
+// }}}
+
+// Float32FieldBuilder {{{
+
+type Float32FieldBuilder struct {
+	Uint32FieldBuilder
+}
+
+func (b *Float32FieldBuilder) Get() float32 {
+	return math.Float32frombits(b.value)
+}
+
+func (b *Float32FieldBuilder) Set(value float32) {
+	b.value = math.Float32bits(value)
+}
+
+// }}}
+
+// Float64FieldBuilder {{{
+
+type Float64FieldBuilder struct {
+	Uint64FieldBuilder
+}
+
+func (b *Float64FieldBuilder) Get() float64 {
+	return math.Float64frombits(b.value)
+}
+
+func (b *Float64FieldBuilder) Set(value float64) {
+	b.value = math.Float64bits(value)
+}
+
+// }}}
+
 // TextFieldBuilder {{{
 
 type TextFieldBuilder struct {
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package idol
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// A Fingerprint identifies a schema or declaration by a hash of its canonical
+// form, which excludes doc comments, source information, and options that
+// don't affect the wire format. A declaration's fingerprint also covers the
+// types it references, including imported types if their schemas were
+// available when it was computed; otherwise imported types contribute only
+// their name, and peers with equal fingerprints may still disagree about them.
+type Fingerprint [32]byte
+
+func (f Fingerprint) String() string {
+	return hex.EncodeToString(f[:])
+}
+
+func (f Fingerprint) IsZero() bool {
+	return f == Fingerprint{}
+}
+
+// ParseFingerprint parses the hex-encoded form returned by String.
+func ParseFingerprint(s string) (Fingerprint, error) {
+	var f Fingerprint
+	if hex.DecodedLen(len(s)) != len(f) {
+		return f, fmt.Errorf("invalid fingerprint %q", s)
+	}
+	if _, err := hex.Decode(f[:], []byte(s)); err != nil {
+		return f, fmt.Errorf("invalid fingerprint %q", s)
+	}
+	return f, nil
+}
+
+// HasFingerprint is implemented by generated message types.
+type HasFingerprint interface {
+	Idol__Fingerprint() Fingerprint
+}
+
+// FingerprintOf returns the fingerprint of a generated message type, for use
+// as an identifier in RPC handshakes and similar version negotiation.
+func FingerprintOf[T AsMessageType[T]]() (Fingerprint, bool) {
+	var zero T
+	if f, ok := any(zero).(HasFingerprint); ok {
+		return f.Idol__Fingerprint(), true
+	}
+	return Fingerprint{}, false
+}
+
+func (ctx *DecodeCtx) checkFingerprint(message any) error {
+	if ctx == nil || ctx.Fingerprint.IsZero() {
+		return nil
+	}
+	var got Fingerprint
+	if f, ok := message.(HasFingerprint); ok {
+		got = f.Idol__Fingerprint()
+	}
+	if got != ctx.Fingerprint {
+		return errFingerprintMismatch(ctx.Fingerprint, got)
+	}
+	return nil
+}
@@ -82,15 +82,26 @@ type IsGeneratedMessageBuilder[T any] struct{}
 
 func (IsGeneratedMessageBuilder[T]) isMessageBuilder(T) {}
 
-type DecodeCtx struct{}
+type DecodeCtx struct {
+	// If set, decoding fails unless the fingerprint of the message type
+	// matches, such as when a peer announced the fingerprint of the schema
+	// it was built from.
+	Fingerprint Fingerprint
+}
 
 func Decode[T AsMessageType[T]](ctx *DecodeCtx, buf []uint8) error {
 	var zero T
+	if err := ctx.checkFingerprint(zero); err != nil {
+		return err
+	}
 	return zero.Idol__MessageType().Decode(ctx, buf)
 }
 
 func DecodeAs[T AsMessageType[T]](ctx *DecodeCtx, buf []uint8) (T, error) {
 	var zero T
+	if err := ctx.checkFingerprint(zero); err != nil {
+		return zero, err
+	}
 	return zero.Idol__MessageType().DecodeAs(ctx, buf)
 }
 
@@ -168,6 +179,10 @@ func (msg DecodedMessage) GetUint8Array(tag uint16) Uint8Array {
 	return Uint8Array{msg.GetIndirect(tag)}
 }
 
+func (msg DecodedMessage) GetUint16Array(tag uint16) Uint16Array {
+	return Uint16Array{msg.GetIndirect(tag)}
+}
+
 func (msg DecodedMessage) GetUint32(tag uint16) uint32 {
 	if !msg.Has(tag) {
 		return 0
@@ -183,6 +198,26 @@ func (msg DecodedMessage) GetUint64(tag uint16) uint64 {
 	return 0
 }
 
+func (msg DecodedMessage) GetInt32(tag uint16) int32 {
+	return int32(msg.GetUint32(tag))
+}
+
+func (msg DecodedMessage) GetInt64(tag uint16) int64 {
+	return int64(msg.GetUint64(tag))
+}
+
+func (msg DecodedMessage) GetFloat32(tag uint16) float32 {
+	return math.Float32frombits(msg.GetUint32(tag))
+}
+
+func (msg DecodedMessage) GetFloat64(tag uint16) float64 {
+	return math.Float64frombits(msg.GetUint64(tag))
+}
+
+func (msg DecodedMessage) GetUint64Array(tag uint16) Uint64Array {
+	return Uint64Array{msg.GetIndirect(tag)}
+}
+
 func (msg DecodedMessage) GetTextArray(tag uint16) TextArray {
 	return TextArray{msg.GetIndirect(tag)}
 }
@@ -323,6 +358,10 @@ func (d *MessageDecoder) Uint16(tag uint16) {
 	// TODO
 }
 
+func (d *MessageDecoder) Uint16Array(tag uint16) {
+	d.valueArray(tag, "u16[]", 2)
+}
+
 func (d *MessageDecoder) Uint32(tag uint16) {
 	if d.err != nil {
 		return
@@ -337,6 +376,66 @@ func (d *MessageDecoder) Uint64(tag uint16) {
 	// TODO
 }
 
+func (d *MessageDecoder) Uint64Array(tag uint16) {
+	d.valueArray(tag, "u64[]", 8)
+}
+
+// valueArray checks that an array field holds a whole number of values.
+func (d *MessageDecoder) valueArray(tag uint16, typeName string, valueSize int) {
+	if d.err != nil || !d.has(tag) {
+		return
+	}
+	if size := len(d.getIndirect(tag)); size%valueSize != 0 {
+		d.err = errArraySize(tag, typeName, size, valueSize)
+	}
+}
+
+func (d *MessageDecoder) getScalar(tag uint16) uint32 {
+	thunkOff := uint32(tag) * 8
+	return leUint32(d.buf[thunkOff+4 : thunkOff+8])
+}
+
+func (d *MessageDecoder) Int8(tag uint16) {
+	if d.err != nil || !d.has(tag) {
+		return
+	}
+	if value := int32(d.getScalar(tag)); value < math.MinInt8 || value > math.MaxInt8 {
+		d.err = errValueOutOfRange(tag, "i8", int64(value))
+	}
+}
+
+func (d *MessageDecoder) Int16(tag uint16) {
+	if d.err != nil || !d.has(tag) {
+		return
+	}
+	if value := int32(d.getScalar(tag)); value < math.MinInt16 || value > math.MaxInt16 {
+		d.err = errValueOutOfRange(tag, "i16", int64(value))
+	}
+}
+
+// Int32 and Float32 fields have no invalid values.
+
+func (d *MessageDecoder) Int32(tag uint16) {}
+
+func (d *MessageDecoder) Float32(tag uint16) {}
+
+func (d *MessageDecoder) Int64(tag uint16) {
+	d.value64(tag, "i64")
+}
+
+func (d *MessageDecoder) Float64(tag uint16) {
+	d.value64(tag, "f64")
+}
+
+func (d *MessageDecoder) value64(tag uint16, typeName string) {
+	if d.err != nil || !d.has(tag) {
+		return
+	}
+	if size := len(d.getIndirect(tag)); size != 8 {
+		d.err = errValueSize(tag, typeName, size, 8)
+	}
+}
+
 func (d *MessageDecoder) Text(tag uint16) {
 	if d.err != nil {
 		return
@@ -0,0 +1,453 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"go.idol-lang.org/idol/schema_idl"
+	"go.idol-lang.org/idol/syntax"
+)
+
+type Finding struct {
+	rule    string
+	message string
+	span    syntax.Span
+}
+
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.rule, f.message)
+}
+
+func (f *Finding) Rule() string {
+	return f.rule
+}
+
+func (f *Finding) Message() string {
+	return f.message
+}
+
+func (f *Finding) Span() syntax.Span {
+	return f.span
+}
+
+type Option interface {
+	apply(*Options)
+}
+
+type option func(*Options)
+
+func (f option) apply(opts *Options) { f(opts) }
+
+type Options struct {
+	disabledRules map[string]struct{}
+	maxStructSize uint32
+}
+
+const defaultMaxStructSize = 256
+
+// WithoutRule disables the named rule.
+func WithoutRule(name string) Option {
+	return option(func(opts *Options) {
+		opts.disabledRules[name] = struct{}{}
+	})
+}
+
+// WithMaxStructSize sets the size in bytes above which a struct is reported
+// by the STRUCT_TOO_LARGE rule.
+func WithMaxStructSize(size uint32) Option {
+	return option(func(opts *Options) {
+		opts.maxStructSize = size
+	})
+}
+
+type rule struct {
+	name  string
+	check func(*linter)
+}
+
+var rules = []*rule{
+	{"NAMING_CASE", checkNamingCase},
+	{"FIELD_TAG_ORDER", checkFieldTagOrder},
+	{"ENUM_ZERO_UNKNOWN", checkEnumZeroUnknown},
+	{"DOC_COMMENT_MISSING", checkDocComments},
+	{"UNUSED_DECL", checkUnusedDecls},
+	{"STRUCT_TOO_LARGE", checkStructSize},
+}
+
+// Rules returns the names of all lint rules.
+func Rules() []string {
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.name)
+	}
+	return names
+}
+
+// Lint checks a schema against the enabled rules. The parsed schema must be
+// the source from which schema was compiled.
+func Lint(
+	parsedSchema *syntax.Schema,
+	schema schema_idl.Schema,
+	opts ...Option,
+) []*Finding {
+	options := &Options{
+		disabledRules: make(map[string]struct{}),
+		maxStructSize: defaultMaxStructSize,
+	}
+	for _, opt := range opts {
+		opt.apply(options)
+	}
+
+	l := &linter{
+		opts:   options,
+		parsed: parsedSchema,
+		schema: schema,
+	}
+	for node := range parsedSchema.ChildNodes() {
+		if decl, ok := node.(declNode); ok {
+			l.decls = append(l.decls, decl)
+		}
+	}
+	for _, rule := range rules {
+		if _, disabled := options.disabledRules[rule.name]; disabled {
+			continue
+		}
+		l.rule = rule.name
+		rule.check(l)
+	}
+	slices.SortStableFunc(l.findings, func(a, b *Finding) int {
+		return cmp.Compare(a.span.Start(), b.span.Start())
+	})
+	return l.findings
+}
+
+type declNode interface {
+	syntax.Node
+	Name() *syntax.Ident
+	DocComments() []*syntax.Comment
+}
+
+type linter struct {
+	opts     *Options
+	parsed   *syntax.Schema
+	schema   schema_idl.Schema
+	decls    []declNode
+	rule     string
+	findings []*Finding
+}
+
+func (l *linter) report(node syntax.Node, format string, args ...any) {
+	l.findings = append(l.findings, &Finding{
+		rule:    l.rule,
+		message: fmt.Sprintf(format, args...),
+		span:    node.Span(),
+	})
+}
+
+func declKind(node syntax.Node) string {
+	switch node.(type) {
+	case *syntax.Const:
+		return "Constant"
+	case *syntax.Enum:
+		return "Enum"
+	case *syntax.Struct:
+		return "Struct"
+	case *syntax.Message:
+		return "Message"
+	case *syntax.Union:
+		return "Union"
+	case *syntax.Protocol:
+		return "Protocol"
+	}
+	panic("unreachable")
+}
+
+var (
+	upperCamelCase = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	lowerSnakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	upperSnakeCase = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+)
+
+func checkNamingCase(l *linter) {
+	checkName := func(kind string, name *syntax.Ident, re *regexp.Regexp, style string) {
+		if !re.MatchString(name.Get()) {
+			l.report(name, "%s name '%s' should be %s", kind, name.Get(), style)
+		}
+	}
+	checkFields := func(kind string, names []*syntax.Ident) {
+		for _, name := range names {
+			checkName(kind, name, lowerSnakeCase, "lower_snake_case")
+		}
+	}
+
+	for _, decl := range l.decls {
+		if _, ok := decl.(*syntax.Const); ok {
+			continue
+		}
+		checkName(declKind(decl), decl.Name(), upperCamelCase, "UpperCamelCase")
+
+		var fieldNames []*syntax.Ident
+		switch decl := decl.(type) {
+		case *syntax.Enum:
+			for _, item := range decl.Items() {
+				checkName("Enum item", item.Name(), upperSnakeCase, "UPPER_SNAKE_CASE")
+			}
+		case *syntax.Struct:
+			for _, field := range decl.Fields() {
+				fieldNames = append(fieldNames, field.Name())
+			}
+		case *syntax.Message:
+			for _, field := range decl.Fields() {
+				fieldNames = append(fieldNames, field.Name())
+			}
+		case *syntax.Union:
+			for _, field := range decl.Fields() {
+				fieldNames = append(fieldNames, field.Name())
+			}
+		}
+		checkFields("Field", fieldNames)
+	}
+}
+
+type taggedField interface {
+	Name() *syntax.Ident
+	Tag() *syntax.Tag
+}
+
+func checkFieldTagOrder(l *linter) {
+	// Reserved tags don't count as gaps.
+	checkTags := func(
+		kind, declName string,
+		fields []taggedField,
+		reserved []*syntax.Reserved,
+	) {
+		reservedTags := make(map[uint64]struct{})
+		for _, node := range reserved {
+			for _, tagNode := range node.Tags() {
+				if tag, ok := tagNode.Value().GetUint64(); ok {
+					reservedTags[tag] = struct{}{}
+				}
+			}
+		}
+		isGap := func(prevTag, tag uint64) bool {
+			for gapTag := prevTag + 1; gapTag < tag; gapTag++ {
+				if _, ok := reservedTags[gapTag]; !ok {
+					return true
+				}
+			}
+			return false
+		}
+
+		var prevTag uint64
+		for _, field := range fields {
+			tagNode := field.Tag()
+			tag, ok := tagNode.Value().GetUint64()
+			if !ok {
+				continue
+			}
+			if tag <= prevTag {
+				l.report(
+					tagNode,
+					"%s '%s' field '%s' tag @%d is not greater than the previous tag @%d",
+					kind, declName, field.Name().Get(), tag, prevTag,
+				)
+			} else if isGap(prevTag, tag) {
+				l.report(
+					tagNode,
+					"%s '%s' field '%s' tag @%d leaves a gap after @%d",
+					kind, declName, field.Name().Get(), tag, prevTag,
+				)
+			}
+			prevTag = max(prevTag, tag)
+		}
+	}
+
+	for _, decl := range l.decls {
+		var fields []taggedField
+		var reserved []*syntax.Reserved
+		switch decl := decl.(type) {
+		case *syntax.Message:
+			for _, field := range decl.Fields() {
+				fields = append(fields, field)
+			}
+			reserved = decl.Reserved()
+		case *syntax.Union:
+			for _, field := range decl.Fields() {
+				fields = append(fields, field)
+			}
+			reserved = decl.Reserved()
+		default:
+			continue
+		}
+		checkTags(declKind(decl), decl.Name().Get(), fields, reserved)
+	}
+}
+
+func checkEnumZeroUnknown(l *linter) {
+	enums := make(map[string]schema_idl.Enum)
+	for _, enum := range l.schema.Enums().Iter() {
+		enums[enum.Name()] = enum
+	}
+	for _, decl := range l.decls {
+		if _, ok := decl.(*syntax.Enum); !ok {
+			continue
+		}
+		enum, ok := enums[decl.Name().Get()]
+		if !ok {
+			continue
+		}
+		found := false
+		for _, item := range enum.Items().Iter() {
+			if item.Value() == 0 && item.Name() == "UNKNOWN" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			l.report(
+				decl.Name(),
+				"Enum '%s' has no item 'UNKNOWN' with value 0",
+				decl.Name().Get(),
+			)
+		}
+	}
+}
+
+// Top-level declarations must have doc comments, since every local
+// declaration can be imported by other schemas. Members such as fields and
+// enum items aren't checked.
+func checkDocComments(l *linter) {
+	for _, decl := range l.decls {
+		if len(decl.DocComments()) == 0 {
+			l.report(
+				decl.Name(),
+				"%s '%s' has no doc comment",
+				declKind(decl), decl.Name().Get(),
+			)
+		}
+	}
+}
+
+// Constants, enums, and structs are building blocks for other declarations,
+// so they're reported if no other local declaration refers to them.
+//
+// References may also appear in the options of a declaration, whose
+// decorators precede it as siblings in the schema.
+func checkUnusedDecls(l *linter) {
+	used := make(map[string]struct{})
+	for node := range l.parsed.ChildNodes() {
+		var declName string
+		if decl, ok := node.(declNode); ok {
+			declName = decl.Name().Get()
+		}
+		syntax.Walk(node, func(node syntax.Node) bool {
+			var name string
+			switch node := node.(type) {
+			case *syntax.TypeName:
+				if node.Scope() == nil {
+					name = node.Name().Get()
+				}
+			case *syntax.ValueName:
+				if scope := node.Scope(); scope != nil {
+					name = scope.Get()
+				} else {
+					name = node.Name().Get()
+				}
+			}
+			if name != "" && name != declName {
+				used[name] = struct{}{}
+			}
+			return true
+		})
+	}
+
+	for _, decl := range l.decls {
+		switch decl.(type) {
+		case *syntax.Const, *syntax.Enum, *syntax.Struct:
+		default:
+			continue
+		}
+		if _, ok := used[decl.Name().Get()]; !ok {
+			l.report(
+				decl.Name(),
+				"%s '%s' is not used by any other declaration",
+				declKind(decl), decl.Name().Get(),
+			)
+		}
+	}
+}
+
+func checkStructSize(l *linter) {
+	structs := make(map[string]schema_idl.Struct)
+	for _, struct_ := range l.schema.Structs().Iter() {
+		structs[struct_.Name()] = struct_
+	}
+	sizes := make(map[string]uint64)
+	var structSize func(schema_idl.Struct) uint64
+	structSize = func(struct_ schema_idl.Struct) uint64 {
+		if size, ok := sizes[struct_.Name()]; ok {
+			return size
+		}
+		var size uint64
+		for _, field := range struct_.Fields().Iter() {
+			var fieldSize uint64
+			switch field.Type() {
+			case schema_idl.Type_BOOL, schema_idl.Type_U8, schema_idl.Type_I8:
+				fieldSize = 1
+			case schema_idl.Type_U16, schema_idl.Type_I16:
+				fieldSize = 2
+			case schema_idl.Type_U32, schema_idl.Type_I32, schema_idl.Type_F32,
+				schema_idl.Type_HANDLE:
+				fieldSize = 4
+			case schema_idl.Type_U64, schema_idl.Type_I64, schema_idl.Type_F64:
+				fieldSize = 8
+			case schema_idl.Type_STRUCT:
+				// Imported structs aren't available, and don't count
+				// towards the size.
+				if fieldStruct, ok := structs[field.TypeName()]; ok {
+					fieldSize = structSize(fieldStruct)
+				}
+			}
+			if arrayLen := field.ArrayLen(); arrayLen > 0 {
+				fieldSize *= uint64(arrayLen)
+			}
+			size += fieldSize
+		}
+		sizes[struct_.Name()] = size
+		return size
+	}
+
+	for _, decl := range l.decls {
+		if _, ok := decl.(*syntax.Struct); !ok {
+			continue
+		}
+		struct_, ok := structs[decl.Name().Get()]
+		if !ok {
+			continue
+		}
+		if size := structSize(struct_); size > uint64(l.opts.maxStructSize) {
+			l.report(
+				decl.Name(),
+				"Struct '%s' is %d bytes, larger than the maximum of %d",
+				decl.Name().Get(), size, l.opts.maxStructSize,
+			)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2024 John Millikin <john@john-millikin.com>
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+// OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+//
+// SPDX-License-Identifier: 0BSD
+
+package lint_test
+
+import (
+	"testing"
+
+	"go.idol-lang.org/idol/compiler"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/lint"
+	"go.idol-lang.org/idol/syntax"
+)
+
+func lintRules(t *testing.T, src string, opts ...lint.Option) []string {
+	t.Helper()
+	parsed, err := syntax.Parse([]byte(src))
+	testutil.AssertNoError(t, err)
+	result := compiler.Compile(parsed)
+	for _, err := range result.Errors {
+		t.Fatal(err)
+	}
+	schema, err := result.Schema()
+	testutil.AssertNoError(t, err)
+	var rules []string
+	for _, finding := range lint.Lint(parsed, schema, opts...) {
+		rules = append(rules, finding.Rule())
+	}
+	return rules
+}
+
+func TestLint_Clean(t *testing.T) {
+	src := `namespace "test"
+
+## A color.
+enum Color : u8 {
+	UNKNOWN = 0
+	RED = 1
+}
+
+## A point.
+struct Point {
+	x : u32
+	y : u32
+}
+
+## A shape.
+message Shape {
+	reserved @2
+	color @1 : Color
+	points @3 : Point[]
+}
+`
+	testutil.ExpectSliceEq(t, nil, lintRules(t, src))
+}
+
+func TestLint_Rules(t *testing.T) {
+	src := `namespace "test"
+
+enum color : u8 {
+	Red = 1
+}
+
+struct Big {
+	data : u8[300]
+}
+
+## A shape.
+message Shape {
+	fieldOne @1 : color
+	b @3 : u32
+	c @2 : u32
+}
+`
+	testutil.ExpectSliceEq(t, []string{
+		"NAMING_CASE",
+		"ENUM_ZERO_UNKNOWN",
+		"DOC_COMMENT_MISSING",
+		"NAMING_CASE",
+		"DOC_COMMENT_MISSING",
+		"UNUSED_DECL",
+		"STRUCT_TOO_LARGE",
+		"NAMING_CASE",
+		"FIELD_TAG_ORDER",
+		"FIELD_TAG_ORDER",
+	}, lintRules(t, src))
+
+	testutil.ExpectSliceEq(t, []string{
+		"NAMING_CASE",
+		"ENUM_ZERO_UNKNOWN",
+		"NAMING_CASE",
+		"UNUSED_DECL",
+		"NAMING_CASE",
+		"FIELD_TAG_ORDER",
+		"FIELD_TAG_ORDER",
+	}, lintRules(t, src,
+		lint.WithoutRule("DOC_COMMENT_MISSING"),
+		lint.WithMaxStructSize(512),
+	))
+}
+
+func TestLint_DocComments(t *testing.T) {
+	src := `namespace "test"
+
+## A color.
+enum Color : u8 {
+	UNKNOWN = 0
+	RED = 1
+}
+
+## A shape.
+message Shape {
+	color @1 : Color
+}
+
+message Undocumented {
+	color @1 : Color
+}
+`
+	testutil.ExpectSliceEq(t, []string{
+		"DOC_COMMENT_MISSING",
+	}, lintRules(t, src))
+}
+
+func TestLint_UsedInOptions(t *testing.T) {
+	src := `namespace "test"
+
+## A default.
+const DEFAULT_SIZE : u32 = 10
+
+## An option value.
+const OPTION_VALUE : u32 = 20
+
+## A shape.
+@{custom_option = OPTION_VALUE}
+message Shape {
+	@{default = DEFAULT_SIZE}
+	size @1 : u32
+}
+`
+	testutil.ExpectSliceEq(t, nil, lintRules(t, src))
+}
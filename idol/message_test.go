@@ -14,3 +14,77 @@
 // SPDX-License-Identifier: 0BSD
 
 package idol_test
+
+import (
+	"testing"
+
+	"go.idol-lang.org/idol"
+	"go.idol-lang.org/idol/internal/testutil"
+	"go.idol-lang.org/idol/schema_idl"
+)
+
+func TestMessage_Uint16Array(t *testing.T) {
+	t.Parallel()
+
+	values := []uint16{2, 3, 0xFFFF}
+	b := &schema_idl.Message__Builder{}
+	b.Name.Set("M")
+	b.ReservedTags.Set(values)
+	buf, err := idol.Encode(&idol.EncodeCtx{}, b)
+	testutil.AssertNoError(t, err)
+
+	msg, err := idol.DecodeAs[schema_idl.Message](nil, buf)
+	testutil.AssertNoError(t, err)
+	testutil.ExpectSliceEq(t, values, msg.ReservedTags().Collect())
+}
+
+func TestMessage_Uint64Array(t *testing.T) {
+	t.Parallel()
+
+	values := []uint64{1, 0xFFFFFFFF, 0xFFFFFFFFFFFFFFFF}
+	b := &schema_idl.Enum__Builder{}
+	b.Name.Set("E")
+	b.ReservedValues.Set(values)
+	buf, err := idol.Encode(&idol.EncodeCtx{}, b)
+	testutil.AssertNoError(t, err)
+
+	enum, err := idol.DecodeAs[schema_idl.Enum](nil, buf)
+	testutil.AssertNoError(t, err)
+	testutil.ExpectSliceEq(t, values, enum.ReservedValues().Collect())
+}
+
+// arrayMessage returns an encoded message with an array of the given size in
+// field @1.
+func arrayMessage(size uint32) []uint8 {
+	padded := (size + 0b111) &^ 0b111
+	buf := []uint8{
+		uint8(16 + padded), 0, 0, 0, 0, 0, 1, 0,
+		0, 0, 0x00, 0xC0, uint8(size), 0, 0, 0,
+	}
+	return append(buf, make([]uint8, padded)...)
+}
+
+func TestMessageDecoder_ArraySize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		decode func(d *idol.MessageDecoder, tag uint16)
+		size   uint32
+		err    string
+	}{
+		{(*idol.MessageDecoder).Uint16Array, 4, ""},
+		{(*idol.MessageDecoder).Uint16Array, 3, "IDOL1004: field @1: u16[] value has size 3, expected a multiple of 2"},
+		{(*idol.MessageDecoder).Uint64Array, 16, ""},
+		{(*idol.MessageDecoder).Uint64Array, 12, "IDOL1004: field @1: u64[] value has size 12, expected a multiple of 8"},
+	}
+	for _, test := range tests {
+		d := idol.NewMessageDecoder(nil, arrayMessage(test.size))
+		test.decode(d, 1)
+		err := d.Finish()
+		if test.err == "" {
+			testutil.AssertNoError(t, err)
+		} else if err == nil || err.Error() != test.err {
+			t.Errorf("size %d: expected error %q, got %v", test.size, test.err, err)
+		}
+	}
+}
@@ -146,6 +146,10 @@ func (Schema) Idol__MessageType() idol.MessageType[Schema] {
 	return _Schema__MessageType{}
 }
 
+func (Schema) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x4D, 0x3F, 0x53, 0xC6, 0x30, 0x8A, 0xF1, 0xAC, 0xCC, 0xDA, 0xE1, 0x31, 0xF7, 0xE0, 0x5D, 0xCD, 0x7B, 0x9B, 0x21, 0x70, 0x44, 0x6E, 0x8F, 0xB6, 0x1E, 0x03, 0x7F, 0xAF, 0x25, 0xEE, 0x15, 0xD1}
+}
+
 func (m _Schema__Message) Self() Schema { return m.self }
 
 func (m _Schema__Message) Type() idol.MessageType[Schema] {
@@ -512,6 +516,10 @@ func (Import) Idol__MessageType() idol.MessageType[Import] {
 	return _Import__MessageType{}
 }
 
+func (Import) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x67, 0x47, 0x00, 0x41, 0x60, 0xB9, 0x0F, 0x4C, 0xF8, 0x8A, 0x06, 0x57, 0x30, 0x25, 0x95, 0x8B, 0x49, 0xA3, 0x70, 0xD9, 0x4D, 0x69, 0xE1, 0xF7, 0x27, 0xA7, 0xD2, 0x9D, 0x49, 0x85, 0x03, 0x63}
+}
+
 func (m _Import__Message) Self() Import { return m.self }
 
 func (m _Import__Message) Type() idol.MessageType[Import] {
@@ -660,6 +668,10 @@ func (Export) Idol__MessageType() idol.MessageType[Export] {
 	return _Export__MessageType{}
 }
 
+func (Export) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x67, 0x74, 0x57, 0x2E, 0xB2, 0x58, 0xAB, 0x16, 0xED, 0x64, 0x96, 0x59, 0x7D, 0x35, 0xCE, 0x0B, 0xF2, 0xCC, 0x21, 0x8A, 0x20, 0x2D, 0xAA, 0x58, 0xA0, 0x8E, 0x2B, 0x9B, 0xDB, 0xD1, 0x3B, 0x6E}
+}
+
 func (m _Export__Message) Self() Export { return m.self }
 
 func (m _Export__Message) Type() idol.MessageType[Export] {
@@ -824,6 +836,10 @@ func (Const) Idol__MessageType() idol.MessageType[Const] {
 	return _Const__MessageType{}
 }
 
+func (Const) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xD0, 0xB0, 0xE6, 0xB3, 0xD8, 0x15, 0x2A, 0x69, 0x30, 0x59, 0xDA, 0x03, 0x11, 0xAF, 0xB8, 0x09, 0xFA, 0xEE, 0x13, 0x98, 0xB3, 0x66, 0xD5, 0x4B, 0xAB, 0x8A, 0x9C, 0xF0, 0x07, 0x12, 0x83, 0x90}
+}
+
 func (m _Const__Message) Self() Const { return m.self }
 
 func (m _Const__Message) Type() idol.MessageType[Const] {
@@ -845,6 +861,10 @@ func (m _Const__Message) Clone() idol.MessageBuilder[Const] {
 	if m.self.msg.Has(5) {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(7) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
 	return b.Idol__MessageBuilder()
 }
 
@@ -864,6 +884,10 @@ func (f _Const__MessageFields) Name(tag uint16) string {
 		return "value"
 	case 5:
 		return "options"
+	case 6:
+		return "doc"
+	case 7:
+		return "source_span"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -890,6 +914,12 @@ func (f _Const__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(5) && !yield(5, f.self.Options()) {
 			return
 		}
+		if f.Has(6) && !yield(6, f.self.Doc()) {
+			return
+		}
+		if f.Has(7) && !yield(7, f.self.SourceSpan()) {
+			return
+		}
 	}
 }
 
@@ -900,6 +930,8 @@ func (_Const__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d.Text(3)
 	d.Uint8Array(4)
 	d.Message(5, (ConstOptions{}).Idol__MessageType().Decode)
+	d.Text(6)
+	d.Message(7, (SourceSpan{}).Idol__MessageType().Decode)
 	return d.Finish()
 }
 
@@ -926,12 +958,23 @@ func (m Const) Options() ConstOptions {
 	return ConstOptions{}
 }
 
+func (m Const) Doc() idol.Text { return m.msg.GetText(6) }
+
+func (m Const) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(7); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
 type Const__Builder struct {
-	Name     idol.TextFieldBuilder
-	Type     idol.EnumFieldBuilder[Type]
-	TypeName idol.TextFieldBuilder
-	Value    idol.Uint8ArrayFieldBuilder
-	Options  idol.MessageFieldBuilder[ConstOptions]
+	Name       idol.TextFieldBuilder
+	Type       idol.EnumFieldBuilder[Type]
+	TypeName   idol.TextFieldBuilder
+	Value      idol.Uint8ArrayFieldBuilder
+	Options    idol.MessageFieldBuilder[ConstOptions]
+	Doc        idol.TextFieldBuilder
+	SourceSpan idol.MessageFieldBuilder[SourceSpan]
 }
 
 type _Const__Builder struct {
@@ -967,6 +1010,12 @@ func (b _Const__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(5, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(6, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(7, b.self.SourceSpan.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -975,10 +1024,16 @@ func (b _Const__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if size == 0 {
 		return nil
 	}
-	var ht [48]uint8
+	var ht [64]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 7:
+		b.self.SourceSpan.PutThunk(ht[56:64])
+		fallthrough
+	case 6:
+		b.self.Doc.PutThunk(ht[48:56])
+		fallthrough
 	case 5:
 		b.self.Options.PutThunk(ht[40:48])
 		fallthrough
@@ -1011,6 +1066,12 @@ func (b _Const__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1033,6 +1094,10 @@ func (Enum) Idol__MessageType() idol.MessageType[Enum] {
 	return _Enum__MessageType{}
 }
 
+func (Enum) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xDE, 0x3E, 0x32, 0xF1, 0x7A, 0xB8, 0x03, 0xCB, 0xEE, 0xDC, 0xDB, 0xA8, 0x9A, 0x5E, 0x99, 0x52, 0xD8, 0xC5, 0x2A, 0x4F, 0x9E, 0x24, 0xEB, 0xF8, 0xA0, 0x3E, 0x84, 0x3A, 0x8C, 0x9A, 0xCB, 0x9B}
+}
+
 func (m _Enum__Message) Self() Enum { return m.self }
 
 func (m _Enum__Message) Type() idol.MessageType[Enum] {
@@ -1053,6 +1118,12 @@ func (m _Enum__Message) Clone() idol.MessageBuilder[Enum] {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
 	b.Items.Extend(m.self.Items())
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(6) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
+	b.ReservedValues.Extend(m.self.ReservedValues())
+	b.ReservedNames.Extend(m.self.ReservedNames())
 	return b.Idol__MessageBuilder()
 }
 
@@ -1070,6 +1141,14 @@ func (f _Enum__MessageFields) Name(tag uint16) string {
 		return "items"
 	case 4:
 		return "options"
+	case 5:
+		return "doc"
+	case 6:
+		return "source_span"
+	case 7:
+		return "reserved_values"
+	case 8:
+		return "reserved_names"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -1093,6 +1172,18 @@ func (f _Enum__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(3) && !yield(3, f.self.Items()) {
 			return
 		}
+		if f.Has(5) && !yield(5, f.self.Doc()) {
+			return
+		}
+		if f.Has(6) && !yield(6, f.self.SourceSpan()) {
+			return
+		}
+		if f.Has(7) && !yield(7, f.self.ReservedValues()) {
+			return
+		}
+		if f.Has(8) && !yield(8, f.self.ReservedNames()) {
+			return
+		}
 	}
 }
 
@@ -1102,6 +1193,10 @@ func (_Enum__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d.Uint8(2)
 	d.MessageArray(3, (EnumItem{}).Idol__MessageType().Decode)
 	d.Message(4, (EnumOptions{}).Idol__MessageType().Decode)
+	d.Text(5)
+	d.Message(6, (SourceSpan{}).Idol__MessageType().Decode)
+	d.Uint64Array(7)
+	d.TextArray(8)
 	return d.Finish()
 }
 
@@ -1131,11 +1226,28 @@ func (m Enum) Items() idol.MessageArray[EnumItem] {
 	return idol.MessageArray[EnumItem]{}
 }
 
+func (m Enum) Doc() idol.Text { return m.msg.GetText(5) }
+
+func (m Enum) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(6); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
+func (m Enum) ReservedValues() idol.Uint64Array { return m.msg.GetUint64Array(7) }
+
+func (m Enum) ReservedNames() idol.TextArray { return m.msg.GetTextArray(8) }
+
 type Enum__Builder struct {
-	Name    idol.TextFieldBuilder
-	Type    idol.EnumFieldBuilder[Type]
-	Options idol.MessageFieldBuilder[EnumOptions]
-	Items   idol.MessageArrayFieldBuilder[EnumItem]
+	Name           idol.TextFieldBuilder
+	Type           idol.EnumFieldBuilder[Type]
+	Options        idol.MessageFieldBuilder[EnumOptions]
+	Items          idol.MessageArrayFieldBuilder[EnumItem]
+	Doc            idol.TextFieldBuilder
+	SourceSpan     idol.MessageFieldBuilder[SourceSpan]
+	ReservedValues idol.Uint64ArrayFieldBuilder
+	ReservedNames  idol.TextArrayFieldBuilder
 }
 
 type _Enum__Builder struct {
@@ -1168,6 +1280,18 @@ func (b _Enum__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(4, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(5, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(6, b.self.SourceSpan.DataSize())
+	}
+	if b.self.ReservedValues.IsPresent() {
+		m.Indirect(7, b.self.ReservedValues.DataSize())
+	}
+	if b.self.ReservedNames.IsPresent() {
+		m.Indirect(8, b.self.ReservedNames.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -1176,10 +1300,22 @@ func (b _Enum__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if size == 0 {
 		return nil
 	}
-	var ht [40]uint8
+	var ht [72]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 8:
+		b.self.ReservedNames.PutThunk(ht[64:72])
+		fallthrough
+	case 7:
+		b.self.ReservedValues.PutThunk(ht[56:64])
+		fallthrough
+	case 6:
+		b.self.SourceSpan.PutThunk(ht[48:56])
+		fallthrough
+	case 5:
+		b.self.Doc.PutThunk(ht[40:48])
+		fallthrough
 	case 4:
 		b.self.Options.PutThunk(ht[32:40])
 		fallthrough
@@ -1206,6 +1342,18 @@ func (b _Enum__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
+	if err := b.self.ReservedValues.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.ReservedNames.EncodeData(w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1228,6 +1376,10 @@ func (EnumItem) Idol__MessageType() idol.MessageType[EnumItem] {
 	return _EnumItem__MessageType{}
 }
 
+func (EnumItem) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x03, 0x71, 0x68, 0x06, 0x0D, 0x3A, 0x82, 0xB8, 0xEB, 0x84, 0x7B, 0x0F, 0x9B, 0x6C, 0xCF, 0x32, 0xCB, 0xD3, 0xC0, 0x0F, 0x04, 0x09, 0xFE, 0xCC, 0x86, 0x61, 0x92, 0xEB, 0xC3, 0xE6, 0xEE, 0x24}
+}
+
 func (m _EnumItem__Message) Self() EnumItem { return m.self }
 
 func (m _EnumItem__Message) Type() idol.MessageType[EnumItem] {
@@ -1248,6 +1400,10 @@ func (m _EnumItem__Message) Clone() idol.MessageBuilder[EnumItem] {
 	if m.self.msg.Has(4) {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(6) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
 	return b.Idol__MessageBuilder()
 }
 
@@ -1265,6 +1421,10 @@ func (f _EnumItem__MessageFields) Name(tag uint16) string {
 		return "is_alias"
 	case 4:
 		return "options"
+	case 5:
+		return "doc"
+	case 6:
+		return "source_span"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -1288,6 +1448,12 @@ func (f _EnumItem__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(4) && !yield(4, f.self.Options()) {
 			return
 		}
+		if f.Has(5) && !yield(5, f.self.Doc()) {
+			return
+		}
+		if f.Has(6) && !yield(6, f.self.SourceSpan()) {
+			return
+		}
 	}
 }
 
@@ -1297,6 +1463,8 @@ func (_EnumItem__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d.Uint64(2)
 	d.Bool(3)
 	d.Message(4, (EnumItemOptions{}).Idol__MessageType().Decode)
+	d.Text(5)
+	d.Message(6, (SourceSpan{}).Idol__MessageType().Decode)
 	return d.Finish()
 }
 
@@ -1321,11 +1489,22 @@ func (m EnumItem) Options() EnumItemOptions {
 	return EnumItemOptions{}
 }
 
+func (m EnumItem) Doc() idol.Text { return m.msg.GetText(5) }
+
+func (m EnumItem) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(6); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
 type EnumItem__Builder struct {
-	Name    idol.TextFieldBuilder
-	Value   idol.Uint64FieldBuilder
-	IsAlias idol.BoolFieldBuilder
-	Options idol.MessageFieldBuilder[EnumItemOptions]
+	Name       idol.TextFieldBuilder
+	Value      idol.Uint64FieldBuilder
+	IsAlias    idol.BoolFieldBuilder
+	Options    idol.MessageFieldBuilder[EnumItemOptions]
+	Doc        idol.TextFieldBuilder
+	SourceSpan idol.MessageFieldBuilder[SourceSpan]
 }
 
 type _EnumItem__Builder struct {
@@ -1358,6 +1537,12 @@ func (b _EnumItem__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(4, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(5, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(6, b.self.SourceSpan.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -1366,10 +1551,16 @@ func (b _EnumItem__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if size == 0 {
 		return nil
 	}
-	var ht [40]uint8
+	var ht [56]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 6:
+		b.self.SourceSpan.PutThunk(ht[48:56])
+		fallthrough
+	case 5:
+		b.self.Doc.PutThunk(ht[40:48])
+		fallthrough
 	case 4:
 		b.self.Options.PutThunk(ht[32:40])
 		fallthrough
@@ -1396,6 +1587,12 @@ func (b _EnumItem__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1418,6 +1615,10 @@ func (Struct) Idol__MessageType() idol.MessageType[Struct] {
 	return _Struct__MessageType{}
 }
 
+func (Struct) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xDA, 0x47, 0xF5, 0x87, 0xB0, 0xE3, 0xD8, 0x6D, 0xFA, 0x1E, 0x18, 0x26, 0xB5, 0xAD, 0x4E, 0x01, 0xFA, 0x4E, 0x04, 0x01, 0x88, 0x22, 0xB9, 0x51, 0x89, 0x82, 0xCC, 0x53, 0xE0, 0xEA, 0x72, 0xF6}
+}
+
 func (m _Struct__Message) Self() Struct { return m.self }
 
 func (m _Struct__Message) Type() idol.MessageType[Struct] {
@@ -1437,6 +1638,10 @@ func (m _Struct__Message) Clone() idol.MessageBuilder[Struct] {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
 	b.Fields.Extend(m.self.Fields())
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(5) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
 	return b.Idol__MessageBuilder()
 }
 
@@ -1452,6 +1657,10 @@ func (f _Struct__MessageFields) Name(tag uint16) string {
 		return "fields"
 	case 3:
 		return "options"
+	case 4:
+		return "doc"
+	case 5:
+		return "source_span"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -1472,6 +1681,12 @@ func (f _Struct__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(2) && !yield(2, f.self.Fields()) {
 			return
 		}
+		if f.Has(4) && !yield(4, f.self.Doc()) {
+			return
+		}
+		if f.Has(5) && !yield(5, f.self.SourceSpan()) {
+			return
+		}
 	}
 }
 
@@ -1480,6 +1695,8 @@ func (_Struct__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d.Text(1)
 	d.MessageArray(2, (StructField{}).Idol__MessageType().Decode)
 	d.Message(3, (StructOptions{}).Idol__MessageType().Decode)
+	d.Text(4)
+	d.Message(5, (SourceSpan{}).Idol__MessageType().Decode)
 	return d.Finish()
 }
 
@@ -1507,10 +1724,21 @@ func (m Struct) Fields() idol.MessageArray[StructField] {
 	return idol.MessageArray[StructField]{}
 }
 
+func (m Struct) Doc() idol.Text { return m.msg.GetText(4) }
+
+func (m Struct) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(5); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
 type Struct__Builder struct {
-	Name    idol.TextFieldBuilder
-	Options idol.MessageFieldBuilder[StructOptions]
-	Fields  idol.MessageArrayFieldBuilder[StructField]
+	Name       idol.TextFieldBuilder
+	Options    idol.MessageFieldBuilder[StructOptions]
+	Fields     idol.MessageArrayFieldBuilder[StructField]
+	Doc        idol.TextFieldBuilder
+	SourceSpan idol.MessageFieldBuilder[SourceSpan]
 }
 
 type _Struct__Builder struct {
@@ -1540,6 +1768,12 @@ func (b _Struct__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(3, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(4, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(5, b.self.SourceSpan.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -1548,10 +1782,16 @@ func (b _Struct__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if size == 0 {
 		return nil
 	}
-	var ht [32]uint8
+	var ht [48]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 5:
+		b.self.SourceSpan.PutThunk(ht[40:48])
+		fallthrough
+	case 4:
+		b.self.Doc.PutThunk(ht[32:40])
+		fallthrough
 	case 3:
 		b.self.Options.PutThunk(ht[24:32])
 		fallthrough
@@ -1575,6 +1815,12 @@ func (b _Struct__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1597,6 +1843,10 @@ func (StructField) Idol__MessageType() idol.MessageType[StructField] {
 	return _StructField__MessageType{}
 }
 
+func (StructField) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xDC, 0x6C, 0xE5, 0x37, 0x0A, 0xD1, 0xC2, 0xF8, 0x57, 0x80, 0x35, 0x7C, 0xDF, 0x2E, 0xD0, 0x1D, 0x1E, 0x2F, 0x7A, 0x3A, 0xA6, 0x10, 0xA9, 0x6A, 0x01, 0x22, 0xA2, 0x80, 0x86, 0x22, 0xF1, 0xD6}
+}
+
 func (m _StructField__Message) Self() StructField { return m.self }
 
 func (m _StructField__Message) Type() idol.MessageType[StructField] {
@@ -1618,6 +1868,10 @@ func (m _StructField__Message) Clone() idol.MessageBuilder[StructField] {
 	if m.self.msg.Has(5) {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(7) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
 	return b.Idol__MessageBuilder()
 }
 
@@ -1637,6 +1891,10 @@ func (f _StructField__MessageFields) Name(tag uint16) string {
 		return "array_len"
 	case 5:
 		return "options"
+	case 6:
+		return "doc"
+	case 7:
+		return "source_span"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -1663,6 +1921,12 @@ func (f _StructField__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(5) && !yield(5, f.self.Options()) {
 			return
 		}
+		if f.Has(6) && !yield(6, f.self.Doc()) {
+			return
+		}
+		if f.Has(7) && !yield(7, f.self.SourceSpan()) {
+			return
+		}
 	}
 }
 
@@ -1673,6 +1937,8 @@ func (_StructField__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error
 	d.Text(3)
 	d.Uint32(4)
 	d.Message(5, (StructFieldOptions{}).Idol__MessageType().Decode)
+	d.Text(6)
+	d.Message(7, (SourceSpan{}).Idol__MessageType().Decode)
 	return d.Finish()
 }
 
@@ -1699,12 +1965,23 @@ func (m StructField) Options() StructFieldOptions {
 	return StructFieldOptions{}
 }
 
+func (m StructField) Doc() idol.Text { return m.msg.GetText(6) }
+
+func (m StructField) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(7); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
 type StructField__Builder struct {
-	Name     idol.TextFieldBuilder
-	Type     idol.EnumFieldBuilder[Type]
-	TypeName idol.TextFieldBuilder
-	ArrayLen idol.Uint32FieldBuilder
-	Options  idol.MessageFieldBuilder[StructFieldOptions]
+	Name       idol.TextFieldBuilder
+	Type       idol.EnumFieldBuilder[Type]
+	TypeName   idol.TextFieldBuilder
+	ArrayLen   idol.Uint32FieldBuilder
+	Options    idol.MessageFieldBuilder[StructFieldOptions]
+	Doc        idol.TextFieldBuilder
+	SourceSpan idol.MessageFieldBuilder[SourceSpan]
 }
 
 type _StructField__Builder struct {
@@ -1740,6 +2017,12 @@ func (b _StructField__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(5, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(6, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(7, b.self.SourceSpan.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -1748,10 +2031,16 @@ func (b _StructField__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error
 	if size == 0 {
 		return nil
 	}
-	var ht [48]uint8
+	var ht [64]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 7:
+		b.self.SourceSpan.PutThunk(ht[56:64])
+		fallthrough
+	case 6:
+		b.self.Doc.PutThunk(ht[48:56])
+		fallthrough
 	case 5:
 		b.self.Options.PutThunk(ht[40:48])
 		fallthrough
@@ -1781,6 +2070,12 @@ func (b _StructField__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1803,6 +2098,10 @@ func (Message) Idol__MessageType() idol.MessageType[Message] {
 	return _Message__MessageType{}
 }
 
+func (Message) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x60, 0xC3, 0x5E, 0x8E, 0xA3, 0xCB, 0xDD, 0x2E, 0xA4, 0xC0, 0xEA, 0x97, 0x4F, 0x2A, 0x2B, 0xCA, 0x66, 0x4D, 0x56, 0xB9, 0xE8, 0x23, 0x1A, 0x4F, 0xDC, 0x9B, 0xF2, 0x4A, 0xF9, 0x46, 0xF4, 0x7D}
+}
+
 func (m _Message__Message) Self() Message { return m.self }
 
 func (m _Message__Message) Type() idol.MessageType[Message] {
@@ -1822,6 +2121,12 @@ func (m _Message__Message) Clone() idol.MessageBuilder[Message] {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
 	b.Fields.Extend(m.self.Fields())
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(5) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
+	b.ReservedTags.Extend(m.self.ReservedTags())
+	b.ReservedNames.Extend(m.self.ReservedNames())
 	return b.Idol__MessageBuilder()
 }
 
@@ -1837,6 +2142,14 @@ func (f _Message__MessageFields) Name(tag uint16) string {
 		return "fields"
 	case 3:
 		return "options"
+	case 4:
+		return "doc"
+	case 5:
+		return "source_span"
+	case 6:
+		return "reserved_tags"
+	case 7:
+		return "reserved_names"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -1857,6 +2170,18 @@ func (f _Message__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(2) && !yield(2, f.self.Fields()) {
 			return
 		}
+		if f.Has(4) && !yield(4, f.self.Doc()) {
+			return
+		}
+		if f.Has(5) && !yield(5, f.self.SourceSpan()) {
+			return
+		}
+		if f.Has(6) && !yield(6, f.self.ReservedTags()) {
+			return
+		}
+		if f.Has(7) && !yield(7, f.self.ReservedNames()) {
+			return
+		}
 	}
 }
 
@@ -1865,6 +2190,10 @@ func (_Message__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d.Text(1)
 	d.MessageArray(2, (MessageField{}).Idol__MessageType().Decode)
 	d.Message(3, (MessageOptions{}).Idol__MessageType().Decode)
+	d.Text(4)
+	d.Message(5, (SourceSpan{}).Idol__MessageType().Decode)
+	d.Uint16Array(6)
+	d.TextArray(7)
 	return d.Finish()
 }
 
@@ -1892,10 +2221,27 @@ func (m Message) Fields() idol.MessageArray[MessageField] {
 	return idol.MessageArray[MessageField]{}
 }
 
+func (m Message) Doc() idol.Text { return m.msg.GetText(4) }
+
+func (m Message) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(5); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
+func (m Message) ReservedTags() idol.Uint16Array { return m.msg.GetUint16Array(6) }
+
+func (m Message) ReservedNames() idol.TextArray { return m.msg.GetTextArray(7) }
+
 type Message__Builder struct {
-	Name    idol.TextFieldBuilder
-	Options idol.MessageFieldBuilder[MessageOptions]
-	Fields  idol.MessageArrayFieldBuilder[MessageField]
+	Name          idol.TextFieldBuilder
+	Options       idol.MessageFieldBuilder[MessageOptions]
+	Fields        idol.MessageArrayFieldBuilder[MessageField]
+	Doc           idol.TextFieldBuilder
+	SourceSpan    idol.MessageFieldBuilder[SourceSpan]
+	ReservedTags  idol.Uint16ArrayFieldBuilder
+	ReservedNames idol.TextArrayFieldBuilder
 }
 
 type _Message__Builder struct {
@@ -1925,6 +2271,18 @@ func (b _Message__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(3, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(4, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(5, b.self.SourceSpan.DataSize())
+	}
+	if b.self.ReservedTags.IsPresent() {
+		m.Indirect(6, b.self.ReservedTags.DataSize())
+	}
+	if b.self.ReservedNames.IsPresent() {
+		m.Indirect(7, b.self.ReservedNames.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -1933,10 +2291,22 @@ func (b _Message__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if size == 0 {
 		return nil
 	}
-	var ht [32]uint8
+	var ht [64]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 7:
+		b.self.ReservedNames.PutThunk(ht[56:64])
+		fallthrough
+	case 6:
+		b.self.ReservedTags.PutThunk(ht[48:56])
+		fallthrough
+	case 5:
+		b.self.SourceSpan.PutThunk(ht[40:48])
+		fallthrough
+	case 4:
+		b.self.Doc.PutThunk(ht[32:40])
+		fallthrough
 	case 3:
 		b.self.Options.PutThunk(ht[24:32])
 		fallthrough
@@ -1960,6 +2330,18 @@ func (b _Message__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
+	if err := b.self.ReservedTags.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.ReservedNames.EncodeData(w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1982,6 +2364,10 @@ func (MessageField) Idol__MessageType() idol.MessageType[MessageField] {
 	return _MessageField__MessageType{}
 }
 
+func (MessageField) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x31, 0xFC, 0xA0, 0xB1, 0xFA, 0x15, 0x5D, 0xF6, 0x1F, 0x32, 0xFF, 0xD2, 0xB4, 0x3C, 0xE4, 0xE5, 0x51, 0x52, 0x8F, 0x94, 0xD3, 0x69, 0x1D, 0x69, 0x5C, 0xB4, 0x26, 0x0F, 0x6B, 0x70, 0x44, 0x17}
+}
+
 func (m _MessageField__Message) Self() MessageField { return m.self }
 
 func (m _MessageField__Message) Type() idol.MessageType[MessageField] {
@@ -2004,6 +2390,10 @@ func (m _MessageField__Message) Clone() idol.MessageBuilder[MessageField] {
 	if m.self.msg.Has(6) {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(8) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
 	return b.Idol__MessageBuilder()
 }
 
@@ -2025,6 +2415,10 @@ func (f _MessageField__MessageFields) Name(tag uint16) string {
 		return "array_len"
 	case 6:
 		return "options"
+	case 7:
+		return "doc"
+	case 8:
+		return "source_span"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -2054,6 +2448,12 @@ func (f _MessageField__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(6) && !yield(6, f.self.Options()) {
 			return
 		}
+		if f.Has(7) && !yield(7, f.self.Doc()) {
+			return
+		}
+		if f.Has(8) && !yield(8, f.self.SourceSpan()) {
+			return
+		}
 	}
 }
 
@@ -2065,6 +2465,8 @@ func (_MessageField__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error
 	d.Text(4)
 	d.Uint32(5)
 	d.Message(6, (MessageFieldOptions{}).Idol__MessageType().Decode)
+	d.Text(7)
+	d.Message(8, (SourceSpan{}).Idol__MessageType().Decode)
 	return d.Finish()
 }
 
@@ -2093,13 +2495,24 @@ func (m MessageField) Options() MessageFieldOptions {
 	return MessageFieldOptions{}
 }
 
+func (m MessageField) Doc() idol.Text { return m.msg.GetText(7) }
+
+func (m MessageField) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(8); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
 type MessageField__Builder struct {
-	Name     idol.TextFieldBuilder
-	Tag      idol.Uint16FieldBuilder
-	Type     idol.EnumFieldBuilder[Type]
-	TypeName idol.TextFieldBuilder
-	ArrayLen idol.Uint32FieldBuilder
-	Options  idol.MessageFieldBuilder[MessageFieldOptions]
+	Name       idol.TextFieldBuilder
+	Tag        idol.Uint16FieldBuilder
+	Type       idol.EnumFieldBuilder[Type]
+	TypeName   idol.TextFieldBuilder
+	ArrayLen   idol.Uint32FieldBuilder
+	Options    idol.MessageFieldBuilder[MessageFieldOptions]
+	Doc        idol.TextFieldBuilder
+	SourceSpan idol.MessageFieldBuilder[SourceSpan]
 }
 
 type _MessageField__Builder struct {
@@ -2138,6 +2551,12 @@ func (b _MessageField__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(6, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(7, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(8, b.self.SourceSpan.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -2146,10 +2565,16 @@ func (b _MessageField__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) erro
 	if size == 0 {
 		return nil
 	}
-	var ht [56]uint8
+	var ht [72]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 8:
+		b.self.SourceSpan.PutThunk(ht[64:72])
+		fallthrough
+	case 7:
+		b.self.Doc.PutThunk(ht[56:64])
+		fallthrough
 	case 6:
 		b.self.Options.PutThunk(ht[48:56])
 		fallthrough
@@ -2182,6 +2607,12 @@ func (b _MessageField__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) erro
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -2204,6 +2635,10 @@ func (Union) Idol__MessageType() idol.MessageType[Union] {
 	return _Union__MessageType{}
 }
 
+func (Union) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x6F, 0x65, 0xD6, 0xEE, 0xED, 0x53, 0xD3, 0xD1, 0x6A, 0x0A, 0x25, 0x6C, 0x05, 0xF6, 0xB6, 0x94, 0xB0, 0xCE, 0xDF, 0x4C, 0x59, 0x59, 0xF1, 0xAA, 0xFE, 0xA1, 0x21, 0x70, 0x70, 0xB0, 0xB5, 0xA4}
+}
+
 func (m _Union__Message) Self() Union { return m.self }
 
 func (m _Union__Message) Type() idol.MessageType[Union] {
@@ -2223,6 +2658,12 @@ func (m _Union__Message) Clone() idol.MessageBuilder[Union] {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
 	b.Fields.Extend(m.self.Fields())
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(5) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
+	b.ReservedTags.Extend(m.self.ReservedTags())
+	b.ReservedNames.Extend(m.self.ReservedNames())
 	return b.Idol__MessageBuilder()
 }
 
@@ -2238,6 +2679,14 @@ func (f _Union__MessageFields) Name(tag uint16) string {
 		return "fields"
 	case 3:
 		return "options"
+	case 4:
+		return "doc"
+	case 5:
+		return "source_span"
+	case 6:
+		return "reserved_tags"
+	case 7:
+		return "reserved_names"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -2258,6 +2707,18 @@ func (f _Union__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(2) && !yield(2, f.self.Fields()) {
 			return
 		}
+		if f.Has(4) && !yield(4, f.self.Doc()) {
+			return
+		}
+		if f.Has(5) && !yield(5, f.self.SourceSpan()) {
+			return
+		}
+		if f.Has(6) && !yield(6, f.self.ReservedTags()) {
+			return
+		}
+		if f.Has(7) && !yield(7, f.self.ReservedNames()) {
+			return
+		}
 	}
 }
 
@@ -2266,6 +2727,10 @@ func (_Union__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d.Text(1)
 	d.MessageArray(2, (UnionField{}).Idol__MessageType().Decode)
 	d.Message(3, (UnionOptions{}).Idol__MessageType().Decode)
+	d.Text(4)
+	d.Message(5, (SourceSpan{}).Idol__MessageType().Decode)
+	d.Uint16Array(6)
+	d.TextArray(7)
 	return d.Finish()
 }
 
@@ -2293,10 +2758,27 @@ func (m Union) Fields() idol.MessageArray[UnionField] {
 	return idol.MessageArray[UnionField]{}
 }
 
+func (m Union) Doc() idol.Text { return m.msg.GetText(4) }
+
+func (m Union) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(5); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
+func (m Union) ReservedTags() idol.Uint16Array { return m.msg.GetUint16Array(6) }
+
+func (m Union) ReservedNames() idol.TextArray { return m.msg.GetTextArray(7) }
+
 type Union__Builder struct {
-	Name    idol.TextFieldBuilder
-	Options idol.MessageFieldBuilder[UnionOptions]
-	Fields  idol.MessageArrayFieldBuilder[UnionField]
+	Name          idol.TextFieldBuilder
+	Options       idol.MessageFieldBuilder[UnionOptions]
+	Fields        idol.MessageArrayFieldBuilder[UnionField]
+	Doc           idol.TextFieldBuilder
+	SourceSpan    idol.MessageFieldBuilder[SourceSpan]
+	ReservedTags  idol.Uint16ArrayFieldBuilder
+	ReservedNames idol.TextArrayFieldBuilder
 }
 
 type _Union__Builder struct {
@@ -2326,6 +2808,18 @@ func (b _Union__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(3, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(4, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(5, b.self.SourceSpan.DataSize())
+	}
+	if b.self.ReservedTags.IsPresent() {
+		m.Indirect(6, b.self.ReservedTags.DataSize())
+	}
+	if b.self.ReservedNames.IsPresent() {
+		m.Indirect(7, b.self.ReservedNames.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -2334,10 +2828,22 @@ func (b _Union__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if size == 0 {
 		return nil
 	}
-	var ht [32]uint8
+	var ht [64]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 7:
+		b.self.ReservedNames.PutThunk(ht[56:64])
+		fallthrough
+	case 6:
+		b.self.ReservedTags.PutThunk(ht[48:56])
+		fallthrough
+	case 5:
+		b.self.SourceSpan.PutThunk(ht[40:48])
+		fallthrough
+	case 4:
+		b.self.Doc.PutThunk(ht[32:40])
+		fallthrough
 	case 3:
 		b.self.Options.PutThunk(ht[24:32])
 		fallthrough
@@ -2361,6 +2867,18 @@ func (b _Union__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
+	if err := b.self.ReservedTags.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.ReservedNames.EncodeData(w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -2383,6 +2901,10 @@ func (UnionField) Idol__MessageType() idol.MessageType[UnionField] {
 	return _UnionField__MessageType{}
 }
 
+func (UnionField) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x97, 0x68, 0x09, 0x5F, 0x17, 0x16, 0xC5, 0x40, 0x54, 0x8E, 0x04, 0xCA, 0x5C, 0xEE, 0xA3, 0xCD, 0x3C, 0x1F, 0x34, 0x39, 0xC0, 0x19, 0xB8, 0x78, 0x8C, 0x70, 0xF2, 0xAE, 0x21, 0xDA, 0x49, 0x1C}
+}
+
 func (m _UnionField__Message) Self() UnionField { return m.self }
 
 func (m _UnionField__Message) Type() idol.MessageType[UnionField] {
@@ -2405,6 +2927,10 @@ func (m _UnionField__Message) Clone() idol.MessageBuilder[UnionField] {
 	if m.self.msg.Has(6) {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(8) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
 	return b.Idol__MessageBuilder()
 }
 
@@ -2426,6 +2952,10 @@ func (f _UnionField__MessageFields) Name(tag uint16) string {
 		return "array_len"
 	case 6:
 		return "options"
+	case 7:
+		return "doc"
+	case 8:
+		return "source_span"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -2455,6 +2985,12 @@ func (f _UnionField__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(6) && !yield(6, f.self.Options()) {
 			return
 		}
+		if f.Has(7) && !yield(7, f.self.Doc()) {
+			return
+		}
+		if f.Has(8) && !yield(8, f.self.SourceSpan()) {
+			return
+		}
 	}
 }
 
@@ -2466,6 +3002,8 @@ func (_UnionField__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d.Text(4)
 	d.Uint32(5)
 	d.Message(6, (UnionFieldOptions{}).Idol__MessageType().Decode)
+	d.Text(7)
+	d.Message(8, (SourceSpan{}).Idol__MessageType().Decode)
 	return d.Finish()
 }
 
@@ -2494,13 +3032,24 @@ func (m UnionField) Options() UnionFieldOptions {
 	return UnionFieldOptions{}
 }
 
+func (m UnionField) Doc() idol.Text { return m.msg.GetText(7) }
+
+func (m UnionField) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(8); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
 type UnionField__Builder struct {
-	Name     idol.TextFieldBuilder
-	Tag      idol.Uint16FieldBuilder
-	Type     idol.EnumFieldBuilder[Type]
-	TypeName idol.TextFieldBuilder
-	ArrayLen idol.Uint32FieldBuilder
-	Options  idol.MessageFieldBuilder[UnionFieldOptions]
+	Name       idol.TextFieldBuilder
+	Tag        idol.Uint16FieldBuilder
+	Type       idol.EnumFieldBuilder[Type]
+	TypeName   idol.TextFieldBuilder
+	ArrayLen   idol.Uint32FieldBuilder
+	Options    idol.MessageFieldBuilder[UnionFieldOptions]
+	Doc        idol.TextFieldBuilder
+	SourceSpan idol.MessageFieldBuilder[SourceSpan]
 }
 
 type _UnionField__Builder struct {
@@ -2539,6 +3088,12 @@ func (b _UnionField__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(6, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(7, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(8, b.self.SourceSpan.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -2547,10 +3102,16 @@ func (b _UnionField__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error
 	if size == 0 {
 		return nil
 	}
-	var ht [56]uint8
+	var ht [72]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 8:
+		b.self.SourceSpan.PutThunk(ht[64:72])
+		fallthrough
+	case 7:
+		b.self.Doc.PutThunk(ht[56:64])
+		fallthrough
 	case 6:
 		b.self.Options.PutThunk(ht[48:56])
 		fallthrough
@@ -2583,6 +3144,12 @@ func (b _UnionField__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -2605,6 +3172,10 @@ func (Protocol) Idol__MessageType() idol.MessageType[Protocol] {
 	return _Protocol__MessageType{}
 }
 
+func (Protocol) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x18, 0x03, 0xAB, 0x74, 0xA6, 0xA3, 0x33, 0xD1, 0x53, 0xE7, 0xB7, 0x8D, 0xBD, 0x10, 0x63, 0x19, 0x6E, 0x55, 0x5B, 0x24, 0x77, 0x28, 0x40, 0x66, 0x54, 0xF0, 0x69, 0xD0, 0x76, 0x8C, 0x54, 0xCB}
+}
+
 func (m _Protocol__Message) Self() Protocol { return m.self }
 
 func (m _Protocol__Message) Type() idol.MessageType[Protocol] {
@@ -2625,6 +3196,10 @@ func (m _Protocol__Message) Clone() idol.MessageBuilder[Protocol] {
 	}
 	b.Rpcs.Extend(m.self.Rpcs())
 	b.Events.Extend(m.self.Events())
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(6) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
 	return b.Idol__MessageBuilder()
 }
 
@@ -2642,6 +3217,10 @@ func (f _Protocol__MessageFields) Name(tag uint16) string {
 		return "events"
 	case 4:
 		return "options"
+	case 5:
+		return "doc"
+	case 6:
+		return "source_span"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -2665,6 +3244,12 @@ func (f _Protocol__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(3) && !yield(3, f.self.Events()) {
 			return
 		}
+		if f.Has(5) && !yield(5, f.self.Doc()) {
+			return
+		}
+		if f.Has(6) && !yield(6, f.self.SourceSpan()) {
+			return
+		}
 	}
 }
 
@@ -2674,6 +3259,8 @@ func (_Protocol__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d.MessageArray(2, (ProtocolRpc{}).Idol__MessageType().Decode)
 	d.MessageArray(3, (ProtocolEvent{}).Idol__MessageType().Decode)
 	d.Message(4, (ProtocolOptions{}).Idol__MessageType().Decode)
+	d.Text(5)
+	d.Message(6, (SourceSpan{}).Idol__MessageType().Decode)
 	return d.Finish()
 }
 
@@ -2708,11 +3295,22 @@ func (m Protocol) Events() idol.MessageArray[ProtocolEvent] {
 	return idol.MessageArray[ProtocolEvent]{}
 }
 
+func (m Protocol) Doc() idol.Text { return m.msg.GetText(5) }
+
+func (m Protocol) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(6); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
 type Protocol__Builder struct {
-	Name    idol.TextFieldBuilder
-	Options idol.MessageFieldBuilder[ProtocolOptions]
-	Rpcs    idol.MessageArrayFieldBuilder[ProtocolRpc]
-	Events  idol.MessageArrayFieldBuilder[ProtocolEvent]
+	Name       idol.TextFieldBuilder
+	Options    idol.MessageFieldBuilder[ProtocolOptions]
+	Rpcs       idol.MessageArrayFieldBuilder[ProtocolRpc]
+	Events     idol.MessageArrayFieldBuilder[ProtocolEvent]
+	Doc        idol.TextFieldBuilder
+	SourceSpan idol.MessageFieldBuilder[SourceSpan]
 }
 
 type _Protocol__Builder struct {
@@ -2745,6 +3343,12 @@ func (b _Protocol__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(4, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(5, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(6, b.self.SourceSpan.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -2753,10 +3357,16 @@ func (b _Protocol__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if size == 0 {
 		return nil
 	}
-	var ht [40]uint8
+	var ht [56]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 6:
+		b.self.SourceSpan.PutThunk(ht[48:56])
+		fallthrough
+	case 5:
+		b.self.Doc.PutThunk(ht[40:48])
+		fallthrough
 	case 4:
 		b.self.Options.PutThunk(ht[32:40])
 		fallthrough
@@ -2786,6 +3396,12 @@ func (b _Protocol__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -2808,6 +3424,10 @@ func (ProtocolRpc) Idol__MessageType() idol.MessageType[ProtocolRpc] {
 	return _ProtocolRpc__MessageType{}
 }
 
+func (ProtocolRpc) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x23, 0xB7, 0x5A, 0xBC, 0x9F, 0x1A, 0xBA, 0xF2, 0x07, 0x81, 0x56, 0x1E, 0x6E, 0xC6, 0xCE, 0xB3, 0x81, 0x79, 0x20, 0x9B, 0x81, 0xFE, 0xD3, 0x1B, 0x23, 0x6A, 0xA5, 0xAE, 0x5B, 0xF3, 0x85, 0x30}
+}
+
 func (m _ProtocolRpc__Message) Self() ProtocolRpc { return m.self }
 
 func (m _ProtocolRpc__Message) Type() idol.MessageType[ProtocolRpc] {
@@ -2835,6 +3455,10 @@ func (m _ProtocolRpc__Message) Clone() idol.MessageBuilder[ProtocolRpc] {
 	if m.self.msg.Has(9) {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(11) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
 	return b.Idol__MessageBuilder()
 }
 
@@ -2862,6 +3486,10 @@ func (f _ProtocolRpc__MessageFields) Name(tag uint16) string {
 		return "response_is_stream"
 	case 9:
 		return "options"
+	case 10:
+		return "doc"
+	case 11:
+		return "source_span"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -2903,6 +3531,12 @@ func (f _ProtocolRpc__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(9) && !yield(9, f.self.Options()) {
 			return
 		}
+		if f.Has(10) && !yield(10, f.self.Doc()) {
+			return
+		}
+		if f.Has(11) && !yield(11, f.self.SourceSpan()) {
+			return
+		}
 	}
 }
 
@@ -2917,6 +3551,8 @@ func (_ProtocolRpc__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error
 	d.Text(7)
 	d.Bool(8)
 	d.Message(9, (ProtocolRpcOptions{}).Idol__MessageType().Decode)
+	d.Text(10)
+	d.Message(11, (SourceSpan{}).Idol__MessageType().Decode)
 	return d.Finish()
 }
 
@@ -2956,6 +3592,15 @@ func (m ProtocolRpc) Options() ProtocolRpcOptions {
 	return ProtocolRpcOptions{}
 }
 
+func (m ProtocolRpc) Doc() idol.Text { return m.msg.GetText(10) }
+
+func (m ProtocolRpc) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(11); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
 type ProtocolRpc__Builder struct {
 	Name             idol.TextFieldBuilder
 	Tag              idol.Uint64FieldBuilder
@@ -2966,6 +3611,8 @@ type ProtocolRpc__Builder struct {
 	ResponseTypeName idol.TextFieldBuilder
 	ResponseIsStream idol.BoolFieldBuilder
 	Options          idol.MessageFieldBuilder[ProtocolRpcOptions]
+	Doc              idol.TextFieldBuilder
+	SourceSpan       idol.MessageFieldBuilder[SourceSpan]
 }
 
 type _ProtocolRpc__Builder struct {
@@ -3013,6 +3660,12 @@ func (b _ProtocolRpc__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(9, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(10, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(11, b.self.SourceSpan.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -3021,10 +3674,16 @@ func (b _ProtocolRpc__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error
 	if size == 0 {
 		return nil
 	}
-	var ht [80]uint8
+	var ht [96]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 11:
+		b.self.SourceSpan.PutThunk(ht[88:96])
+		fallthrough
+	case 10:
+		b.self.Doc.PutThunk(ht[80:88])
+		fallthrough
 	case 9:
 		b.self.Options.PutThunk(ht[72:80])
 		fallthrough
@@ -3072,6 +3731,12 @@ func (b _ProtocolRpc__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -3094,6 +3759,10 @@ func (ProtocolEvent) Idol__MessageType() idol.MessageType[ProtocolEvent] {
 	return _ProtocolEvent__MessageType{}
 }
 
+func (ProtocolEvent) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x61, 0x6F, 0xD7, 0x37, 0x3B, 0x51, 0x1A, 0xC1, 0x94, 0xB6, 0xDF, 0xCA, 0x66, 0xE9, 0xB2, 0x69, 0x7E, 0x0C, 0x33, 0xFF, 0x7C, 0xD2, 0x8B, 0x5D, 0xA7, 0xB9, 0x6C, 0x4B, 0xDE, 0x33, 0xF3, 0x17}
+}
+
 func (m _ProtocolEvent__Message) Self() ProtocolEvent { return m.self }
 
 func (m _ProtocolEvent__Message) Type() idol.MessageType[ProtocolEvent] {
@@ -3117,6 +3786,10 @@ func (m _ProtocolEvent__Message) Clone() idol.MessageBuilder[ProtocolEvent] {
 	if m.self.msg.Has(5) {
 		b.Options.Set(idol.Clone(m.self.Options()).Self())
 	}
+	b.Doc.Set(m.self.Doc())
+	if m.self.msg.Has(7) {
+		b.SourceSpan.Set(idol.Clone(m.self.SourceSpan()).Self())
+	}
 	return b.Idol__MessageBuilder()
 }
 
@@ -3136,6 +3809,10 @@ func (f _ProtocolEvent__MessageFields) Name(tag uint16) string {
 		return "payload_type_name"
 	case 5:
 		return "options"
+	case 6:
+		return "doc"
+	case 7:
+		return "source_span"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -3165,6 +3842,12 @@ func (f _ProtocolEvent__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(5) && !yield(5, f.self.Options()) {
 			return
 		}
+		if f.Has(6) && !yield(6, f.self.Doc()) {
+			return
+		}
+		if f.Has(7) && !yield(7, f.self.SourceSpan()) {
+			return
+		}
 	}
 }
 
@@ -3175,6 +3858,8 @@ func (_ProtocolEvent__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) erro
 	d.Uint8(3)
 	d.Text(4)
 	d.Message(5, (ProtocolEventOptions{}).Idol__MessageType().Decode)
+	d.Text(6)
+	d.Message(7, (SourceSpan{}).Idol__MessageType().Decode)
 	return d.Finish()
 }
 
@@ -3206,12 +3891,23 @@ func (m ProtocolEvent) Options() ProtocolEventOptions {
 	return ProtocolEventOptions{}
 }
 
+func (m ProtocolEvent) Doc() idol.Text { return m.msg.GetText(6) }
+
+func (m ProtocolEvent) SourceSpan() SourceSpan {
+	if v := m.msg.GetIndirect(7); len(v) > 0 {
+		return *(*SourceSpan)(unsafe_.Pointer(&v))
+	}
+	return SourceSpan{}
+}
+
 type ProtocolEvent__Builder struct {
 	Name            idol.TextFieldBuilder
 	Tag             idol.Uint64FieldBuilder
 	PayloadType     idol.EnumFieldBuilder[Type]
 	PayloadTypeName idol.TextFieldBuilder
 	Options         idol.MessageFieldBuilder[ProtocolEventOptions]
+	Doc             idol.TextFieldBuilder
+	SourceSpan      idol.MessageFieldBuilder[SourceSpan]
 }
 
 type _ProtocolEvent__Builder struct {
@@ -3247,6 +3943,12 @@ func (b _ProtocolEvent__Builder) messageSize() (uint32, uint16) {
 	if b.self.Options.IsPresent() {
 		m.Indirect(5, b.self.Options.DataSize())
 	}
+	if b.self.Doc.IsPresent() {
+		m.Indirect(6, b.self.Doc.DataSize())
+	}
+	if b.self.SourceSpan.IsPresent() {
+		m.Indirect(7, b.self.SourceSpan.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -3255,10 +3957,16 @@ func (b _ProtocolEvent__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) err
 	if size == 0 {
 		return nil
 	}
-	var ht [48]uint8
+	var ht [64]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 7:
+		b.self.SourceSpan.PutThunk(ht[56:64])
+		fallthrough
+	case 6:
+		b.self.Doc.PutThunk(ht[48:56])
+		fallthrough
 	case 5:
 		b.self.Options.PutThunk(ht[40:48])
 		fallthrough
@@ -3291,6 +3999,158 @@ func (b _ProtocolEvent__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) err
 	if err := b.self.Options.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Doc.EncodeData(w); err != nil {
+		return err
+	}
+	if err := b.self.SourceSpan.EncodeData(ctx, w); err != nil {
+		return err
+	}
+	return nil
+}
+
+type SourceSpan struct{ msg idol.DecodedMessage }
+
+type _SourceSpan__Message struct {
+	idol.IsGeneratedMessage[SourceSpan]
+	self SourceSpan
+}
+
+type _SourceSpan__MessageType struct {
+	idol.IsGeneratedMessageType[SourceSpan]
+}
+
+func (m SourceSpan) Idol__Message() idol.Message[SourceSpan] {
+	return _SourceSpan__Message{self: m}
+}
+
+func (SourceSpan) Idol__MessageType() idol.MessageType[SourceSpan] {
+	return _SourceSpan__MessageType{}
+}
+
+func (SourceSpan) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xE5, 0x3E, 0xA5, 0xBA, 0x4C, 0x2D, 0x06, 0x5C, 0x8D, 0xDE, 0xE5, 0x12, 0xAF, 0x4A, 0x4A, 0x30, 0x91, 0x4B, 0xFB, 0x2F, 0x5E, 0x2A, 0x46, 0xCF, 0x57, 0x4F, 0xAB, 0x89, 0x9A, 0xE8, 0xFC, 0x1D}
+}
+
+func (m _SourceSpan__Message) Self() SourceSpan { return m.self }
+
+func (m _SourceSpan__Message) Type() idol.MessageType[SourceSpan] {
+	return _SourceSpan__MessageType{}
+}
+
+func (m _SourceSpan__Message) Size() uint32 { return m.self.msg.Size() }
+
+func (m _SourceSpan__Message) Fields() idol.MessageFields {
+	return _SourceSpan__MessageFields{m.self}
+}
+
+func (m _SourceSpan__Message) Clone() idol.MessageBuilder[SourceSpan] {
+	b := &SourceSpan__Builder{}
+	b.Start.Set(m.self.Start())
+	b.Len.Set(m.self.Len())
+	return b.Idol__MessageBuilder()
+}
+
+type _SourceSpan__MessageFields struct {
+	self SourceSpan
+}
+
+func (f _SourceSpan__MessageFields) Name(tag uint16) string {
+	switch tag {
+	case 1:
+		return "start"
+	case 2:
+		return "len"
+	default:
+		return fmt_.Sprintf("@%d", tag)
+	}
+}
+
+func (f _SourceSpan__MessageFields) Has(tag uint16) bool {
+	return f.self.msg.Has(tag)
+}
+
+func (f _SourceSpan__MessageFields) Values() iter_.Seq2[uint16, any] {
+	return func(yield func(uint16, any) bool) {
+		if f.Has(1) && !yield(1, f.self.Start()) {
+			return
+		}
+		if f.Has(2) && !yield(2, f.self.Len()) {
+			return
+		}
+	}
+}
+
+func (_SourceSpan__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
+	d := idol.NewMessageDecoder(ctx, buf)
+	d.Uint32(1)
+	d.Uint32(2)
+	return d.Finish()
+}
+
+func (_SourceSpan__MessageType) DecodeAs(ctx *idol.DecodeCtx, buf []uint8) (SourceSpan, error) {
+	if err := (_SourceSpan__MessageType{}).Decode(ctx, buf); err != nil {
+		return SourceSpan{}, err
+	}
+	frozen := string(buf)
+	return *(*SourceSpan)(unsafe_.Pointer(&frozen)), nil
+}
+
+func (m SourceSpan) Start() uint32 { return m.msg.GetUint32(1) }
+
+func (m SourceSpan) Len() uint32 { return m.msg.GetUint32(2) }
+
+type SourceSpan__Builder struct {
+	Start idol.Uint32FieldBuilder
+	Len   idol.Uint32FieldBuilder
+}
+
+type _SourceSpan__Builder struct {
+	idol.IsGeneratedMessageBuilder[SourceSpan]
+	self *SourceSpan__Builder
+}
+
+func (b *SourceSpan__Builder) Idol__MessageBuilder() idol.MessageBuilder[SourceSpan] {
+	return _SourceSpan__Builder{self: b}
+}
+
+func (m _SourceSpan__Builder) Self() idol.AsMessageBuilder[SourceSpan] { return m.self }
+
+func (b _SourceSpan__Builder) Size() uint32 {
+	size, _ := b.messageSize()
+	return size
+}
+
+func (b _SourceSpan__Builder) messageSize() (uint32, uint16) {
+	var m idol.MessageSizeBuilder
+	if b.self.Start.IsPresent() {
+		m.Scalar(1)
+	}
+	if b.self.Len.IsPresent() {
+		m.Scalar(2)
+	}
+	return m.Finish()
+}
+
+func (b _SourceSpan__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) error {
+	size, thunkCount := b.messageSize()
+	if size == 0 {
+		return nil
+	}
+	var ht [24]uint8
+	binary_.LittleEndian.PutUint32(ht[0:4], size)
+	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
+	switch thunkCount {
+	case 2:
+		b.self.Len.PutThunk(ht[16:24])
+		fallthrough
+	case 1:
+		b.self.Start.PutThunk(ht[8:16])
+		fallthrough
+	case 0:
+	}
+	if _, err := w.Write(ht[:8+uint32(thunkCount)*8]); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -3313,6 +4173,10 @@ func (SchemaOptions) Idol__MessageType() idol.MessageType[SchemaOptions] {
 	return _SchemaOptions__MessageType{}
 }
 
+func (SchemaOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xFD, 0x63, 0x10, 0x69, 0x01, 0x9B, 0x88, 0x66, 0x4D, 0xC5, 0x48, 0x5C, 0x5E, 0xA3, 0xBB, 0x93, 0xDF, 0xCA, 0xD2, 0x5A, 0x7E, 0x35, 0xFF, 0x9A, 0x0F, 0x5E, 0x94, 0x7D, 0x7F, 0x33, 0x2E, 0x21}
+}
+
 func (m _SchemaOptions__Message) Self() SchemaOptions { return m.self }
 
 func (m _SchemaOptions__Message) Type() idol.MessageType[SchemaOptions] {
@@ -3447,6 +4311,10 @@ func (ConstOptions) Idol__MessageType() idol.MessageType[ConstOptions] {
 	return _ConstOptions__MessageType{}
 }
 
+func (ConstOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xFB, 0x6F, 0x28, 0x3F, 0x1E, 0x9C, 0x09, 0x89, 0xAB, 0x3F, 0x2E, 0xF0, 0x76, 0x84, 0x46, 0x35, 0x97, 0x58, 0xFF, 0x6F, 0xE7, 0xE9, 0xBB, 0x53, 0xD6, 0xF1, 0x34, 0xF4, 0x62, 0xEA, 0xC3, 0xF2}
+}
+
 func (m _ConstOptions__Message) Self() ConstOptions { return m.self }
 
 func (m _ConstOptions__Message) Type() idol.MessageType[ConstOptions] {
@@ -3581,6 +4449,10 @@ func (EnumOptions) Idol__MessageType() idol.MessageType[EnumOptions] {
 	return _EnumOptions__MessageType{}
 }
 
+func (EnumOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x67, 0x82, 0x46, 0x8B, 0xEA, 0xA4, 0xC7, 0x4E, 0xD4, 0x08, 0xFA, 0xE1, 0x97, 0x36, 0xED, 0x2D, 0x07, 0xFD, 0x9F, 0xED, 0x9F, 0x93, 0xD8, 0x4C, 0x58, 0x01, 0xBB, 0xA4, 0xEA, 0x9D, 0x1D, 0xE1}
+}
+
 func (m _EnumOptions__Message) Self() EnumOptions { return m.self }
 
 func (m _EnumOptions__Message) Type() idol.MessageType[EnumOptions] {
@@ -3715,6 +4587,10 @@ func (EnumItemOptions) Idol__MessageType() idol.MessageType[EnumItemOptions] {
 	return _EnumItemOptions__MessageType{}
 }
 
+func (EnumItemOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x32, 0x3F, 0x3C, 0x04, 0x5F, 0xDB, 0xC1, 0xFA, 0x77, 0x69, 0x78, 0x91, 0x22, 0x7C, 0x08, 0x59, 0x75, 0xCF, 0xF9, 0x1E, 0xB6, 0xC6, 0x02, 0x60, 0xFC, 0xDB, 0xA4, 0xA5, 0xEB, 0x24, 0x39, 0x08}
+}
+
 func (m _EnumItemOptions__Message) Self() EnumItemOptions { return m.self }
 
 func (m _EnumItemOptions__Message) Type() idol.MessageType[EnumItemOptions] {
@@ -3730,6 +4606,7 @@ func (m _EnumItemOptions__Message) Fields() idol.MessageFields {
 func (m _EnumItemOptions__Message) Clone() idol.MessageBuilder[EnumItemOptions] {
 	b := &EnumItemOptions__Builder{}
 	b.Uninterpreted.Extend(m.self.Uninterpreted())
+	b.Deprecated.Set(m.self.Deprecated())
 	return b.Idol__MessageBuilder()
 }
 
@@ -3741,6 +4618,8 @@ func (f _EnumItemOptions__MessageFields) Name(tag uint16) string {
 	switch tag {
 	case 2:
 		return "uninterpreted"
+	case 3:
+		return "deprecated"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -3755,12 +4634,16 @@ func (f _EnumItemOptions__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(2) && !yield(2, f.self.Uninterpreted()) {
 			return
 		}
+		if f.Has(3) && !yield(3, f.self.Deprecated()) {
+			return
+		}
 	}
 }
 
 func (_EnumItemOptions__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d := idol.NewMessageDecoder(ctx, buf)
 	d.MessageArray(2, (UninterpretedOptions{}).Idol__MessageType().Decode)
+	d.Bool(3)
 	return d.Finish()
 }
 
@@ -3779,8 +4662,11 @@ func (m EnumItemOptions) Uninterpreted() idol.MessageArray[UninterpretedOptions]
 	return idol.MessageArray[UninterpretedOptions]{}
 }
 
+func (m EnumItemOptions) Deprecated() bool { return m.msg.GetBool(3) }
+
 type EnumItemOptions__Builder struct {
 	Uninterpreted idol.MessageArrayFieldBuilder[UninterpretedOptions]
+	Deprecated    idol.BoolFieldBuilder
 }
 
 type _EnumItemOptions__Builder struct {
@@ -3804,6 +4690,9 @@ func (b _EnumItemOptions__Builder) messageSize() (uint32, uint16) {
 	if b.self.Uninterpreted.IsPresent() {
 		m.Indirect(2, b.self.Uninterpreted.DataSize())
 	}
+	if b.self.Deprecated.IsPresent() {
+		m.Scalar(3)
+	}
 	return m.Finish()
 }
 
@@ -3812,10 +4701,13 @@ func (b _EnumItemOptions__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) e
 	if size == 0 {
 		return nil
 	}
-	var ht [24]uint8
+	var ht [32]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 3:
+		b.self.Deprecated.PutThunk(ht[24:32])
+		fallthrough
 	case 2:
 		b.self.Uninterpreted.PutThunk(ht[16:24])
 		fallthrough
@@ -3849,6 +4741,10 @@ func (StructOptions) Idol__MessageType() idol.MessageType[StructOptions] {
 	return _StructOptions__MessageType{}
 }
 
+func (StructOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x3B, 0x09, 0x17, 0xF0, 0xFB, 0x79, 0xDB, 0xB5, 0x91, 0xCF, 0x4B, 0xF2, 0x15, 0x45, 0xE6, 0x96, 0x55, 0xA9, 0xA4, 0x48, 0x15, 0xA7, 0x49, 0x11, 0xAE, 0xB3, 0x75, 0xC9, 0x90, 0x77, 0x77, 0xFC}
+}
+
 func (m _StructOptions__Message) Self() StructOptions { return m.self }
 
 func (m _StructOptions__Message) Type() idol.MessageType[StructOptions] {
@@ -3983,6 +4879,10 @@ func (StructFieldOptions) Idol__MessageType() idol.MessageType[StructFieldOption
 	return _StructFieldOptions__MessageType{}
 }
 
+func (StructFieldOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x0E, 0x0A, 0x5A, 0xAA, 0x41, 0x05, 0x70, 0x37, 0x72, 0xE4, 0xFC, 0xB3, 0x69, 0x9C, 0x72, 0xA2, 0x44, 0x34, 0x75, 0xD6, 0xE9, 0x0D, 0xA0, 0xAA, 0x80, 0xD5, 0x58, 0x51, 0xCE, 0xFE, 0x4D, 0xAE}
+}
+
 func (m _StructFieldOptions__Message) Self() StructFieldOptions { return m.self }
 
 func (m _StructFieldOptions__Message) Type() idol.MessageType[StructFieldOptions] {
@@ -4117,6 +5017,10 @@ func (MessageOptions) Idol__MessageType() idol.MessageType[MessageOptions] {
 	return _MessageOptions__MessageType{}
 }
 
+func (MessageOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x5E, 0x98, 0xA8, 0x80, 0x79, 0xC1, 0x59, 0xCD, 0xC6, 0xAB, 0x7C, 0x40, 0x93, 0x7D, 0x4B, 0x39, 0x2C, 0xD4, 0xA0, 0xEB, 0x30, 0xB8, 0x94, 0x34, 0x5D, 0x52, 0x05, 0x96, 0xA0, 0x49, 0x50, 0x91}
+}
+
 func (m _MessageOptions__Message) Self() MessageOptions { return m.self }
 
 func (m _MessageOptions__Message) Type() idol.MessageType[MessageOptions] {
@@ -4132,6 +5036,7 @@ func (m _MessageOptions__Message) Fields() idol.MessageFields {
 func (m _MessageOptions__Message) Clone() idol.MessageBuilder[MessageOptions] {
 	b := &MessageOptions__Builder{}
 	b.Uninterpreted.Extend(m.self.Uninterpreted())
+	b.Deprecated.Set(m.self.Deprecated())
 	return b.Idol__MessageBuilder()
 }
 
@@ -4143,6 +5048,8 @@ func (f _MessageOptions__MessageFields) Name(tag uint16) string {
 	switch tag {
 	case 2:
 		return "uninterpreted"
+	case 3:
+		return "deprecated"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -4157,12 +5064,16 @@ func (f _MessageOptions__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(2) && !yield(2, f.self.Uninterpreted()) {
 			return
 		}
+		if f.Has(3) && !yield(3, f.self.Deprecated()) {
+			return
+		}
 	}
 }
 
 func (_MessageOptions__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d := idol.NewMessageDecoder(ctx, buf)
 	d.MessageArray(2, (UninterpretedOptions{}).Idol__MessageType().Decode)
+	d.Bool(3)
 	return d.Finish()
 }
 
@@ -4181,8 +5092,11 @@ func (m MessageOptions) Uninterpreted() idol.MessageArray[UninterpretedOptions]
 	return idol.MessageArray[UninterpretedOptions]{}
 }
 
+func (m MessageOptions) Deprecated() bool { return m.msg.GetBool(3) }
+
 type MessageOptions__Builder struct {
 	Uninterpreted idol.MessageArrayFieldBuilder[UninterpretedOptions]
+	Deprecated    idol.BoolFieldBuilder
 }
 
 type _MessageOptions__Builder struct {
@@ -4206,6 +5120,9 @@ func (b _MessageOptions__Builder) messageSize() (uint32, uint16) {
 	if b.self.Uninterpreted.IsPresent() {
 		m.Indirect(2, b.self.Uninterpreted.DataSize())
 	}
+	if b.self.Deprecated.IsPresent() {
+		m.Scalar(3)
+	}
 	return m.Finish()
 }
 
@@ -4214,10 +5131,13 @@ func (b _MessageOptions__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer) er
 	if size == 0 {
 		return nil
 	}
-	var ht [24]uint8
+	var ht [32]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 3:
+		b.self.Deprecated.PutThunk(ht[24:32])
+		fallthrough
 	case 2:
 		b.self.Uninterpreted.PutThunk(ht[16:24])
 		fallthrough
@@ -4251,6 +5171,10 @@ func (MessageFieldOptions) Idol__MessageType() idol.MessageType[MessageFieldOpti
 	return _MessageFieldOptions__MessageType{}
 }
 
+func (MessageFieldOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xBA, 0xE7, 0x04, 0xD3, 0xFE, 0x78, 0x83, 0x16, 0xD2, 0x88, 0x0F, 0x92, 0xA7, 0xA0, 0xE7, 0xCC, 0x90, 0xB5, 0x52, 0xDF, 0xC5, 0x50, 0xEF, 0xF5, 0xD1, 0xB9, 0x86, 0x4E, 0x0D, 0x80, 0x37, 0x82}
+}
+
 func (m _MessageFieldOptions__Message) Self() MessageFieldOptions { return m.self }
 
 func (m _MessageFieldOptions__Message) Type() idol.MessageType[MessageFieldOptions] {
@@ -4267,6 +5191,8 @@ func (m _MessageFieldOptions__Message) Clone() idol.MessageBuilder[MessageFieldO
 	b := &MessageFieldOptions__Builder{}
 	b.Optional.Set(m.self.Optional())
 	b.Uninterpreted.Extend(m.self.Uninterpreted())
+	b.Deprecated.Set(m.self.Deprecated())
+	b.Default.Extend(m.self.Default())
 	return b.Idol__MessageBuilder()
 }
 
@@ -4280,6 +5206,10 @@ func (f _MessageFieldOptions__MessageFields) Name(tag uint16) string {
 		return "optional"
 	case 3:
 		return "uninterpreted"
+	case 4:
+		return "deprecated"
+	case 5:
+		return "default"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -4297,6 +5227,12 @@ func (f _MessageFieldOptions__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(3) && !yield(3, f.self.Uninterpreted()) {
 			return
 		}
+		if f.Has(4) && !yield(4, f.self.Deprecated()) {
+			return
+		}
+		if f.Has(5) && !yield(5, f.self.Default()) {
+			return
+		}
 	}
 }
 
@@ -4304,6 +5240,8 @@ func (_MessageFieldOptions__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8
 	d := idol.NewMessageDecoder(ctx, buf)
 	d.Bool(2)
 	d.MessageArray(3, (UninterpretedOptions{}).Idol__MessageType().Decode)
+	d.Bool(4)
+	d.Uint8Array(5)
 	return d.Finish()
 }
 
@@ -4324,9 +5262,15 @@ func (m MessageFieldOptions) Uninterpreted() idol.MessageArray[UninterpretedOpti
 	return idol.MessageArray[UninterpretedOptions]{}
 }
 
+func (m MessageFieldOptions) Deprecated() bool { return m.msg.GetBool(4) }
+
+func (m MessageFieldOptions) Default() idol.Uint8Array { return m.msg.GetUint8Array(5) }
+
 type MessageFieldOptions__Builder struct {
 	Optional      idol.BoolFieldBuilder
 	Uninterpreted idol.MessageArrayFieldBuilder[UninterpretedOptions]
+	Deprecated    idol.BoolFieldBuilder
+	Default       idol.Uint8ArrayFieldBuilder
 }
 
 type _MessageFieldOptions__Builder struct {
@@ -4355,6 +5299,12 @@ func (b _MessageFieldOptions__Builder) messageSize() (uint32, uint16) {
 	if b.self.Uninterpreted.IsPresent() {
 		m.Indirect(3, b.self.Uninterpreted.DataSize())
 	}
+	if b.self.Deprecated.IsPresent() {
+		m.Scalar(4)
+	}
+	if b.self.Default.IsPresent() {
+		m.Indirect(5, b.self.Default.DataSize())
+	}
 	return m.Finish()
 }
 
@@ -4363,10 +5313,16 @@ func (b _MessageFieldOptions__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Write
 	if size == 0 {
 		return nil
 	}
-	var ht [32]uint8
+	var ht [48]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 5:
+		b.self.Default.PutThunk(ht[40:48])
+		fallthrough
+	case 4:
+		b.self.Deprecated.PutThunk(ht[32:40])
+		fallthrough
 	case 3:
 		b.self.Uninterpreted.PutThunk(ht[24:32])
 		fallthrough
@@ -4381,6 +5337,9 @@ func (b _MessageFieldOptions__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Write
 	if err := b.self.Uninterpreted.EncodeData(ctx, w); err != nil {
 		return err
 	}
+	if err := b.self.Default.EncodeData(w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -4403,6 +5362,10 @@ func (UnionOptions) Idol__MessageType() idol.MessageType[UnionOptions] {
 	return _UnionOptions__MessageType{}
 }
 
+func (UnionOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x2B, 0x8D, 0x17, 0xBB, 0xE9, 0x59, 0x3E, 0xBD, 0x46, 0xFF, 0x1C, 0x99, 0x38, 0xAD, 0x6A, 0x38, 0x46, 0x74, 0x2A, 0x47, 0x8B, 0xC2, 0xB2, 0x5E, 0x8C, 0x62, 0x17, 0x05, 0xAE, 0x01, 0x92, 0xE8}
+}
+
 func (m _UnionOptions__Message) Self() UnionOptions { return m.self }
 
 func (m _UnionOptions__Message) Type() idol.MessageType[UnionOptions] {
@@ -4537,6 +5500,10 @@ func (UnionFieldOptions) Idol__MessageType() idol.MessageType[UnionFieldOptions]
 	return _UnionFieldOptions__MessageType{}
 }
 
+func (UnionFieldOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x41, 0x38, 0x00, 0x48, 0x48, 0x31, 0x70, 0xAE, 0x51, 0xC5, 0x91, 0x80, 0x12, 0xD6, 0x29, 0x14, 0x31, 0xD6, 0xFA, 0x66, 0xF1, 0xCF, 0xBA, 0x02, 0x5E, 0xAE, 0xA8, 0xF9, 0xD5, 0xD2, 0x22, 0xC1}
+}
+
 func (m _UnionFieldOptions__Message) Self() UnionFieldOptions { return m.self }
 
 func (m _UnionFieldOptions__Message) Type() idol.MessageType[UnionFieldOptions] {
@@ -4671,6 +5638,10 @@ func (ProtocolOptions) Idol__MessageType() idol.MessageType[ProtocolOptions] {
 	return _ProtocolOptions__MessageType{}
 }
 
+func (ProtocolOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x24, 0x2E, 0x6E, 0x45, 0x98, 0x9D, 0x78, 0x51, 0x15, 0xBF, 0x57, 0x12, 0x44, 0x04, 0x24, 0xBB, 0xE2, 0xD8, 0xBF, 0xC2, 0x93, 0x99, 0xB1, 0x3B, 0xEC, 0x8E, 0xDC, 0xDB, 0xD8, 0x16, 0x11, 0xA2}
+}
+
 func (m _ProtocolOptions__Message) Self() ProtocolOptions { return m.self }
 
 func (m _ProtocolOptions__Message) Type() idol.MessageType[ProtocolOptions] {
@@ -4805,6 +5776,10 @@ func (ProtocolRpcOptions) Idol__MessageType() idol.MessageType[ProtocolRpcOption
 	return _ProtocolRpcOptions__MessageType{}
 }
 
+func (ProtocolRpcOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xBB, 0x76, 0x8F, 0xD6, 0x6C, 0x0C, 0x76, 0x45, 0x6E, 0x59, 0xFA, 0xBD, 0x21, 0x3F, 0x8A, 0x29, 0x24, 0x9F, 0x56, 0x53, 0xED, 0x59, 0x8E, 0xAA, 0xB2, 0xFA, 0xF8, 0xD2, 0xE3, 0x7C, 0x3A, 0xC5}
+}
+
 func (m _ProtocolRpcOptions__Message) Self() ProtocolRpcOptions { return m.self }
 
 func (m _ProtocolRpcOptions__Message) Type() idol.MessageType[ProtocolRpcOptions] {
@@ -4820,6 +5795,7 @@ func (m _ProtocolRpcOptions__Message) Fields() idol.MessageFields {
 func (m _ProtocolRpcOptions__Message) Clone() idol.MessageBuilder[ProtocolRpcOptions] {
 	b := &ProtocolRpcOptions__Builder{}
 	b.Uninterpreted.Extend(m.self.Uninterpreted())
+	b.Deprecated.Set(m.self.Deprecated())
 	return b.Idol__MessageBuilder()
 }
 
@@ -4831,6 +5807,8 @@ func (f _ProtocolRpcOptions__MessageFields) Name(tag uint16) string {
 	switch tag {
 	case 2:
 		return "uninterpreted"
+	case 3:
+		return "deprecated"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -4845,12 +5823,16 @@ func (f _ProtocolRpcOptions__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(2) && !yield(2, f.self.Uninterpreted()) {
 			return
 		}
+		if f.Has(3) && !yield(3, f.self.Deprecated()) {
+			return
+		}
 	}
 }
 
 func (_ProtocolRpcOptions__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d := idol.NewMessageDecoder(ctx, buf)
 	d.MessageArray(2, (UninterpretedOptions{}).Idol__MessageType().Decode)
+	d.Bool(3)
 	return d.Finish()
 }
 
@@ -4869,8 +5851,11 @@ func (m ProtocolRpcOptions) Uninterpreted() idol.MessageArray[UninterpretedOptio
 	return idol.MessageArray[UninterpretedOptions]{}
 }
 
+func (m ProtocolRpcOptions) Deprecated() bool { return m.msg.GetBool(3) }
+
 type ProtocolRpcOptions__Builder struct {
 	Uninterpreted idol.MessageArrayFieldBuilder[UninterpretedOptions]
+	Deprecated    idol.BoolFieldBuilder
 }
 
 type _ProtocolRpcOptions__Builder struct {
@@ -4894,6 +5879,9 @@ func (b _ProtocolRpcOptions__Builder) messageSize() (uint32, uint16) {
 	if b.self.Uninterpreted.IsPresent() {
 		m.Indirect(2, b.self.Uninterpreted.DataSize())
 	}
+	if b.self.Deprecated.IsPresent() {
+		m.Scalar(3)
+	}
 	return m.Finish()
 }
 
@@ -4902,10 +5890,13 @@ func (b _ProtocolRpcOptions__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writer
 	if size == 0 {
 		return nil
 	}
-	var ht [24]uint8
+	var ht [32]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 3:
+		b.self.Deprecated.PutThunk(ht[24:32])
+		fallthrough
 	case 2:
 		b.self.Uninterpreted.PutThunk(ht[16:24])
 		fallthrough
@@ -4939,6 +5930,10 @@ func (ProtocolEventOptions) Idol__MessageType() idol.MessageType[ProtocolEventOp
 	return _ProtocolEventOptions__MessageType{}
 }
 
+func (ProtocolEventOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xA3, 0xC2, 0xDA, 0x72, 0x55, 0x8A, 0xCF, 0xE0, 0x92, 0xC4, 0xC3, 0x20, 0xFE, 0xEE, 0x34, 0xA9, 0x30, 0x85, 0x84, 0xE2, 0x10, 0xF7, 0x6A, 0x06, 0x9F, 0xA7, 0xAB, 0xF1, 0x3F, 0x18, 0xBD, 0x69}
+}
+
 func (m _ProtocolEventOptions__Message) Self() ProtocolEventOptions { return m.self }
 
 func (m _ProtocolEventOptions__Message) Type() idol.MessageType[ProtocolEventOptions] {
@@ -4954,6 +5949,7 @@ func (m _ProtocolEventOptions__Message) Fields() idol.MessageFields {
 func (m _ProtocolEventOptions__Message) Clone() idol.MessageBuilder[ProtocolEventOptions] {
 	b := &ProtocolEventOptions__Builder{}
 	b.Uninterpreted.Extend(m.self.Uninterpreted())
+	b.Deprecated.Set(m.self.Deprecated())
 	return b.Idol__MessageBuilder()
 }
 
@@ -4965,6 +5961,8 @@ func (f _ProtocolEventOptions__MessageFields) Name(tag uint16) string {
 	switch tag {
 	case 2:
 		return "uninterpreted"
+	case 3:
+		return "deprecated"
 	default:
 		return fmt_.Sprintf("@%d", tag)
 	}
@@ -4979,12 +5977,16 @@ func (f _ProtocolEventOptions__MessageFields) Values() iter_.Seq2[uint16, any] {
 		if f.Has(2) && !yield(2, f.self.Uninterpreted()) {
 			return
 		}
+		if f.Has(3) && !yield(3, f.self.Deprecated()) {
+			return
+		}
 	}
 }
 
 func (_ProtocolEventOptions__MessageType) Decode(ctx *idol.DecodeCtx, buf []uint8) error {
 	d := idol.NewMessageDecoder(ctx, buf)
 	d.MessageArray(2, (UninterpretedOptions{}).Idol__MessageType().Decode)
+	d.Bool(3)
 	return d.Finish()
 }
 
@@ -5003,8 +6005,11 @@ func (m ProtocolEventOptions) Uninterpreted() idol.MessageArray[UninterpretedOpt
 	return idol.MessageArray[UninterpretedOptions]{}
 }
 
+func (m ProtocolEventOptions) Deprecated() bool { return m.msg.GetBool(3) }
+
 type ProtocolEventOptions__Builder struct {
 	Uninterpreted idol.MessageArrayFieldBuilder[UninterpretedOptions]
+	Deprecated    idol.BoolFieldBuilder
 }
 
 type _ProtocolEventOptions__Builder struct {
@@ -5030,6 +6035,9 @@ func (b _ProtocolEventOptions__Builder) messageSize() (uint32, uint16) {
 	if b.self.Uninterpreted.IsPresent() {
 		m.Indirect(2, b.self.Uninterpreted.DataSize())
 	}
+	if b.self.Deprecated.IsPresent() {
+		m.Scalar(3)
+	}
 	return m.Finish()
 }
 
@@ -5038,10 +6046,13 @@ func (b _ProtocolEventOptions__Builder) EncodeTo(ctx *idol.EncodeCtx, w io_.Writ
 	if size == 0 {
 		return nil
 	}
-	var ht [24]uint8
+	var ht [32]uint8
 	binary_.LittleEndian.PutUint32(ht[0:4], size)
 	binary_.LittleEndian.PutUint16(ht[6:8], thunkCount)
 	switch thunkCount {
+	case 3:
+		b.self.Deprecated.PutThunk(ht[24:32])
+		fallthrough
 	case 2:
 		b.self.Uninterpreted.PutThunk(ht[16:24])
 		fallthrough
@@ -5075,6 +6086,10 @@ func (UninterpretedOptions) Idol__MessageType() idol.MessageType[UninterpretedOp
 	return _UninterpretedOptions__MessageType{}
 }
 
+func (UninterpretedOptions) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0x2C, 0x43, 0x61, 0xD3, 0x50, 0x94, 0xA2, 0xA5, 0x75, 0xB3, 0x87, 0x25, 0x24, 0x96, 0x53, 0x22, 0x19, 0x35, 0x43, 0xD1, 0x1A, 0xF9, 0x39, 0x99, 0xE0, 0xF4, 0xDD, 0x10, 0x61, 0xAF, 0x72, 0x0A}
+}
+
 func (m _UninterpretedOptions__Message) Self() UninterpretedOptions { return m.self }
 
 func (m _UninterpretedOptions__Message) Type() idol.MessageType[UninterpretedOptions] {
@@ -5246,6 +6261,10 @@ func (UninterpretedOption) Idol__MessageType() idol.MessageType[UninterpretedOpt
 	return _UninterpretedOption__MessageType{}
 }
 
+func (UninterpretedOption) Idol__Fingerprint() idol.Fingerprint {
+	return idol.Fingerprint{0xBE, 0x65, 0xC8, 0x30, 0xAC, 0xA6, 0xB5, 0xCC, 0xE7, 0x94, 0x3C, 0xCA, 0x4C, 0x57, 0x8A, 0xFD, 0xB3, 0x06, 0x44, 0x74, 0x05, 0x09, 0xB6, 0xC6, 0x22, 0x5D, 0x11, 0x90, 0x8F, 0x4D, 0xBA, 0x69}
+}
+
 func (m _UninterpretedOption__Message) Self() UninterpretedOption { return m.self }
 
 func (m _UninterpretedOption__Message) Type() idol.MessageType[UninterpretedOption] {
@@ -17,6 +17,7 @@ package syntax
 
 import (
 	"bytes"
+	"slices"
 )
 
 type ParseOption interface {
@@ -273,6 +274,38 @@ func (ctx *parseCtx[T]) suffixComment() {
 	// TODO
 }
 
+func (ctx *parseCtx[T]) docComments() []*Comment {
+	var docs []*Comment
+	newlines := 0
+	for _, child := range slices.Backward(ctx.childNodes) {
+		switch child := child.(type) {
+		case *Space:
+		case *Newline:
+			newlines += 1
+			if newlines > 1 {
+				slices.Reverse(docs)
+				return docs
+			}
+		case *Comment:
+			if !child.IsDocComment() {
+				slices.Reverse(docs)
+				return docs
+			}
+			docs = append(docs, child)
+			newlines = 0
+		default:
+			// A doc comment must start its own line.
+			if newlines == 0 && len(docs) > 0 {
+				docs = docs[:len(docs)-1]
+			}
+			slices.Reverse(docs)
+			return docs
+		}
+	}
+	slices.Reverse(docs)
+	return docs
+}
+
 func (ctx *parseCtx[T]) sigil(kind TokenKind) {
 	if err := ctx.ensureToken(); err != nil {
 		return
@@ -362,6 +395,27 @@ func (ctx *parseCtx[T]) int() *IntLit {
 	return intNode
 }
 
+// negativeInt parses a '-' operator token directly followed by an integer
+// literal as a negative integer literal.
+func (ctx *parseCtx[T]) negativeInt() *IntLit {
+	if len(ctx.src) < 2 || ctx.src[1] < '0' || ctx.src[1] > '9' {
+		return nil
+	}
+	start := ctx.offset
+	ctx.consumeToken(nil)
+	if err := ctx.ensureToken(); err != nil {
+		return nil
+	}
+	token := "-" + string(ctx.readToken())
+	intNode, err := newIntLit(token, ctx.token.Kind, start)
+	if err != nil {
+		ctx.err = err
+		return nil
+	}
+	ctx.consumeToken(intNode)
+	return intNode
+}
+
 func (ctx *parseCtx[T]) text() *TextLit {
 	if err := ctx.ensureToken(); err != nil {
 		return nil
@@ -457,6 +511,7 @@ func parseSchema(ctx *parseCtx[Schema]) (*Schema, error) {
 			break
 		}
 
+		docs := ctx.docComments()
 		decorators := parseDecorators(ctx)
 
 		var ok bool
@@ -464,36 +519,42 @@ func parseSchema(ctx *parseCtx[Schema]) (*Schema, error) {
 			var decl *Const
 			if decl, ok = parseChild(ctx, parseConst); ok {
 				setDecorators(decl, decorators)
+				setDocComments(decl, docs)
 			}
 		}
 		if !ok && ctx.err == nil {
 			var decl *Enum
 			if decl, ok = parseChild(ctx, parseEnum); ok {
 				setDecorators(decl, decorators)
+				setDocComments(decl, docs)
 			}
 		}
 		if !ok && ctx.err == nil {
 			var decl *Struct
 			if decl, ok = parseChild(ctx, parseStruct); ok {
 				setDecorators(decl, decorators)
+				setDocComments(decl, docs)
 			}
 		}
 		if !ok && ctx.err == nil {
 			var decl *Message
 			if decl, ok = parseChild(ctx, parseMessage); ok {
 				setDecorators(decl, decorators)
+				setDocComments(decl, docs)
 			}
 		}
 		if !ok && ctx.err == nil {
 			var decl *Union
 			if decl, ok = parseChild(ctx, parseUnion); ok {
 				setDecorators(decl, decorators)
+				setDocComments(decl, docs)
 			}
 		}
 		if !ok && ctx.err == nil {
 			var decl *Protocol
 			if decl, ok = parseChild(ctx, parseProtocol); ok {
 				setDecorators(decl, decorators)
+				setDocComments(decl, docs)
 			}
 		}
 		if ctx.err != nil {
@@ -830,6 +891,16 @@ func setDecorators[T any](node *T, decorators []*Decorator) {
 	}
 }
 
+func setDocComments[T any](node *T, docs []*Comment) {
+	type setter interface {
+		setDocComments([]*Comment)
+	}
+	if node != nil {
+		var iface interface{} = node
+		iface.(setter).setDocComments(docs)
+	}
+}
+
 func parseDecorators[T any](ctx *parseCtx[T]) []*Decorator {
 	var decorators []*Decorator
 	for _ = range ctx.loop {
@@ -895,7 +966,7 @@ func parseConst(ctx *parseCtx[Const]) (*Const, error) {
 }
 
 func parseConstValue[T any](ctx *parseCtx[T]) Node {
-	node := parseValue(ctx)
+	node := parseExpr(ctx, 1)
 	if node == nil && ctx.err == nil {
 		ctx.err = errExpectedConstValue(
 			ctx.token.Kind,
@@ -943,6 +1014,158 @@ func parseValue[T any](ctx *parseCtx[T]) Node {
 	return nil
 }
 
+// Binary operators have the same precedence as in Go: multiplicative
+// operators (including shifts and '&') bind more tightly than additive
+// operators (including '|'), and operators of equal precedence associate
+// to the left.
+func binaryOperatorPrecedence(kind TokenKind) int {
+	switch kind {
+	case T_STAR, T_SLASH, T_PERCENT, T_SHL, T_SHR, T_AMP:
+		return 2
+	case T_PLUS, T_MINUS, T_PIPE:
+		return 1
+	}
+	return 0
+}
+
+func (ctx *parseCtx[T]) peekBinaryOperator() int {
+	if err := ctx.ensureToken(); err != nil {
+		return 0
+	}
+	token := ctx.token
+	if token.Kind == T_SPACE {
+		peek := *ctx.tokens
+		if err := peek.Next(&token); err != nil {
+			return 0
+		}
+	}
+	return binaryOperatorPrecedence(token.Kind)
+}
+
+func (ctx *parseCtx[T]) operator() *Operator {
+	if err := ctx.ensureToken(); err != nil {
+		return nil
+	}
+	op := &Operator{
+		raw:   string(ctx.readToken()),
+		start: ctx.offset,
+	}
+	ctx.consumeToken(op)
+	return op
+}
+
+func (ctx *parseCtx[T]) reduce(
+	start int,
+	build func(span Span, childNodes []Node) Node,
+) Node {
+	childNodes := slices.Clone(ctx.childNodes[start:])
+	first := childNodes[0].Span()
+	last := childNodes[len(childNodes)-1].Span()
+	node := build(Span{
+		start: first.Start(),
+		len:   last.End() - first.Start(),
+	}, childNodes)
+	ctx.childNodes = append(ctx.childNodes[:start], node)
+	return node
+}
+
+func parseExpr[T any](ctx *parseCtx[T], minPrecedence int) Node {
+	start := len(ctx.childNodes)
+	left := parseUnaryExpr(ctx)
+	for left != nil && ctx.err == nil {
+		precedence := ctx.peekBinaryOperator()
+		if precedence == 0 || precedence < minPrecedence {
+			break
+		}
+		ctx.space()
+		op := ctx.operator()
+		ctx.space()
+		right := parseExpr(ctx, precedence+1)
+		if ctx.err != nil {
+			return nil
+		}
+		if right == nil {
+			ctx.err = errExpectedExprOperand(
+				ctx.token.Kind,
+				string(ctx.readToken()),
+				ctx.tokenSpan(),
+			)
+			return nil
+		}
+		lhs := left
+		left = ctx.reduce(start, func(span Span, childNodes []Node) Node {
+			return &BinaryExpr{
+				span:       span,
+				childNodes: childNodes,
+				left:       lhs,
+				op:         op,
+				right:      right,
+			}
+		})
+	}
+	return left
+}
+
+func parseUnaryExpr[T any](ctx *parseCtx[T]) Node {
+	if err := ctx.ensureToken(); err != nil {
+		return nil
+	}
+	start := len(ctx.childNodes)
+	switch ctx.token.Kind {
+	case T_MINUS, T_TILDE:
+		op := ctx.operator()
+		ctx.space()
+		operand := parseUnaryExpr(ctx)
+		if ctx.err != nil {
+			return nil
+		}
+		if operand == nil {
+			ctx.err = errExpectedExprOperand(
+				ctx.token.Kind,
+				string(ctx.readToken()),
+				ctx.tokenSpan(),
+			)
+			return nil
+		}
+		return ctx.reduce(start, func(span Span, childNodes []Node) Node {
+			return &UnaryExpr{
+				span:       span,
+				childNodes: childNodes,
+				op:         op,
+				operand:    operand,
+			}
+		})
+	case T_OPEN_PAREN:
+		ctx.sigil(T_OPEN_PAREN)
+		ctx.space()
+		inner := parseExpr(ctx, 1)
+		if ctx.err != nil {
+			return nil
+		}
+		if inner == nil {
+			ctx.err = errExpectedExprOperand(
+				ctx.token.Kind,
+				string(ctx.readToken()),
+				ctx.tokenSpan(),
+			)
+			return nil
+		}
+		ctx.space()
+		ctx.sigil(T_CLOSE_PAREN)
+		if ctx.err != nil {
+			return nil
+		}
+		return ctx.reduce(start, func(span Span, childNodes []Node) Node {
+			return &ParenExpr{
+				span:       span,
+				childNodes: childNodes,
+				inner:      inner,
+			}
+		})
+	}
+	return parseValue(ctx)
+}
+
 func parseEnumRef(ctx *parseCtx[EnumRef]) (*EnumRef, error) {
 	ctx.sigil(T_DOT)
 	name := ctx.ident()
@@ -968,15 +1191,24 @@ func parseEnum(ctx *parseCtx[Enum]) (*Enum, error) {
 	ctx.space()
 
 	var items []*EnumItem
+	var reserved []*Reserved
 	ctx.sigil(T_OPEN_CURL)
 	ctx.comments()
 	for _ = range ctx.loop {
 		if ctx.trySigil(T_CLOSE_CURL) {
 			break
 		}
+		if node, ok := parseChild(ctx, parseEnumReserved); ok {
+			reserved = append(reserved, node)
+			ctx.suffixComment()
+			ctx.comments()
+			continue
+		}
+		docs := ctx.docComments()
 		decorators := parseDecorators(ctx)
 		item, _ := parseChild(ctx, parseEnumItem)
 		setDecorators(item, decorators)
+		setDocComments(item, docs)
 		items = append(items, item)
 		ctx.suffixComment()
 		ctx.comments()
@@ -990,6 +1222,7 @@ func parseEnum(ctx *parseCtx[Enum]) (*Enum, error) {
 			name:       name,
 			type_:      type_,
 			items:      items,
+			reserved:   reserved,
 		}
 	})
 }
@@ -1004,12 +1237,13 @@ func parseEnumItem(ctx *parseCtx[EnumItem]) (*EnumItem, error) {
 	if err := ctx.ensureToken(); err != nil {
 		return nil, err
 	}
-	if ctx.token.Kind == T_DOT {
-		value, _ = parseChild(ctx, parseEnumRef)
-	} else if ctx.token.Kind == T_IDENT {
-		value, _ = parseChild(ctx, parseValueName)
-	} else {
-		value = ctx.int()
+	value = parseExpr(ctx, 1)
+	if value == nil && ctx.err == nil {
+		ctx.err = errExpectedIntLit(
+			ctx.token.Kind,
+			string(ctx.readToken()),
+			ctx.tokenSpan(),
+		)
 	}
 	ctx.suffixComment()
 
@@ -1038,9 +1272,11 @@ func parseStruct(ctx *parseCtx[Struct]) (*Struct, error) {
 		if ctx.trySigil(T_CLOSE_CURL) {
 			break
 		}
+		docs := ctx.docComments()
 		decorators := parseDecorators(ctx)
 		field, _ := parseChild(ctx, parseStructField)
 		setDecorators(field, decorators)
+		setDocComments(field, docs)
 		fields = append(fields, field)
 		ctx.suffixComment()
 		ctx.comments()
@@ -1084,15 +1320,24 @@ func parseMessage(ctx *parseCtx[Message]) (*Message, error) {
 	ctx.space()
 
 	var fields []*MessageField
+	var reserved []*Reserved
 	ctx.sigil(T_OPEN_CURL)
 	ctx.comments()
 	for _ = range ctx.loop {
 		if ctx.trySigil(T_CLOSE_CURL) {
 			break
 		}
+		if node, ok := parseChild(ctx, parseFieldReserved); ok {
+			reserved = append(reserved, node)
+			ctx.suffixComment()
+			ctx.comments()
+			continue
+		}
+		docs := ctx.docComments()
 		decorators := parseDecorators(ctx)
 		field, _ := parseChild(ctx, parseMessageField)
 		setDecorators(field, decorators)
+		setDocComments(field, docs)
 		fields = append(fields, field)
 		ctx.suffixComment()
 		ctx.comments()
@@ -1105,6 +1350,7 @@ func parseMessage(ctx *parseCtx[Message]) (*Message, error) {
 			childNodes: childNodes,
 			name:       name,
 			fields:     fields,
+			reserved:   reserved,
 		}
 	})
 }
@@ -1139,15 +1385,24 @@ func parseUnion(ctx *parseCtx[Union]) (*Union, error) {
 	ctx.space()
 
 	var fields []*UnionField
+	var reserved []*Reserved
 	ctx.sigil(T_OPEN_CURL)
 	ctx.comments()
 	for _ = range ctx.loop {
 		if ctx.trySigil(T_CLOSE_CURL) {
 			break
 		}
+		if node, ok := parseChild(ctx, parseFieldReserved); ok {
+			reserved = append(reserved, node)
+			ctx.suffixComment()
+			ctx.comments()
+			continue
+		}
+		docs := ctx.docComments()
 		decorators := parseDecorators(ctx)
 		field, _ := parseChild(ctx, parseUnionField)
 		setDecorators(field, decorators)
+		setDocComments(field, docs)
 		fields = append(fields, field)
 		ctx.suffixComment()
 		ctx.comments()
@@ -1160,6 +1415,7 @@ func parseUnion(ctx *parseCtx[Union]) (*Union, error) {
 			childNodes: childNodes,
 			name:       name,
 			fields:     fields,
+			reserved:   reserved,
 		}
 	})
 }
@@ -1185,6 +1441,103 @@ func parseUnionField(ctx *parseCtx[UnionField]) (*UnionField, error) {
 	})
 }
 
+// A field or enum item may itself be named "reserved", so look past the
+// keyword for the `@N :` of a field or the `=` of an enum item.
+func (ctx *parseCtx[T]) peekReserved() bool {
+	if err := ctx.ensureToken(); err != nil {
+		return false
+	}
+	if ctx.token.Kind != T_IDENT || string(ctx.readToken()) != "reserved" {
+		return false
+	}
+	peek := *ctx.tokens
+	var kinds []TokenKind
+	var token Token
+	for len(kinds) < 3 {
+		if err := peek.Next(&token); err != nil || token.Kind == T_EOF {
+			break
+		}
+		if token.Kind != T_SPACE {
+			kinds = append(kinds, token.Kind)
+		}
+	}
+	if len(kinds) > 0 && kinds[0] == T_EQ {
+		return false
+	}
+	if len(kinds) == 3 && kinds[0] == T_AT && kinds[2] == T_COLON {
+		return false
+	}
+	return true
+}
+
+func parseFieldReserved(ctx *parseCtx[Reserved]) (*Reserved, error) {
+	return parseReserved(ctx, false)
+}
+
+func parseEnumReserved(ctx *parseCtx[Reserved]) (*Reserved, error) {
+	return parseReserved(ctx, true)
+}
+
+func parseReserved(ctx *parseCtx[Reserved], enum bool) (*Reserved, error) {
+	if !ctx.peekReserved() {
+		return nil, nil
+	}
+	ctx.tryKeyword("reserved")
+
+	var tags []*Tag
+	var values []*IntLit
+	var names []*Ident
+	for _ = range ctx.loop {
+		ctx.space()
+		if err := ctx.ensureToken(); err != nil {
+			break
+		}
+		switch ctx.token.Kind {
+		case T_IDENT:
+			names = append(names, ctx.ident())
+			continue
+		case T_AT:
+			if !enum {
+				tag, _ := parseChild(ctx, parseFieldTag)
+				tags = append(tags, tag)
+				continue
+			}
+		case T_INT_LIT, T_BIN_INT_LIT, T_OCT_INT_LIT, T_DEC_INT_LIT, T_HEX_INT_LIT:
+			if enum {
+				values = append(values, ctx.int())
+				continue
+			}
+		case T_MINUS:
+			// Reserved values are separated by spaces, so a negative value
+			// following another value is lexed as a subtraction operator.
+			if enum {
+				if value := ctx.negativeInt(); value != nil {
+					values = append(values, value)
+					continue
+				}
+			}
+		}
+		break
+	}
+	if len(tags)+len(values)+len(names) == 0 && ctx.err == nil {
+		ctx.err = errExpectedReservedItem(
+			ctx.token.Kind,
+			string(ctx.readToken()),
+			ctx.tokenSpan(),
+		)
+	}
+
+	return ctx.finish(func(span Span, childNodes []Node) *Reserved {
+		return &Reserved{
+			span:       span,
+			childNodes: childNodes,
+			tags:       tags,
+			values:     values,
+			names:      names,
+		}
+	})
+}
+
 func parseFieldTag(ctx *parseCtx[Tag]) (*Tag, error) {
 	ctx.sigil(T_AT)
 	ctx.space()
@@ -1217,12 +1570,19 @@ func parseFieldType(ctx *parseCtx[FieldType]) (*FieldType, error) {
 	typeName, _ := parseChild(ctx, parseTypeName)
 
 	isArray := false
-	var arrayLen *IntLit
+	var arrayLen Node
 	if ctx.trySigil(T_OPEN_SQUARE) {
 		isArray = true
 		ctx.space()
 		if !ctx.trySigil(T_CLOSE_SQUARE) {
-			arrayLen = ctx.int()
+			arrayLen = parseExpr(ctx, 1)
+			if arrayLen == nil && ctx.err == nil {
+				ctx.err = errExpectedIntLit(
+					ctx.token.Kind,
+					string(ctx.readToken()),
+					ctx.tokenSpan(),
+				)
+			}
 			ctx.space()
 			ctx.sigil(T_CLOSE_SQUARE)
 		}
@@ -1255,11 +1615,13 @@ func parseProtocol(ctx *parseCtx[Protocol]) (*Protocol, error) {
 		if ctx.trySigil(T_CLOSE_CURL) {
 			break
 		}
+		docs := ctx.docComments()
 		decorators := parseDecorators(ctx)
 
 		rpc, ok := parseChild(ctx, parseProtocolRpc)
 		if ok {
 			setDecorators(rpc, decorators)
+			setDocComments(rpc, docs)
 			rpcs = append(rpcs, rpc)
 		}
 		if !ok && ctx.err == nil {
@@ -1267,6 +1629,7 @@ func parseProtocol(ctx *parseCtx[Protocol]) (*Protocol, error) {
 			event, ok = parseChild(ctx, parseProtocolEvent)
 			if ok {
 				setDecorators(event, decorators)
+				setDocComments(event, docs)
 				events = append(events, event)
 			}
 		}
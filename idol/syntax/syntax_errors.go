@@ -355,3 +355,19 @@ func errExpectedProtocolItem(gotKind TokenKind, gotToken string, span Span) erro
 		span:    span,
 	}
 }
+
+func errExpectedExprOperand(gotKind TokenKind, gotToken string, span Span) error {
+	return &Error{
+		code:    2028,
+		message: fmt.Sprintf("Expected expression operand, got (%s %q)", gotKind, gotToken),
+		span:    span,
+	}
+}
+
+func errExpectedReservedItem(gotKind TokenKind, gotToken string, span Span) error {
+	return &Error{
+		code:    2029,
+		message: fmt.Sprintf("Expected reserved tag, value, or name, got (%s %q)", gotKind, gotToken),
+		span:    span,
+	}
+}
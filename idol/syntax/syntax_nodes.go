@@ -567,6 +567,29 @@ func (n *Keyword) UnparseTo(buf *bytes.Buffer) {
 	buf.WriteString(n.raw)
 }
 
+type Operator struct {
+	leafNode
+	raw   string
+	start uint32
+}
+
+var _ Node = (*Operator)(nil)
+
+func (n *Operator) Span() Span {
+	return Span{
+		start: n.start,
+		len:   uint32(len(n.raw)),
+	}
+}
+
+func (n *Operator) UnparseTo(buf *bytes.Buffer) {
+	buf.WriteString(n.raw)
+}
+
+func (n *Operator) Get() string {
+	return n.raw
+}
+
 type TypeName struct {
 	span       Span
 	childNodes []Node
@@ -1013,12 +1036,13 @@ func (n *OptionName) UnparseTo(buf *bytes.Buffer) {
 }
 
 type Const struct {
-	span       Span
-	childNodes []Node
-	name       *Ident
-	typeName   *TypeName
-	value      Node
-	decorators []*Decorator
+	span        Span
+	childNodes  []Node
+	name        *Ident
+	typeName    *TypeName
+	value       Node
+	decorators  []*Decorator
+	docComments []*Comment
 }
 
 var _ Node = (*Const)(nil)
@@ -1061,6 +1085,14 @@ func (n *Const) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *Const) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *Const) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 type EnumRef struct {
 	span       Span
 	childNodes []Node
@@ -1092,13 +1124,162 @@ func (n *EnumRef) Name() *Ident {
 	return n.name
 }
 
-type Enum struct {
+type BinaryExpr struct {
 	span       Span
 	childNodes []Node
-	name       *Ident
-	type_      *Ident
-	items      []*EnumItem
-	decorators []*Decorator
+	left       Node
+	op         *Operator
+	right      Node
+}
+
+var _ Node = (*BinaryExpr)(nil)
+
+func (n *BinaryExpr) Span() Span {
+	return n.span
+}
+
+func (n *BinaryExpr) ChildNodes() iter.Seq[Node] {
+	return iterChildren(n.childNodes)
+}
+
+func (n *BinaryExpr) privChildren() []Node {
+	return n.childNodes
+}
+
+func (n *BinaryExpr) UnparseTo(buf *bytes.Buffer) {
+	for _, childNode := range n.childNodes {
+		childNode.UnparseTo(buf)
+	}
+}
+
+func (n *BinaryExpr) Left() Node {
+	return n.left
+}
+
+func (n *BinaryExpr) Operator() *Operator {
+	return n.op
+}
+
+func (n *BinaryExpr) Right() Node {
+	return n.right
+}
+
+type UnaryExpr struct {
+	span       Span
+	childNodes []Node
+	op         *Operator
+	operand    Node
+}
+
+var _ Node = (*UnaryExpr)(nil)
+
+func (n *UnaryExpr) Span() Span {
+	return n.span
+}
+
+func (n *UnaryExpr) ChildNodes() iter.Seq[Node] {
+	return iterChildren(n.childNodes)
+}
+
+func (n *UnaryExpr) privChildren() []Node {
+	return n.childNodes
+}
+
+func (n *UnaryExpr) UnparseTo(buf *bytes.Buffer) {
+	for _, childNode := range n.childNodes {
+		childNode.UnparseTo(buf)
+	}
+}
+
+func (n *UnaryExpr) Operator() *Operator {
+	return n.op
+}
+
+func (n *UnaryExpr) Operand() Node {
+	return n.operand
+}
+
+type ParenExpr struct {
+	span       Span
+	childNodes []Node
+	inner      Node
+}
+
+var _ Node = (*ParenExpr)(nil)
+
+func (n *ParenExpr) Span() Span {
+	return n.span
+}
+
+func (n *ParenExpr) ChildNodes() iter.Seq[Node] {
+	return iterChildren(n.childNodes)
+}
+
+func (n *ParenExpr) privChildren() []Node {
+	return n.childNodes
+}
+
+func (n *ParenExpr) UnparseTo(buf *bytes.Buffer) {
+	for _, childNode := range n.childNodes {
+		childNode.UnparseTo(buf)
+	}
+}
+
+func (n *ParenExpr) Inner() Node {
+	return n.inner
+}
+
+type Reserved struct {
+	span       Span
+	childNodes []Node
+	tags       []*Tag
+	values     []*IntLit
+	names      []*Ident
+}
+
+var _ Node = (*Reserved)(nil)
+
+func (n *Reserved) Span() Span {
+	return n.span
+}
+
+func (n *Reserved) ChildNodes() iter.Seq[Node] {
+	return iterChildren(n.childNodes)
+}
+
+func (n *Reserved) privChildren() []Node {
+	return n.childNodes
+}
+
+func (n *Reserved) UnparseTo(buf *bytes.Buffer) {
+	for _, childNode := range n.childNodes {
+		childNode.UnparseTo(buf)
+	}
+}
+
+// Tags returns the reserved field tags of a message or union.
+func (n *Reserved) Tags() []*Tag {
+	return n.tags
+}
+
+// Values returns the reserved item values of an enum.
+func (n *Reserved) Values() []*IntLit {
+	return n.values
+}
+
+func (n *Reserved) Names() []*Ident {
+	return n.names
+}
+
+type Enum struct {
+	span        Span
+	childNodes  []Node
+	name        *Ident
+	type_       *Ident
+	items       []*EnumItem
+	reserved    []*Reserved
+	decorators  []*Decorator
+	docComments []*Comment
 }
 
 var _ Node = (*Enum)(nil)
@@ -1133,6 +1314,10 @@ func (n *Enum) Items() []*EnumItem {
 	return n.items
 }
 
+func (n *Enum) Reserved() []*Reserved {
+	return n.reserved
+}
+
 func (n *Enum) Decorators() []*Decorator {
 	return n.decorators
 }
@@ -1141,12 +1326,21 @@ func (n *Enum) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *Enum) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *Enum) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 type EnumItem struct {
-	span       Span
-	childNodes []Node
-	name       *Ident
-	value      Node
-	decorators []*Decorator
+	span        Span
+	childNodes  []Node
+	name        *Ident
+	value       Node
+	decorators  []*Decorator
+	docComments []*Comment
 }
 
 var _ Node = (*EnumItem)(nil)
@@ -1185,12 +1379,21 @@ func (n *EnumItem) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *EnumItem) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *EnumItem) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 type Struct struct {
-	span       Span
-	childNodes []Node
-	name       *Ident
-	decorators []*Decorator
-	fields     []*StructField
+	span        Span
+	childNodes  []Node
+	name        *Ident
+	decorators  []*Decorator
+	docComments []*Comment
+	fields      []*StructField
 }
 
 var _ Node = (*Struct)(nil)
@@ -1225,16 +1428,25 @@ func (n *Struct) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *Struct) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *Struct) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 func (n *Struct) Fields() []*StructField {
 	return n.fields
 }
 
 type StructField struct {
-	span       Span
-	childNodes []Node
-	name       *Ident
-	fieldType  *FieldType
-	decorators []*Decorator
+	span        Span
+	childNodes  []Node
+	name        *Ident
+	fieldType   *FieldType
+	decorators  []*Decorator
+	docComments []*Comment
 }
 
 var _ Node = (*StructField)(nil)
@@ -1273,12 +1485,22 @@ func (n *StructField) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *StructField) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *StructField) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 type Message struct {
-	span       Span
-	childNodes []Node
-	name       *Ident
-	decorators []*Decorator
-	fields     []*MessageField
+	span        Span
+	childNodes  []Node
+	name        *Ident
+	decorators  []*Decorator
+	docComments []*Comment
+	fields      []*MessageField
+	reserved    []*Reserved
 }
 
 var _ Node = (*Message)(nil)
@@ -1313,17 +1535,30 @@ func (n *Message) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *Message) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *Message) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 func (n *Message) Fields() []*MessageField {
 	return n.fields
 }
 
+func (n *Message) Reserved() []*Reserved {
+	return n.reserved
+}
+
 type MessageField struct {
-	span       Span
-	childNodes []Node
-	name       *Ident
-	tag        *Tag
-	fieldType  *FieldType
-	decorators []*Decorator
+	span        Span
+	childNodes  []Node
+	name        *Ident
+	tag         *Tag
+	fieldType   *FieldType
+	decorators  []*Decorator
+	docComments []*Comment
 }
 
 var _ Node = (*MessageField)(nil)
@@ -1366,12 +1601,22 @@ func (n *MessageField) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *MessageField) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *MessageField) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 type Union struct {
-	span       Span
-	childNodes []Node
-	name       *Ident
-	decorators []*Decorator
-	fields     []*UnionField
+	span        Span
+	childNodes  []Node
+	name        *Ident
+	decorators  []*Decorator
+	docComments []*Comment
+	fields      []*UnionField
+	reserved    []*Reserved
 }
 
 var _ Node = (*Union)(nil)
@@ -1406,17 +1651,30 @@ func (n *Union) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *Union) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *Union) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 func (n *Union) Fields() []*UnionField {
 	return n.fields
 }
 
+func (n *Union) Reserved() []*Reserved {
+	return n.reserved
+}
+
 type UnionField struct {
-	span       Span
-	childNodes []Node
-	name       *Ident
-	tag        *Tag
-	fieldType  *FieldType
-	decorators []*Decorator
+	span        Span
+	childNodes  []Node
+	name        *Ident
+	tag         *Tag
+	fieldType   *FieldType
+	decorators  []*Decorator
+	docComments []*Comment
 }
 
 var _ Node = (*UnionField)(nil)
@@ -1459,13 +1717,21 @@ func (n *UnionField) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *UnionField) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *UnionField) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 type FieldType struct {
 	span       Span
 	childNodes []Node
 
 	typeName *TypeName
 	isArray  bool
-	arrayLen *IntLit
+	arrayLen Node
 }
 
 var _ Node = (*FieldType)(nil)
@@ -1496,17 +1762,18 @@ func (n *FieldType) IsArray() bool {
 	return n.isArray
 }
 
-func (n *FieldType) ArrayLen() *IntLit {
+func (n *FieldType) ArrayLen() Node {
 	return n.arrayLen
 }
 
 type Protocol struct {
-	span       Span
-	childNodes []Node
-	name       *Ident
-	decorators []*Decorator
-	rpcs       []*ProtocolRpc
-	events     []*ProtocolEvent
+	span        Span
+	childNodes  []Node
+	name        *Ident
+	decorators  []*Decorator
+	docComments []*Comment
+	rpcs        []*ProtocolRpc
+	events      []*ProtocolEvent
 }
 
 var _ Node = (*Protocol)(nil)
@@ -1541,6 +1808,14 @@ func (n *Protocol) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *Protocol) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *Protocol) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 func (n *Protocol) Rpcs() iter.Seq[*ProtocolRpc] {
 	return slices.Values(n.rpcs)
 }
@@ -1559,6 +1834,7 @@ type ProtocolRpc struct {
 	responseType     *TypeName
 	responseIsStream bool
 	decorators       []*Decorator
+	docComments      []*Comment
 }
 
 var _ Node = (*ProtocolRpc)(nil)
@@ -1613,6 +1889,14 @@ func (n *ProtocolRpc) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
 
+func (n *ProtocolRpc) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *ProtocolRpc) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
+
 type ProtocolEvent struct {
 	span        Span
 	childNodes  []Node
@@ -1620,6 +1904,7 @@ type ProtocolEvent struct {
 	tag         *Tag
 	payloadType *TypeName
 	decorators  []*Decorator
+	docComments []*Comment
 }
 
 var _ Node = (*ProtocolEvent)(nil)
@@ -1661,3 +1946,11 @@ func (n *ProtocolEvent) Decorators() []*Decorator {
 func (n *ProtocolEvent) setDecorators(decorators []*Decorator) {
 	n.decorators = decorators
 }
+
+func (n *ProtocolEvent) DocComments() []*Comment {
+	return n.docComments
+}
+
+func (n *ProtocolEvent) setDocComments(docComments []*Comment) {
+	n.docComments = docComments
+}
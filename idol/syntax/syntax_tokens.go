@@ -55,6 +55,17 @@ const (
 	T_OPEN_SQUARE
 	T_CLOSE_SQUARE
 
+	T_PLUS
+	T_MINUS
+	T_STAR
+	T_SLASH
+	T_PERCENT
+	T_SHL
+	T_SHR
+	T_PIPE
+	T_AMP
+	T_TILDE
+
 	T_INT_LIT
 	T_BIN_INT_LIT
 	T_OCT_INT_LIT
@@ -96,6 +107,26 @@ func (k TokenKind) String() string {
 		return "OPEN_SQUARE"
 	case T_CLOSE_SQUARE:
 		return "CLOSE_SQUARE"
+	case T_PLUS:
+		return "PLUS"
+	case T_MINUS:
+		return "MINUS"
+	case T_STAR:
+		return "STAR"
+	case T_SLASH:
+		return "SLASH"
+	case T_PERCENT:
+		return "PERCENT"
+	case T_SHL:
+		return "SHL"
+	case T_SHR:
+		return "SHR"
+	case T_PIPE:
+		return "PIPE"
+	case T_AMP:
+		return "AMP"
+	case T_TILDE:
+		return "TILDE"
 	case T_INT_LIT:
 		return "INT_LIT"
 	case T_BIN_INT_LIT:
@@ -118,6 +149,9 @@ func (k TokenKind) String() string {
 type Tokens struct {
 	src    []byte
 	offset uint32
+
+	// prev is the last byte of the previous token, ignoring spaces.
+	prev byte
 }
 
 func NewTokens(src []byte) (*Tokens, error) {
@@ -133,6 +167,15 @@ func NewTokens(src []byte) (*Tokens, error) {
 }
 
 func (t *Tokens) Next(token *Token) error {
+	start := t.src
+	err := t.next(token)
+	if err == nil && token.Len > 0 && token.Kind != T_SPACE {
+		t.prev = start[token.Len-1]
+	}
+	return err
+}
+
+func (t *Tokens) next(token *Token) error {
 	if len(t.src) == 0 {
 		*token = Token{
 			Kind: T_EOF,
@@ -178,6 +221,51 @@ func (t *Tokens) Next(token *Token) error {
 	case ']':
 		kind = T_CLOSE_SQUARE
 		goto len1
+	case '+':
+		kind = T_PLUS
+		goto len1
+	case '*':
+		kind = T_STAR
+		goto len1
+	case '/':
+		kind = T_SLASH
+		goto len1
+	case '%':
+		kind = T_PERCENT
+		goto len1
+	case '|':
+		kind = T_PIPE
+		goto len1
+	case '&':
+		kind = T_AMP
+		goto len1
+	case '~':
+		kind = T_TILDE
+		goto len1
+	case '<', '>':
+		if len(t.src) < 2 || t.src[1] != c {
+			return errUnexpectedCharacter(t.offset, rune(c))
+		}
+		kind = T_SHL
+		if c == '>' {
+			kind = T_SHR
+		}
+		*token = Token{
+			Kind: kind,
+			Len:  2,
+		}
+		t.offset += 2
+		t.src = t.src[2:]
+		return nil
+	case '-':
+		// A '-' following an operand (with or without spaces between them)
+		// is subtraction, otherwise it's the sign of an integer literal (if
+		// one follows).
+		if t.prevIsOperand() || len(t.src) < 2 || t.src[1] < '0' || t.src[1] > '9' {
+			kind = T_MINUS
+			goto len1
+		}
+		return t.nextNumLit(token)
 	case '#':
 		return t.nextComment(token)
 	case '"':
@@ -207,7 +295,7 @@ len1:
 	return nil
 
 big:
-	if (c >= '0' && c <= '9') || c == '-' {
+	if c >= '0' && c <= '9' {
 		return t.nextNumLit(token)
 	}
 
@@ -226,6 +314,14 @@ big:
 	return errUnexpectedCharacter(t.offset, r)
 }
 
+func (t *Tokens) prevIsOperand() bool {
+	c := t.prev
+	if (c >= '0' && c <= '9') || c == '_' || c == ')' {
+		return true
+	}
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
 func (t *Tokens) nextSpace(token *Token) error {
 	src := t.src
 	for {